@@ -16,15 +16,24 @@ import (
 	"github.com/dmksnnk/octo/docs"
 	"github.com/dmksnnk/octo/internal/api"
 	"github.com/dmksnnk/octo/internal/auth"
+	"github.com/dmksnnk/octo/internal/idempotency"
 	"github.com/dmksnnk/octo/internal/platform/httpplatform"
 	"github.com/dmksnnk/octo/internal/service"
 	"github.com/dmksnnk/octo/internal/storage"
+	"github.com/dmksnnk/octo/internal/ticket"
 )
 
 type config struct {
-	ListenAddress string     `env:"LISTEN_ADDRESS" envDefault:":8080"`
-	LogLevel      slog.Level `env:"LOG_LEVEL" envDefault:"INFO"`
-	DatabaseURL   string     `env:"DATABASE_URL"`
+	ListenAddress    string        `env:"LISTEN_ADDRESS" envDefault:":8080"`
+	LogLevel         slog.Level    `env:"LOG_LEVEL" envDefault:"INFO"`
+	DatabaseURL      string        `env:"DATABASE_URL"`
+	ReservationHold  time.Duration `env:"RESERVATION_HOLD" envDefault:"30m"`
+	IdempotencyTTL   time.Duration `env:"IDEMPOTENCY_KEY_TTL" envDefault:"24h"`
+	ReaperInterval   time.Duration `env:"REAPER_INTERVAL" envDefault:"1m"`
+	WaitlistInterval time.Duration `env:"WAITLIST_INTERVAL" envDefault:"1m"`
+	WebhookTimeout   time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+	// TicketSigningKey signs ticket codes, "<key ID>:<base64 Ed25519 seed>", generate with "ticket keygen".
+	TicketSigningKey string `env:"TICKET_SIGNING_KEY,required"`
 }
 
 func main() {
@@ -41,6 +50,12 @@ func main() {
 	}
 	defer db.Close()
 
+	signer, err := ticket.ParseSigningKey(cfg.TicketSigningKey)
+	if err != nil {
+		logger.Error("failed to parse ticket signing key", "error", err)
+		os.Exit(1)
+	}
+
 	mux := http.NewServeMux()
 	mux.HandleFunc("/-/health", health(db))
 	mux.Handle("/docs",
@@ -58,12 +73,15 @@ func main() {
 		),
 	)
 
-	pg := storage.NewPostgres(db)
-	svc := service.NewService(pg)
+	pg := storage.NewPostgres(db, signer)
+	svc := service.NewService(pg, service.Config{
+		ReservationHold:   cfg.ReservationHold,
+		IdempotencyKeyTTL: cfg.IdempotencyTTL,
+	})
 	a := api.NewAPI(svc)
 	mux.Handle("/",
 		httpplatform.Wrap(
-			api.NewRouter(a),
+			api.NewRouter(a, idempotency.Check(pg)),
 			auth.CheckUser(pg),
 			httpplatform.LogRequests(logger),
 		),
@@ -74,6 +92,12 @@ func main() {
 		Handler: mux,
 	}
 
+	reaper := service.NewReaper(svc, cfg.ReaperInterval, logger)
+	go reaper.Run(rootCtx)
+
+	waitlister := service.NewWaitlister(svc, httpplatform.NewPublicClient(cfg.WebhookTimeout), cfg.WaitlistInterval, logger)
+	go waitlister.Run(rootCtx)
+
 	go func() {
 		slog.Info("starting server", "address", srv.Addr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
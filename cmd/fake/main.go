@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/brianvoe/gofakeit/v7"
+	"github.com/dmksnnk/octo/internal"
 	"github.com/dmksnnk/octo/internal/platform"
 	"github.com/dmksnnk/octo/internal/storage/queries"
 	_ "github.com/lib/pq"
@@ -72,35 +73,134 @@ func generate(ctx context.Context, tx *sql.Tx, cfg config) error {
 	qrs := queries.New(tx)
 	for range cfg.Products {
 		p, err := qrs.InsertProduct(ctx, queries.InsertProductParams{
-			Name:     gofakeit.ProductName(),
-			Capacity: int32(gofakeit.Number(1, 1000)),
+			Name:                      gofakeit.ProductName(),
+			Capacity:                  int32(gofakeit.Number(1, 1000)),
+			MaxReservationHoldMinutes: int32(gofakeit.Number(30, 120)),
+			ContactRequiredToConfirm:  gofakeit.Bool(),
+			LimitedThreshold:          int32(gofakeit.Number(0, 20)),
+			AvailabilityType:          internal.AvailabilityType(gofakeit.RandomString([]string{string(internal.AvailabilityTypeStartTime), string(internal.AvailabilityTypeOpeningHours)})),
+			Timezone:                  gofakeit.RandomString([]string{"UTC", "Europe/Berlin", "Europe/London", "America/New_York", "Asia/Tokyo"}),
+			DeliveryFormat:            internal.DeliveryFormat(gofakeit.RandomString([]string{string(internal.DeliveryFormatQRCode), string(internal.DeliveryFormatCode128)})),
+			DeliveryMethods:           deliveryMethods(),
+			DefaultCurrency:           gofakeit.CurrencyShort(),
 		})
 		if err != nil {
 			return fmt.Errorf("insert product: %w", err)
 		}
 
+		option, err := qrs.InsertOption(ctx, queries.InsertOptionParams{
+			ProductID: p.ID,
+			Code:      internal.DefaultOptionID,
+			Name:      p.Name,
+			Capacity:  p.Capacity,
+		})
+		if err != nil {
+			return fmt.Errorf("insert option: %w", err)
+		}
+
 		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
 		for i := range cfg.AvailabilityDays {
-			_, err = qrs.InsertAvailability(ctx, queries.InsertAvailabilityParams{
-				ProductID: p.ID,
-				LocalDate: now.AddDate(0, 0, i),
-				Vacancies: int32(gofakeit.Number(1, 1000)),
-			})
-			if err != nil {
-				return fmt.Errorf("insert availability: %w", err)
+			localDate := today.AddDate(0, 0, i)
+			switch p.AvailabilityType {
+			case internal.AvailabilityTypeOpeningHours:
+				// whole day, open from 10:00 to 18:00
+				availability, err := qrs.InsertAvailability(ctx, queries.InsertAvailabilityParams{
+					ProductID: p.ID,
+					OptionID:  sql.NullInt32{Int32: option.ID, Valid: true},
+					LocalDate: localDate,
+					Vacancies: int32(gofakeit.Number(0, int(p.Capacity))), // partly sold
+				})
+				if err != nil {
+					return fmt.Errorf("insert availability: %w", err)
+				}
+				_, err = qrs.InsertOpeningHours(ctx, queries.InsertOpeningHoursParams{
+					AvailabilityID: availability.ID,
+					TimeFrom:       localDate.Add(10 * time.Hour),
+					TimeTo:         localDate.Add(18 * time.Hour),
+				})
+				if err != nil {
+					return fmt.Errorf("insert opening hours: %w", err)
+				}
+			default:
+				// morning, afternoon and evening slots, 2 hours each
+				for _, hour := range []int{9, 13, 17} {
+					start := localDate.Add(time.Duration(hour) * time.Hour)
+					_, err = qrs.InsertAvailability(ctx, queries.InsertAvailabilityParams{
+						ProductID:          p.ID,
+						OptionID:           sql.NullInt32{Int32: option.ID, Valid: true},
+						LocalDate:          localDate,
+						LocalDateTimeStart: sql.NullTime{Time: start, Valid: true},
+						LocalDateTimeEnd:   sql.NullTime{Time: start.Add(2 * time.Hour), Valid: true},
+						Vacancies:          int32(gofakeit.Number(0, int(p.Capacity))),
+					})
+					if err != nil {
+						return fmt.Errorf("insert availability: %w", err)
+					}
+				}
 			}
 		}
 
+		retail := int32(gofakeit.Price(10, 1000))
+		price, err := qrs.InsertPrice(ctx, queries.InsertPriceParams{
+			ProductID: p.ID,
+			Price:     retail,
+			Net:       sql.NullInt32{Int32: retail * 4 / 5, Valid: true}, // resellers get 20%
+			Currency:  p.DefaultCurrency,
+		})
+		if err != nil {
+			return fmt.Errorf("insert price: %w", err)
+		}
+		// weekends are more expensive
 		_, err = qrs.InsertPrice(ctx, queries.InsertPriceParams{
 			ProductID: p.ID,
-			Price:     int32(gofakeit.Price(10, 1000)),
-			Currency:  gofakeit.CurrencyShort(),
+			Price:     price.Price * 5 / 4,
+			Currency:  price.Currency,
+			Weekdays:  []int32{6, 7},
+			Priority:  1,
+		})
+		if err != nil {
+			return fmt.Errorf("insert price: %w", err)
+		}
+
+		// adults pay the product price, children get a discount
+		_, err = qrs.InsertUnitType(ctx, queries.InsertUnitTypeParams{
+			ProductID: p.ID,
+			Code:      "adult",
+			Name:      "Adult",
+		})
+		if err != nil {
+			return fmt.Errorf("insert unit type: %w", err)
+		}
+		child, err := qrs.InsertUnitType(ctx, queries.InsertUnitTypeParams{
+			ProductID: p.ID,
+			Code:      "child",
+			Name:      "Child",
+		})
+		if err != nil {
+			return fmt.Errorf("insert unit type: %w", err)
+		}
+		_, err = qrs.InsertPrice(ctx, queries.InsertPriceParams{
+			ProductID:  p.ID,
+			UnitTypeID: sql.NullInt32{Int32: child.ID, Valid: true},
+			Price:      price.Price / 2,
+			Currency:   price.Currency,
 		})
 		if err != nil {
 			return fmt.Errorf("insert price: %w", err)
 		}
 	}
 
+	// 10% off all products
+	_, err := qrs.InsertPromoCode(ctx, queries.InsertPromoCodeParams{
+		Code:         "WELCOME10",
+		DiscountType: queries.DiscountTypePERCENTAGE,
+		Amount:       10,
+	})
+	if err != nil {
+		return fmt.Errorf("insert promo code: %w", err)
+	}
+
 	for range cfg.Users {
 		_, err := qrs.InsertUser(ctx, queries.InsertUserParams{
 			Email: gofakeit.Email(),
@@ -116,3 +216,16 @@ func generate(ctx context.Context, tx *sql.Tx, cfg config) error {
 
 	return nil
 }
+
+// deliveryMethods returns tickets, a voucher or both.
+func deliveryMethods() []internal.DeliveryMethod {
+	all := []internal.DeliveryMethod{internal.DeliveryMethodTicket, internal.DeliveryMethodVoucher}
+	switch gofakeit.Number(0, 2) {
+	case 0:
+		return all[:1]
+	case 1:
+		return all[1:]
+	default:
+		return all
+	}
+}
@@ -0,0 +1,121 @@
+// Command ticket generates ticket signing keys and verifies ticket codes offline.
+//
+// Usage:
+//
+//	ticket keygen -key-id 2
+//	ticket verify -public-keys 1:<base64>,2:<base64> <code>
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dmksnnk/octo/internal/ticket"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "keygen":
+		err = keygen(os.Args[2:])
+	case "verify":
+		err = verify(os.Args[2:])
+	default:
+		usage()
+	}
+	if errors.Is(err, errFlags) {
+		os.Exit(2) // already reported by the flag set
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// errFlags wraps flag parsing errors, which flag sets report themselves.
+var errFlags = errors.New("invalid flags")
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: ticket keygen|verify [options]")
+	os.Exit(2)
+}
+
+// keygen prints a new signing key for TICKET_SIGNING_KEY and its public key for verifiers.
+func keygen(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	keyID := fs.Uint("key-id", 1, "ID of the key, increment it when rotating keys (0-255)")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %w", errFlags, err)
+	}
+
+	if *keyID > 255 {
+		return fmt.Errorf("key ID must be 0-255, got %d", *keyID)
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generate key: %w", err)
+	}
+
+	fmt.Printf("signing key: %s\n", ticket.FormatKey(byte(*keyID), privateKey.Seed()))
+	fmt.Printf("public key:  %s\n", ticket.FormatKey(byte(*keyID), publicKey))
+	return nil
+}
+
+// verify checks the signature of ticket codes and prints their claims.
+// Verification is offline, it does not know whether the booking was cancelled or the ticket redeemed.
+func verify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	publicKeys := fs.String("public-keys", os.Getenv("TICKET_PUBLIC_KEYS"), `Comma separated public keys "<key ID>:<base64 key>" (default from TICKET_PUBLIC_KEYS)`)
+	date := fs.String("date", "", "Local date the ticket must be valid on, YYYY-MM-DD (optional)")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %w", errFlags, err)
+	}
+
+	if fs.NArg() == 0 {
+		return fmt.Errorf("no ticket code given")
+	}
+
+	keys, err := ticket.ParsePublicKeys(*publicKeys)
+	if err != nil {
+		return fmt.Errorf("parse public keys: %w", err)
+	}
+	verifier := ticket.NewVerifier(keys)
+
+	var invalid bool
+	for _, code := range fs.Args() {
+		claims, err := verifier.Verify(code)
+		if err != nil {
+			invalid = true
+			fmt.Printf("%s: invalid: %v\n", code, err)
+			continue
+		}
+
+		localDate := claims.LocalDate.Format(time.DateOnly)
+		if *date != "" && *date != localDate {
+			invalid = true
+			fmt.Printf("%s: wrong date: valid on %s\n", code, localDate)
+			continue
+		}
+
+		if claims.UnitID == 0 {
+			fmt.Printf("%s: valid: voucher of booking %d, date %s\n", code, claims.BookingID, localDate)
+			continue
+		}
+		fmt.Printf("%s: valid: booking %d, unit %d, date %s\n", code, claims.BookingID, claims.UnitID, localDate)
+	}
+
+	if invalid {
+		return fmt.Errorf("invalid tickets")
+	}
+	return nil
+}
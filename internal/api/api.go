@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/dmksnnk/octo/internal"
 	"github.com/dmksnnk/octo/internal/auth"
+	"github.com/dmksnnk/octo/internal/platform"
 )
 
 type API struct {
@@ -16,16 +18,48 @@ type API struct {
 }
 
 type Service interface {
-	Products(ctx context.Context, capability internal.CapabilityRequest) ([]internal.Product, error)
-	Product(ctx context.Context, id int, capability internal.CapabilityRequest) (internal.Product, error)
-	Availability(ctx context.Context, productID int, localDate time.Time, capability internal.CapabilityRequest) (internal.Availability, error)
-	Availabilities(ctx context.Context, productID int, localDateStart, localDateEnd time.Time, capability internal.CapabilityRequest) ([]internal.Availability, error)
+	Products(ctx context.Context, capability internal.CapabilityRequest, currency string) ([]internal.Product, error)
+	Product(ctx context.Context, id int, capability internal.CapabilityRequest, currency string) (internal.Product, error)
+	// Availabilities returns availabilities of a product option starting in the local date range.
+	Availabilities(ctx context.Context, productID int, optionID string, localDateStart, localDateEnd time.Time, capability internal.CapabilityRequest, currency string) ([]internal.Availability, error)
+	// AvailabilityCalendar returns availability of a product per local date in the range.
+	AvailabilityCalendar(ctx context.Context, productID int, localDateStart, localDateEnd time.Time, capability internal.CapabilityRequest, currency string) ([]internal.AvailabilityCalendar, error)
 	// CreateBooking creates a booking for the given product and availability.
-	// Return internal.ErrNotAvailable if the product is not available.
+	// Return internal.ErrNotAvailable if the product is not available
+	// or the availability does not belong to the option.
+	// Return internal.InvalidPromoCodeError if the promo code cannot be applied.
 	CreateBooking(ctx context.Context, params internal.CreateBookingRequest) (int, error)
 	// ConfirmBooking confirms a booking for the given product and availability and generates tickets.
-	ConfirmBooking(ctx context.Context, id, userID int) error
+	// Return internal.BookingStatusError if the booking cannot be confirmed.
+	ConfirmBooking(ctx context.Context, req internal.ConfirmBookingRequest) error
+	// UpdateBooking changes number of units or availability of a reservation.
+	// Return internal.ErrNotAvailable if the new availability does not have enough vacancies.
+	// Return internal.BookingStatusError if the booking is not reserved or the reservation has expired.
+	UpdateBooking(ctx context.Context, params internal.UpdateBookingRequest) error
+	// ExtendBooking extends reservation hold of a booking, up to the product's maximum.
+	// If hold is zero, the default reservation hold is used.
+	// Return internal.BookingStatusError if the booking is not reserved or the reservation has expired.
+	ExtendBooking(ctx context.Context, id, userID int, hold time.Duration) error
+	// CancelBooking cancels a booking, returns its units to the availability and invalidates tickets.
+	// Return internal.BookingStatusError if the booking cannot be cancelled.
+	CancelBooking(ctx context.Context, id, userID int, reason string) error
 	Booking(ctx context.Context, id, userID int, capability internal.CapabilityRequest) (internal.Booking, error)
+	// Bookings returns a page of bookings matching the filter.
+	Bookings(ctx context.Context, filter internal.BookingsFilter, capability internal.CapabilityRequest) (internal.BookingsPage, error)
+	// UnitTicket returns the ticket of a booking's unit.
+	// Return internal.ErrNotFound if the unit is not found or has no ticket yet.
+	UnitTicket(ctx context.Context, bookingID, unitID, userID int) (internal.UnitTicket, error)
+	// RedeemTicket marks the unit of the user's booking with the ticket as redeemed by the user.
+	// A voucher redeems all units of its booking which are not redeemed yet.
+	// Return internal.ErrNotFound if no unit of the user's bookings has the ticket.
+	// Return internal.BookingStatusError if the booking is not confirmed.
+	// Return internal.TicketRedeemedError if the ticket or all units of the voucher have already been redeemed.
+	// Return internal.ErrWrongDate if the booking is not for today.
+	RedeemTicket(ctx context.Context, ticket string, userID int) (internal.Redemption, error)
+	// JoinWaitlist records demand for units of an availability, which is reserved once enough vacancies return.
+	// Return internal.ErrNotFound if the availability is not found.
+	JoinWaitlist(ctx context.Context, req internal.JoinWaitlistRequest) (int, error)
+	WaitlistEntry(ctx context.Context, id, userID int) (internal.WaitlistEntry, error)
 }
 
 func NewAPI(service Service) API {
@@ -41,8 +75,20 @@ func (a API) Products(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	products, err := a.service.Products(r.Context(), internal.CapabilityRequest(capability))
+	var currency CurrencyRequest
+	if err := currency.UnmarshalHTTP(r); err != nil {
+		writeError(w, "invalid currency", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	products, err := a.service.Products(r.Context(), internal.CapabilityRequest(capability), string(currency))
 	if err != nil {
+		var currencyErr internal.UnsupportedCurrencyError
+		if errors.As(err, &currencyErr) {
+			writeError(w, "unsupported currency", http.StatusBadRequest, currencyErr.Error())
+			return
+		}
+
 		writeError(w, "failed to get products", http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -57,18 +103,29 @@ func (a API) Product(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var currency CurrencyRequest
+	if err := currency.UnmarshalHTTP(r); err != nil {
+		writeError(w, "invalid currency", http.StatusBadRequest, err.Error())
+		return
+	}
+
 	var id IDPathValue
 	if err := id.UnmarshalHTTP(r); err != nil {
 		writeError(w, "invalid product ID", http.StatusBadRequest, err.Error())
 		return
 	}
 
-	product, err := a.service.Product(r.Context(), int(id), internal.CapabilityRequest(capability))
+	product, err := a.service.Product(r.Context(), int(id), internal.CapabilityRequest(capability), string(currency))
 	if err != nil {
 		if errors.Is(err, internal.ErrNotFound) {
 			writeError(w, "product not found", http.StatusNotFound)
 			return
 		}
+		var currencyErr internal.UnsupportedCurrencyError
+		if errors.As(err, &currencyErr) {
+			writeError(w, "unsupported currency", http.StatusBadRequest, currencyErr.Error())
+			return
+		}
 
 		writeError(w, "failed to get product", http.StatusInternalServerError, err.Error())
 		return
@@ -84,31 +141,35 @@ func (a API) Availability(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var availabilityReq AvailabilityRequest
-	if err := availabilityReq.UnmarshalHTTP(r); err != nil {
-		writeError(w, "failed to decode availability request", http.StatusBadRequest, err.Error())
+	var currency CurrencyRequest
+	if err := currency.UnmarshalHTTP(r); err != nil {
+		writeError(w, "invalid currency", http.StatusBadRequest, err.Error())
 		return
 	}
 
-	if !time.Time(availabilityReq.LocalDate).IsZero() { // request for a single availability
-		availabilities, err := a.singleAvailability(r.Context(), availabilityReq, capability)
-		if err != nil {
-			writeError(w, "failed to get availability", http.StatusInternalServerError, err.Error())
-			return
-		}
-		_ = writeJSON(w, http.StatusOK, availabilities)
+	var availabilityReq AvailabilityRequest
+	if err := availabilityReq.UnmarshalHTTP(r); err != nil {
+		writeError(w, "failed to decode availability request", http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// request for a range of availabilities
+	localDateStart, localDateEnd := availabilityReq.DateRange()
 	availabilities, err := a.service.Availabilities(
 		r.Context(),
 		int(availabilityReq.ProductID),
-		time.Time(availabilityReq.LocalDateStart),
-		time.Time(availabilityReq.LocalDateEnd),
+		availabilityReq.OptionID,
+		localDateStart,
+		localDateEnd,
 		internal.CapabilityRequest(capability),
+		string(currency),
 	)
 	if err != nil {
+		var currencyErr internal.UnsupportedCurrencyError
+		if errors.As(err, &currencyErr) {
+			writeError(w, "unsupported currency", http.StatusBadRequest, currencyErr.Error())
+			return
+		}
+
 		writeError(w, "failed to get availability", http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -116,22 +177,45 @@ func (a API) Availability(w http.ResponseWriter, r *http.Request) {
 	_ = writeJSON(w, http.StatusOK, availabilities)
 }
 
-func (a API) singleAvailability(ctx context.Context, req AvailabilityRequest, capability CapabilityRequest) ([]internal.Availability, error) {
-	availability, err := a.service.Availability(
-		ctx,
-		int(req.ProductID),
-		time.Time(req.LocalDate),
+func (a API) AvailabilityCalendar(w http.ResponseWriter, r *http.Request) {
+	var capability CapabilityRequest
+	if err := capability.UnmarshalHTTP(r); err != nil {
+		writeError(w, "failed to decode capability", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var currency CurrencyRequest
+	if err := currency.UnmarshalHTTP(r); err != nil {
+		writeError(w, "invalid currency", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var calendarReq AvailabilityCalendarRequest
+	if err := calendarReq.UnmarshalHTTP(r); err != nil {
+		writeError(w, "failed to decode availability calendar request", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	calendar, err := a.service.AvailabilityCalendar(
+		r.Context(),
+		int(calendarReq.ProductID),
+		time.Time(calendarReq.LocalDateStart),
+		time.Time(calendarReq.LocalDateEnd),
 		internal.CapabilityRequest(capability),
+		string(currency),
 	)
 	if err != nil {
-		if errors.Is(err, internal.ErrNotFound) {
-			return []internal.Availability{}, nil // return empty slice
+		var currencyErr internal.UnsupportedCurrencyError
+		if errors.As(err, &currencyErr) {
+			writeError(w, "unsupported currency", http.StatusBadRequest, currencyErr.Error())
+			return
 		}
 
-		return nil, err
+		writeError(w, "failed to get availability calendar", http.StatusInternalServerError, err.Error())
+		return
 	}
 
-	return []internal.Availability{availability}, nil
+	_ = writeJSON(w, http.StatusOK, calendar)
 }
 
 func (a API) CreateBooking(w http.ResponseWriter, r *http.Request) {
@@ -140,18 +224,33 @@ func (a API) CreateBooking(w http.ResponseWriter, r *http.Request) {
 		writeError(w, "failed to decode capability", http.StatusBadRequest, err.Error())
 		return
 	}
+	var currency CurrencyRequest
+	if err := currency.UnmarshalHTTP(r); err != nil {
+		writeError(w, "invalid currency", http.StatusBadRequest, err.Error())
+		return
+	}
 	var bookingReq BookingRequest
 	if err := bookingReq.UnmarshalHTTP(r); err != nil {
 		writeError(w, "failed to decode booking request", http.StatusBadRequest, err.Error())
 		return
 	}
+	if bookingReq.Currency != "" {
+		currency = bookingReq.Currency
+	}
 
 	user, _ := auth.ContextUser(r.Context())
 	params := internal.CreateBookingRequest{
-		ProductID:      int(bookingReq.ProductID),
-		AvailabilityID: int(bookingReq.AvailabilityID),
-		Units:          int(bookingReq.Units),
-		UserID:         user.ID,
+		ProductID:         int(bookingReq.ProductID),
+		OptionID:          bookingReq.OptionID,
+		AvailabilityID:    int(bookingReq.AvailabilityID),
+		Units:             int(bookingReq.Units),
+		UnitIDs:           bookingReq.UnitIDs(),
+		UserID:            user.ID,
+		UUID:              bookingReq.UUID,
+		ResellerReference: bookingReq.ResellerReference,
+		Contact:           bookingReq.Contact,
+		Currency:          string(currency),
+		PromoCode:         bookingReq.PromoCode,
 	}
 	id, err := a.service.CreateBooking(r.Context(), params)
 	if err != nil {
@@ -159,6 +258,24 @@ func (a API) CreateBooking(w http.ResponseWriter, r *http.Request) {
 			writeError(w, "not available", http.StatusConflict)
 			return
 		}
+		if errors.Is(err, internal.ErrUnknownUnit) {
+			writeError(w, "unknown unit", http.StatusBadRequest, "unitId must be one of the product's units")
+			return
+		}
+		if errors.Is(err, internal.ErrAlreadyExists) {
+			writeError(w, "booking with the same uuid or resellerReference already exists", http.StatusConflict)
+			return
+		}
+		var currencyErr internal.UnsupportedCurrencyError
+		if errors.As(err, &currencyErr) {
+			writeError(w, "unsupported currency", http.StatusBadRequest, currencyErr.Error())
+			return
+		}
+		var promoCodeErr internal.InvalidPromoCodeError
+		if errors.As(err, &promoCodeErr) {
+			writeError(w, "invalid promo code", http.StatusBadRequest, promoCodeErr.Error())
+			return
+		}
 		writeError(w, "failed to create booking", http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -179,12 +296,32 @@ func (a API) ConfirmBooking(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var confirmReq ConfirmBookingRequest
+	if err := confirmReq.UnmarshalHTTP(r); err != nil {
+		writeError(w, "failed to decode confirm booking request", http.StatusBadRequest, err.Error())
+		return
+	}
+
 	user, _ := auth.ContextUser(r.Context())
-	if err := a.service.ConfirmBooking(r.Context(), int(id), user.ID); err != nil {
+	params := internal.ConfirmBookingRequest{
+		ID:      int(id),
+		UserID:  user.ID,
+		Contact: confirmReq.Contact,
+	}
+	if err := a.service.ConfirmBooking(r.Context(), params); err != nil {
 		if errors.Is(err, internal.ErrNotFound) {
 			writeError(w, "booking not found", http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, internal.ErrContactRequired) {
+			writeError(w, "contact required", http.StatusBadRequest, "product requires contact fullName and emailAddress to confirm")
+			return
+		}
+		var statusErr internal.BookingStatusError
+		if errors.As(err, &statusErr) {
+			writeError(w, statusErr.Error(), http.StatusConflict)
+			return
+		}
 
 		writeError(w, "failed to confirm booking", http.StatusInternalServerError, err.Error())
 		return
@@ -193,6 +330,142 @@ func (a API) ConfirmBooking(w http.ResponseWriter, r *http.Request) {
 	a.booking(r.Context(), w, int(id), user.ID, internal.CapabilityRequest(capability))
 }
 
+func (a API) UpdateBooking(w http.ResponseWriter, r *http.Request) {
+	var capability CapabilityRequest
+	if err := capability.UnmarshalHTTP(r); err != nil {
+		writeError(w, "failed to decode capability", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var id IDPathValue
+	if err := id.UnmarshalHTTP(r); err != nil {
+		writeError(w, "invalid booking ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var updateReq UpdateBookingRequest
+	if err := updateReq.UnmarshalHTTP(r); err != nil {
+		writeError(w, "failed to decode update booking request", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, _ := auth.ContextUser(r.Context())
+	params := internal.UpdateBookingRequest{
+		ID:             int(id),
+		UserID:         user.ID,
+		AvailabilityID: int(updateReq.AvailabilityID),
+		Units:          platform.FromPtr(updateReq.Units),
+		UnitIDs:        updateReq.UnitIDs(),
+		Contact:        updateReq.Contact,
+	}
+	if err := a.service.UpdateBooking(r.Context(), params); err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			writeError(w, "booking not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, internal.ErrUnknownUnit) {
+			writeError(w, "unknown unit", http.StatusBadRequest, "unitId must be one of the product's units")
+			return
+		}
+		if errors.Is(err, internal.ErrUnitItemsRequired) {
+			writeError(w, "unit items required", http.StatusBadRequest, "units of a booking with unit types must be changed with unitItems")
+			return
+		}
+		if errors.Is(err, internal.ErrNotAvailable) {
+			writeError(w, "not available", http.StatusConflict)
+			return
+		}
+		var statusErr internal.BookingStatusError
+		if errors.As(err, &statusErr) {
+			writeError(w, statusErr.Error(), http.StatusConflict)
+			return
+		}
+
+		writeError(w, "failed to update booking", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	a.booking(r.Context(), w, int(id), user.ID, internal.CapabilityRequest(capability))
+}
+
+func (a API) ExtendBooking(w http.ResponseWriter, r *http.Request) {
+	var capability CapabilityRequest
+	if err := capability.UnmarshalHTTP(r); err != nil {
+		writeError(w, "failed to decode capability", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var id IDPathValue
+	if err := id.UnmarshalHTTP(r); err != nil {
+		writeError(w, "invalid booking ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var extendReq ExtendBookingRequest
+	if err := extendReq.UnmarshalHTTP(r); err != nil {
+		writeError(w, "failed to decode extend booking request", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, _ := auth.ContextUser(r.Context())
+	hold := time.Duration(extendReq.ExpirationMinutes) * time.Minute
+	if err := a.service.ExtendBooking(r.Context(), int(id), user.ID, hold); err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			writeError(w, "booking not found", http.StatusNotFound)
+			return
+		}
+		var statusErr internal.BookingStatusError
+		if errors.As(err, &statusErr) {
+			writeError(w, statusErr.Error(), http.StatusConflict)
+			return
+		}
+
+		writeError(w, "failed to extend booking", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	a.booking(r.Context(), w, int(id), user.ID, internal.CapabilityRequest(capability))
+}
+
+func (a API) CancelBooking(w http.ResponseWriter, r *http.Request) {
+	var capability CapabilityRequest
+	if err := capability.UnmarshalHTTP(r); err != nil {
+		writeError(w, "failed to decode capability", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var id IDPathValue
+	if err := id.UnmarshalHTTP(r); err != nil {
+		writeError(w, "invalid booking ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var cancelReq CancelBookingRequest
+	if err := cancelReq.UnmarshalHTTP(r); err != nil {
+		writeError(w, "failed to decode cancel booking request", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, _ := auth.ContextUser(r.Context())
+	if err := a.service.CancelBooking(r.Context(), int(id), user.ID, cancelReq.Reason); err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			writeError(w, "booking not found", http.StatusNotFound)
+			return
+		}
+
+		var statusErr internal.BookingStatusError
+		if errors.As(err, &statusErr) {
+			writeError(w, statusErr.Error(), http.StatusConflict)
+			return
+		}
+
+		writeError(w, "failed to cancel booking", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	a.booking(r.Context(), w, int(id), user.ID, internal.CapabilityRequest(capability))
+}
+
 func (a API) Booking(w http.ResponseWriter, r *http.Request) {
 	var capability CapabilityRequest
 	if err := capability.UnmarshalHTTP(r); err != nil {
@@ -210,6 +483,226 @@ func (a API) Booking(w http.ResponseWriter, r *http.Request) {
 	a.booking(r.Context(), w, int(id), user.ID, internal.CapabilityRequest(capability))
 }
 
+func (a API) Bookings(w http.ResponseWriter, r *http.Request) {
+	var capability CapabilityRequest
+	if err := capability.UnmarshalHTTP(r); err != nil {
+		writeError(w, "failed to decode capability", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var bookingsReq BookingsRequest
+	if err := bookingsReq.UnmarshalHTTP(r); err != nil {
+		writeError(w, "invalid bookings query", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, _ := auth.ContextUser(r.Context())
+	filter := internal.BookingsFilter{
+		UserID:            user.ID,
+		Status:            bookingsReq.Status,
+		ProductID:         bookingsReq.ProductID,
+		LocalDateStart:    bookingsReq.LocalDateStart,
+		LocalDateEnd:      bookingsReq.LocalDateEnd,
+		CreatedAtStart:    bookingsReq.CreatedAtStart,
+		CreatedAtEnd:      bookingsReq.CreatedAtEnd,
+		UUID:              bookingsReq.UUID,
+		ResellerReference: bookingsReq.ResellerReference,
+		Cursor:            int(bookingsReq.Cursor),
+		Limit:             bookingsReq.Limit,
+	}
+	page, err := a.service.Bookings(r.Context(), filter, internal.CapabilityRequest(capability))
+	if err != nil {
+		writeError(w, "failed to get bookings", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := BookingsResponse{
+		Bookings: page.Bookings,
+	}
+	if page.NextCursor != 0 {
+		resp.NextCursor = platform.ToPtr(Cursor(page.NextCursor))
+	}
+
+	_ = writeJSON(w, http.StatusOK, resp)
+}
+
+func (a API) TicketPNG(w http.ResponseWriter, r *http.Request) {
+	art, ok := a.ticketArtwork(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	_ = art.writePNG(w)
+}
+
+func (a API) TicketSVG(w http.ResponseWriter, r *http.Request) {
+	art, ok := a.ticketArtwork(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	_ = art.writeSVG(w)
+}
+
+// ticketArtwork encodes the ticket of the unit in the request path.
+// It writes an error and returns false if the ticket cannot be encoded.
+func (a API) ticketArtwork(w http.ResponseWriter, r *http.Request) (artwork, bool) {
+	var id IDPathValue
+	if err := id.UnmarshalHTTP(r); err != nil {
+		writeError(w, "invalid booking ID", http.StatusBadRequest, err.Error())
+		return artwork{}, false
+	}
+
+	unitID, err := strconv.Atoi(r.PathValue("unitId"))
+	if err != nil {
+		writeError(w, "invalid unit ID", http.StatusBadRequest, err.Error())
+		return artwork{}, false
+	}
+
+	user, _ := auth.ContextUser(r.Context())
+	ticket, err := a.service.UnitTicket(r.Context(), int(id), unitID, user.ID)
+	if err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			writeError(w, "ticket not found", http.StatusNotFound)
+			return artwork{}, false
+		}
+
+		writeError(w, "failed to get ticket", http.StatusInternalServerError, err.Error())
+		return artwork{}, false
+	}
+
+	art, err := newArtwork(ticket)
+	if err != nil {
+		if errors.Is(err, errTicketTooLong) {
+			writeError(w, "ticket too long for the delivery format", http.StatusUnprocessableEntity, err.Error())
+			return artwork{}, false
+		}
+
+		writeError(w, "failed to encode ticket", http.StatusInternalServerError, err.Error())
+		return artwork{}, false
+	}
+
+	return art, true
+}
+
+func (a API) RedeemTicket(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+
+	user, _ := auth.ContextUser(r.Context())
+	redemption, err := a.service.RedeemTicket(r.Context(), code, user.ID)
+	if err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			writeError(w, "ticket not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, internal.ErrWrongDate) {
+			writeError(w, "wrong date", http.StatusConflict, "ticket is not valid today")
+			return
+		}
+		var statusErr internal.BookingStatusError
+		if errors.As(err, &statusErr) {
+			writeError(w, statusErr.Error(), http.StatusConflict)
+			return
+		}
+		var redeemedErr internal.TicketRedeemedError
+		if errors.As(err, &redeemedErr) {
+			writeError(w, redeemedErr.Error(), http.StatusConflict)
+			return
+		}
+
+		writeError(w, "failed to redeem ticket", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, redemption)
+}
+
+func (a API) JoinWaitlist(w http.ResponseWriter, r *http.Request) {
+	var id IDPathValue
+	if err := id.UnmarshalHTTP(r); err != nil {
+		writeError(w, "invalid availability ID", http.StatusBadRequest, err.Error())
+		return
+	}
+	var currency CurrencyRequest
+	if err := currency.UnmarshalHTTP(r); err != nil {
+		writeError(w, "invalid currency", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var waitlistReq WaitlistRequest
+	if err := waitlistReq.UnmarshalHTTP(r); err != nil {
+		writeError(w, "failed to decode waitlist request", http.StatusBadRequest, err.Error())
+		return
+	}
+	if waitlistReq.Currency != "" {
+		currency = waitlistReq.Currency
+	}
+
+	user, _ := auth.ContextUser(r.Context())
+	params := internal.JoinWaitlistRequest{
+		AvailabilityID: int(id),
+		OptionID:       waitlistReq.OptionID,
+		UserID:         user.ID,
+		Units:          waitlistReq.Units,
+		UnitIDs:        waitlistReq.UnitIDs(),
+		WebhookURL:     waitlistReq.WebhookURL,
+		Currency:       string(currency),
+	}
+	entryID, err := a.service.JoinWaitlist(r.Context(), params)
+	if err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			writeError(w, "availability not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, internal.ErrWebhookNotAllowed) {
+			writeError(w, "webhook not allowed", http.StatusBadRequest, "webhookUrl must resolve to public addresses only")
+			return
+		}
+		if errors.Is(err, internal.ErrUnknownUnit) {
+			writeError(w, "unknown unit", http.StatusBadRequest, "unitId must be one of the product's units")
+			return
+		}
+		var currencyErr internal.UnsupportedCurrencyError
+		if errors.As(err, &currencyErr) {
+			writeError(w, "unsupported currency", http.StatusBadRequest, currencyErr.Error())
+			return
+		}
+
+		writeError(w, "failed to join waitlist", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	a.waitlistEntry(r.Context(), w, entryID, user.ID)
+}
+
+func (a API) WaitlistEntry(w http.ResponseWriter, r *http.Request) {
+	var id IDPathValue
+	if err := id.UnmarshalHTTP(r); err != nil {
+		writeError(w, "invalid waitlist entry ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, _ := auth.ContextUser(r.Context())
+	a.waitlistEntry(r.Context(), w, int(id), user.ID)
+}
+
+func (a API) waitlistEntry(ctx context.Context, w http.ResponseWriter, id, userID int) {
+	entry, err := a.service.WaitlistEntry(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, internal.ErrNotFound) {
+			writeError(w, "waitlist entry not found", http.StatusNotFound)
+			return
+		}
+
+		writeError(w, "failed to get waitlist entry", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	_ = writeJSON(w, http.StatusOK, entry)
+}
+
 func (a API) booking(ctx context.Context, w http.ResponseWriter, id, userID int, capability internal.CapabilityRequest) {
 	booking, err := a.service.Booking(ctx, int(id), userID, internal.CapabilityRequest(capability))
 	if err != nil {
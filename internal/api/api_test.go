@@ -1,11 +1,14 @@
 package api_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +18,7 @@ import (
 	"github.com/dmksnnk/octo/internal/auth"
 	"github.com/dmksnnk/octo/internal/platform"
 	"github.com/dmksnnk/octo/internal/platform/golden"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -27,13 +31,25 @@ func TestAPIProducts(t *testing.T) {
 	t.Run("products", func(t *testing.T) {
 		products := []internal.Product{
 			internal.ProductBase{
-				ID:       "1",
-				Name:     "Product 1",
-				Capacity: 10,
+				ID:                  "1",
+				Name:                "Product 1",
+				Capacity:            10,
+				AvailabilityType:    internal.AvailabilityTypeStartTime,
+				Timezone:            "Europe/Berlin",
+				DeliveryFormat:      internal.DeliveryFormatQRCode,
+				DeliveryMethods:     []internal.DeliveryMethod{internal.DeliveryMethodTicket},
+				DefaultCurrency:     "EUR",
+				AvailableCurrencies: []string{"EUR"},
+				Options: []internal.Option{
+					internal.OptionBase{ID: internal.DefaultOptionID, Name: "Default", Capacity: 10},
+				},
+				Units: []internal.UnitType{
+					internal.UnitTypeBase{ID: "adult", Name: "Adult"},
+				},
 			},
 		}
 		svc := mocks.NewMockService(t)
-		svc.On("Products", mock.Anything, internal.CapabilityRequestNone).Return(products, nil)
+		svc.On("Products", mock.Anything, internal.CapabilityRequestNone, "").Return(products, nil)
 
 		srv := newTestServer(t, svc)
 		client := srv.Client()
@@ -46,22 +62,58 @@ func TestAPIProducts(t *testing.T) {
 		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "products.json"))
 	})
 
+	t.Run("products unsupported currency", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("Products", mock.Anything, internal.CapabilityRequestNone, "USD").
+			Return(nil, internal.UnsupportedCurrencyError{Currency: "USD", Available: []string{"EUR", "GBP"}})
+
+		srv := newTestServer(t, svc)
+		client := srv.Client()
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/products", http.NoBody)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		req.Header.Set("Octo-Currency", "USD")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "product-unsupported-currency.json"))
+	})
+
 	t.Run("products with price", func(t *testing.T) {
 		products := []internal.Product{
 			internal.ProductWithPrice{
 				ProductBase: internal.ProductBase{
-					ID:       "1",
-					Name:     "Product 1",
-					Capacity: 10,
-				},
-				CapabilityPrice: internal.CapabilityPrice{
-					Price:    100,
-					Currency: "EUR",
+					ID:                  "1",
+					Name:                "Product 1",
+					Capacity:            10,
+					AvailabilityType:    internal.AvailabilityTypeStartTime,
+					Timezone:            "Europe/Berlin",
+					DeliveryFormat:      internal.DeliveryFormatQRCode,
+					DeliveryMethods:     []internal.DeliveryMethod{internal.DeliveryMethodTicket},
+					DefaultCurrency:     "EUR",
+					AvailableCurrencies: []string{"EUR"},
+					Options: []internal.Option{
+						internal.OptionWithPrice{
+							OptionBase:      internal.OptionBase{ID: internal.DefaultOptionID, Name: "Default", Capacity: 10},
+							CapabilityPrice: eurPrice(100),
+						},
+					},
+					Units: []internal.UnitType{
+						internal.UnitTypeWithPrice{
+							UnitTypeBase:    internal.UnitTypeBase{ID: "adult", Name: "Adult"},
+							CapabilityPrice: eurPrice(100),
+						},
+					},
 				},
+				CapabilityPrice: eurPrice(100),
 			},
 		}
 		svc := mocks.NewMockService(t)
-		svc.On("Products", mock.Anything, internal.CapabilityRequestPrice).Return(products, nil)
+		svc.On("Products", mock.Anything, internal.CapabilityRequestPrice, "").Return(products, nil)
 		srv := newTestServer(t, svc)
 		client := srv.Client()
 
@@ -83,12 +135,24 @@ func TestAPIProducts(t *testing.T) {
 func TestAPIProduct(t *testing.T) {
 	t.Run("product", func(t *testing.T) {
 		product := internal.ProductBase{
-			ID:       "1",
-			Name:     "Product 1",
-			Capacity: 10,
+			ID:                  "1",
+			Name:                "Product 1",
+			Capacity:            10,
+			AvailabilityType:    internal.AvailabilityTypeStartTime,
+			Timezone:            "Europe/Berlin",
+			DeliveryFormat:      internal.DeliveryFormatQRCode,
+			DeliveryMethods:     []internal.DeliveryMethod{internal.DeliveryMethodTicket},
+			DefaultCurrency:     "EUR",
+			AvailableCurrencies: []string{"EUR"},
+			Options: []internal.Option{
+				internal.OptionBase{ID: internal.DefaultOptionID, Name: "Default", Capacity: 10},
+			},
+			Units: []internal.UnitType{
+				internal.UnitTypeBase{ID: "adult", Name: "Adult"},
+			},
 		}
 		svc := mocks.NewMockService(t)
-		svc.On("Product", mock.Anything, 1, internal.CapabilityRequestNone).Return(product, nil)
+		svc.On("Product", mock.Anything, 1, internal.CapabilityRequestNone, "").Return(product, nil)
 
 		srv := newTestServer(t, svc)
 		client := srv.Client()
@@ -116,7 +180,7 @@ func TestAPIProduct(t *testing.T) {
 
 	t.Run("product not found", func(t *testing.T) {
 		svc := mocks.NewMockService(t)
-		svc.On("Product", mock.Anything, 1, internal.CapabilityRequestNone).Return(nil, internal.ErrNotFound)
+		svc.On("Product", mock.Anything, 1, internal.CapabilityRequestNone, "").Return(nil, internal.ErrNotFound)
 
 		srv := newTestServer(t, svc)
 		client := srv.Client()
@@ -128,20 +192,103 @@ func TestAPIProduct(t *testing.T) {
 
 		assertEqualResponse(t, resp, http.StatusNotFound, golden.ReadBytes(t, "product-not-found.json"))
 	})
+
+	t.Run("currency", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("Product", mock.Anything, 1, internal.CapabilityRequestPrice, "USD").Return(internal.ProductWithPrice{}, nil)
+
+		srv := newTestServer(t, svc)
+		client := srv.Client()
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/products/1", http.NoBody)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		req.Header.Set("Capability", "price")
+		req.Header.Set("Octo-Currency", "usd")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+		}
+	})
+
+	t.Run("invalid currency", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		srv := newTestServer(t, svc)
+		client := srv.Client()
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/products/1", http.NoBody)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		req.Header.Set("Octo-Currency", "euro")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "product-invalid-currency.json"))
+	})
+
+	t.Run("unsupported currency", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("Product", mock.Anything, 1, internal.CapabilityRequestPrice, "USD").
+			Return(nil, internal.UnsupportedCurrencyError{Currency: "USD", Available: []string{"EUR", "GBP"}})
+
+		srv := newTestServer(t, svc)
+		client := srv.Client()
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/products/1", http.NoBody)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		req.Header.Set("Capability", "price")
+		req.Header.Set("Octo-Currency", "USD")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "product-unsupported-currency.json"))
+	})
 }
 
 func TestAPIAvailability(t *testing.T) {
 	t.Run("single availability", func(t *testing.T) {
 		localDate := platform.Must(time.Parse("2006-01-02", "2025-01-20"))
-		availability := internal.AvailabilityBase{
-			ID:        "123",
-			LocalDate: internal.Date(localDate),
-			Status:    internal.AvailabilityStatusAvailable,
-			Vacancies: 10,
-			Available: true,
+		berlin := platform.Must(time.LoadLocation("Europe/Berlin"))
+		availabilities := []internal.Availability{
+			internal.AvailabilityBase{
+				ID:                 "123",
+				LocalDate:          internal.Date(localDate),
+				LocalDateTimeStart: time.Date(2025, 1, 20, 9, 0, 0, 0, berlin),
+				LocalDateTimeEnd:   time.Date(2025, 1, 20, 11, 0, 0, 0, berlin),
+				Status:             internal.AvailabilityStatusAvailable,
+				Capacity:           20,
+				Vacancies:          10,
+				Utilization:        0.5,
+				Available:          true,
+				OpeningHours:       []internal.OpeningHours{},
+			},
+			internal.AvailabilityBase{
+				ID:                 "124",
+				LocalDate:          internal.Date(localDate),
+				LocalDateTimeStart: time.Date(2025, 1, 20, 13, 0, 0, 0, berlin),
+				LocalDateTimeEnd:   time.Date(2025, 1, 20, 15, 0, 0, 0, berlin),
+				Status:             internal.AvailabilityStatusSoldOut,
+				Capacity:           20,
+				Vacancies:          0,
+				Utilization:        1,
+				Available:          false,
+				OpeningHours:       []internal.OpeningHours{},
+			},
 		}
 		svc := mocks.NewMockService(t)
-		svc.On("Availability", mock.Anything, 1, localDate, internal.CapabilityRequestNone).Return(availability, nil)
+		svc.On("Availabilities", mock.Anything, 1, internal.DefaultOptionID, localDate, localDate, internal.CapabilityRequestNone, "").Return(availabilities, nil)
 		srv := newTestServer(t, svc)
 		client := srv.Client()
 		resp, err := client.Post(srv.URL+"/availability", "application/json", golden.Open(t, "availability-request-single.json"))
@@ -153,10 +300,10 @@ func TestAPIAvailability(t *testing.T) {
 		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "availability-single.json"))
 	})
 
-	t.Run("single availability not found", func(t *testing.T) {
+	t.Run("single availability none", func(t *testing.T) {
 		localDate := platform.Must(time.Parse("2006-01-02", "2025-01-20"))
 		svc := mocks.NewMockService(t)
-		svc.On("Availability", mock.Anything, 1, localDate, internal.CapabilityRequestNone).Return(nil, internal.ErrNotFound)
+		svc.On("Availabilities", mock.Anything, 1, internal.DefaultOptionID, localDate, localDate, internal.CapabilityRequestNone, "").Return([]internal.Availability{}, nil)
 
 		srv := newTestServer(t, svc)
 		client := srv.Client()
@@ -169,30 +316,142 @@ func TestAPIAvailability(t *testing.T) {
 		assertEqualResponse(t, resp, http.StatusOK, []byte("[]\n"))
 	})
 
+	t.Run("single availability for option", func(t *testing.T) {
+		localDate := platform.Must(time.Parse("2006-01-02", "2025-01-20"))
+		availability := internal.AvailabilityBase{
+			ID:                 "124",
+			LocalDate:          internal.Date(localDate),
+			LocalDateTimeStart: localDate,
+			LocalDateTimeEnd:   localDate.AddDate(0, 0, 1),
+			AllDay:             true,
+			Status:             internal.AvailabilityStatusAvailable,
+			Capacity:           10,
+			Vacancies:          10,
+			Utilization:        0,
+			Available:          true,
+			OpeningHours:       []internal.OpeningHours{},
+		}
+		svc := mocks.NewMockService(t)
+		svc.On("Availabilities", mock.Anything, 1, "MORNING", localDate, localDate, internal.CapabilityRequestNone, "").Return([]internal.Availability{availability}, nil)
+		srv := newTestServer(t, svc)
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/availability", "application/json", golden.Open(t, "availability-request-option.json"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "availability-option.json"))
+	})
+
+	t.Run("opening hours availability", func(t *testing.T) {
+		localDate := platform.Must(time.Parse("2006-01-02", "2025-01-20"))
+		availability := internal.AvailabilityBase{
+			ID:                 "125",
+			LocalDate:          internal.Date(localDate),
+			LocalDateTimeStart: localDate,
+			LocalDateTimeEnd:   localDate.AddDate(0, 0, 1),
+			AllDay:             true,
+			Status:             internal.AvailabilityStatusAvailable,
+			Capacity:           400,
+			Vacancies:          100,
+			Utilization:        0.75,
+			Available:          true,
+			OpeningHours: []internal.OpeningHours{
+				{From: "09:00", To: "12:00"},
+				{From: "13:00", To: "18:30"},
+			},
+		}
+		svc := mocks.NewMockService(t)
+		svc.On("Availabilities", mock.Anything, 1, internal.DefaultOptionID, localDate, localDate, internal.CapabilityRequestNone, "").Return([]internal.Availability{availability}, nil)
+		srv := newTestServer(t, svc)
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/availability", "application/json", golden.Open(t, "availability-request-single.json"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "availability-opening-hours.json"))
+	})
+
 	t.Run("single availability with price range", func(t *testing.T) {
 		localDateStart := platform.Must(time.Parse("2006-01-02", "2025-01-20"))
 		localDateEnd := platform.Must(time.Parse("2006-01-02", "2025-01-25"))
 		availabilities := []internal.Availability{
 			internal.AvailabilityWithPrice{
 				AvailabilityBase: internal.AvailabilityBase{
-					ID:        "123",
+					ID:                 "123",
+					LocalDate:          internal.Date(localDateStart),
+					LocalDateTimeStart: localDateStart,
+					LocalDateTimeEnd:   localDateStart.AddDate(0, 0, 1),
+					AllDay:             true,
+					Status:             internal.AvailabilityStatusAvailable,
+					Capacity:           40,
+					Vacancies:          10,
+					Utilization:        0.75,
+					Available:          true,
+					OpeningHours:       []internal.OpeningHours{},
+				},
+				CapabilityPrice: eurPrice(100),
+			},
+		}
+		svc := mocks.NewMockService(t)
+		svc.On("Availabilities", mock.Anything, 1, internal.DefaultOptionID, localDateStart, localDateEnd, internal.CapabilityRequestPrice, "").Return(availabilities, nil)
+
+		srv := newTestServer(t, svc)
+		client := srv.Client()
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/availability", golden.Open(t, "availability-request-range.json"))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		req.Header.Set("Capability", "price")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "availability-range-with-price.json"))
+	})
+
+	t.Run("calendar with price", func(t *testing.T) {
+		localDateStart := platform.Must(time.Parse("2006-01-02", "2025-01-20"))
+		localDateEnd := platform.Must(time.Parse("2006-01-02", "2025-01-25"))
+		price := eurPrice(100)
+		calendar := []internal.AvailabilityCalendar{
+			internal.AvailabilityCalendarWithPrice{
+				AvailabilityCalendarBase: internal.AvailabilityCalendarBase{
 					LocalDate: internal.Date(localDateStart),
 					Status:    internal.AvailabilityStatusAvailable,
-					Vacancies: 10,
+					Vacancies: 30,
+					Available: true,
+				},
+				CapabilityPrice: price,
+			},
+			internal.AvailabilityCalendarWithPrice{
+				AvailabilityCalendarBase: internal.AvailabilityCalendarBase{
+					LocalDate: internal.Date(localDateStart.AddDate(0, 0, 1)),
+					Status:    internal.AvailabilityStatusLimited,
+					Vacancies: 5,
 					Available: true,
 				},
-				CapabilityPrice: internal.CapabilityPrice{
-					Price:    100,
-					Currency: "EUR",
+				CapabilityPrice: price,
+			},
+			internal.AvailabilityCalendarWithPrice{
+				AvailabilityCalendarBase: internal.AvailabilityCalendarBase{
+					LocalDate: internal.Date(localDateStart.AddDate(0, 0, 2)),
+					Status:    internal.AvailabilityStatusClosed,
 				},
+				CapabilityPrice: price,
 			},
 		}
 		svc := mocks.NewMockService(t)
-		svc.On("Availabilities", mock.Anything, 1, localDateStart, localDateEnd, internal.CapabilityRequestPrice).Return(availabilities, nil)
+		svc.On("AvailabilityCalendar", mock.Anything, 1, localDateStart, localDateEnd, internal.CapabilityRequestPrice, "").Return(calendar, nil)
 
 		srv := newTestServer(t, svc)
 		client := srv.Client()
-		req, err := http.NewRequest(http.MethodPost, srv.URL+"/availability", golden.Open(t, "availability-request-range.json"))
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/availability/calendar", golden.Open(t, "availability-request-range.json"))
 		if err != nil {
 			t.Fatalf("failed to create request: %v", err)
 		}
@@ -203,19 +462,48 @@ func TestAPIAvailability(t *testing.T) {
 		}
 		defer resp.Body.Close()
 
-		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "availability-range-with-price.json"))
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "availability-calendar-with-price.json"))
+	})
+
+	t.Run("calendar invalid range", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		body := strings.NewReader(`{"productId": "1", "localDateStart": "2025-01-25", "localDateEnd": "2025-01-20"}`)
+		resp, err := client.Post(srv.URL+"/availability/calendar", "application/json", body)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "availability-calendar-invalid-range.json"))
 	})
 }
 
 func TestAPIBooking(t *testing.T) {
+	expiresAt := platform.Must(time.Parse(time.RFC3339, "2025-01-20T10:30:00Z"))
+	bookingUUID := uuid.MustParse("0b7e4c2e-6d7e-4c55-9a4b-6f0f4a1d2c3e")
+	contact := internal.Contact{
+		FullName:     platform.ToPtr("John Doe"),
+		EmailAddress: platform.ToPtr("john.doe@example.com"),
+		PhoneNumber:  platform.ToPtr("+4915112345678"),
+		Locales:      []string{"en-GB", "de"},
+		Country:      platform.ToPtr("DE"),
+	}
 	booking := internal.BookingBase{
 		ID:             "123",
+		UUID:           bookingUUID.String(),
 		ProductID:      "1",
+		OptionID:       internal.DefaultOptionID,
 		AvailabilityID: "123",
 		Status:         internal.BookingStatusReserved,
+		UTCExpiresAt:   &expiresAt,
+		Contact:        contact,
 		Units: []internal.Unit{
 			internal.UnitBase{
 				ID:     "1",
+				UnitID: platform.ToPtr("adult"),
 				Ticket: platform.ToPtr("ticket 1"),
 			},
 		},
@@ -223,26 +511,25 @@ func TestAPIBooking(t *testing.T) {
 	bookingWithPrice := internal.BookingWithPrice{
 		BookingBase: internal.BookingBase{
 			ID:             "123",
+			UUID:           bookingUUID.String(),
 			ProductID:      "1",
+			OptionID:       internal.DefaultOptionID,
 			AvailabilityID: "123",
 			Status:         internal.BookingStatusReserved,
+			UTCExpiresAt:   &expiresAt,
+			Contact:        contact,
 			Units: []internal.Unit{
 				internal.UnitWithPrice{
 					UnitBase: internal.UnitBase{
 						ID:     "1",
+						UnitID: platform.ToPtr("adult"),
 						Ticket: platform.ToPtr("ticket 1"),
 					},
-					CapabilityPrice: internal.CapabilityPrice{
-						Price:    100,
-						Currency: "EUR",
-					},
+					CapabilityPrice: eurPrice(100),
 				},
 			},
 		},
-		CapabilityPrice: internal.CapabilityPrice{
-			Price:    100,
-			Currency: "EUR",
-		},
+		CapabilityPrice: eurPrice(100),
 	}
 
 	t.Run("get booking", func(t *testing.T) {
@@ -280,6 +567,62 @@ func TestAPIBooking(t *testing.T) {
 		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "booking-with-price.json"))
 	})
 
+	t.Run("list bookings", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		filter := internal.BookingsFilter{
+			UserID:            user.ID,
+			Status:            internal.BookingStatusReserved,
+			ProductID:         1,
+			LocalDateStart:    platform.Must(time.Parse(time.DateOnly, "2025-01-20")),
+			LocalDateEnd:      platform.Must(time.Parse(time.DateOnly, "2025-01-25")),
+			CreatedAtStart:    platform.Must(time.Parse(time.RFC3339, "2025-01-01T00:00:00Z")),
+			UUID:              bookingUUID,
+			ResellerReference: "REF-123",
+			Cursor:            100,
+			Limit:             1,
+		}
+		page := internal.BookingsPage{
+			Bookings:   []internal.Booking{booking},
+			NextCursor: 123,
+		}
+		svc.On("Bookings", mock.Anything, filter, internal.CapabilityRequestNone).Return(page, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		query := url.Values{
+			"status":            {"RESERVED"},
+			"productId":         {"1"},
+			"localDateStart":    {"2025-01-20"},
+			"localDateEnd":      {"2025-01-25"},
+			"utcCreatedAtStart": {"2025-01-01T00:00:00Z"},
+			"uuid":              {bookingUUID.String()},
+			"resellerReference": {"REF-123"},
+			"cursor":            {"MTAw"},
+			"limit":             {"1"},
+		}
+		resp, err := client.Get(srv.URL + "/bookings?" + query.Encode())
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "bookings.json"))
+	})
+
+	t.Run("list bookings invalid query", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Get(srv.URL + "/bookings?status=PENDING")
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "bookings-invalid-query.json"))
+	})
+
 	t.Run("create booking", func(t *testing.T) {
 		svc := mocks.NewMockService(t)
 		params := internal.CreateBookingRequest{
@@ -302,6 +645,45 @@ func TestAPIBooking(t *testing.T) {
 		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "booking.json"))
 	})
 
+	t.Run("create booking with reseller reference", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		params := internal.CreateBookingRequest{
+			ProductID:         1,
+			AvailabilityID:    123,
+			Units:             1,
+			UserID:            user.ID,
+			UUID:              bookingUUID,
+			ResellerReference: "REF-123",
+		}
+		svc.On("CreateBooking", mock.Anything, params).Return(123, nil)
+		svc.On("Booking", mock.Anything, 123, user.ID, internal.CapabilityRequestNone).Return(booking, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/bookings", "application/json", golden.Open(t, "booking-create-reseller-request.json"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "booking.json"))
+	})
+
+	t.Run("create booking duplicate", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("CreateBooking", mock.Anything, mock.Anything).Return(0, internal.ErrAlreadyExists)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/bookings", "application/json", golden.Open(t, "booking-create-reseller-request.json"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusConflict, golden.ReadBytes(t, "booking-duplicate.json"))
+	})
+
 	t.Run("create booking conflict", func(t *testing.T) {
 		svc := mocks.NewMockService(t)
 		params := internal.CreateBookingRequest{
@@ -323,14 +705,21 @@ func TestAPIBooking(t *testing.T) {
 		assertEqualResponse(t, resp, http.StatusConflict, golden.ReadBytes(t, "booking-conflict.json"))
 	})
 
-	t.Run("confirm booking", func(t *testing.T) {
+	t.Run("create booking with unit items", func(t *testing.T) {
 		svc := mocks.NewMockService(t)
-		svc.On("ConfirmBooking", mock.Anything, 123, user.ID).Return(nil)
+		params := internal.CreateBookingRequest{
+			ProductID:      1,
+			AvailabilityID: 123,
+			Units:          2,
+			UnitIDs:        []string{"adult", "child"},
+			UserID:         user.ID,
+		}
+		svc.On("CreateBooking", mock.Anything, params).Return(123, nil)
 		svc.On("Booking", mock.Anything, 123, user.ID, internal.CapabilityRequestNone).Return(booking, nil)
 		srv := newTestServer(t, svc)
 
 		client := srv.Client()
-		resp, err := client.Post(srv.URL+"/bookings/123/confirm", "application/json", golden.Open(t, "booking-create-request.json"))
+		resp, err := client.Post(srv.URL+"/bookings", "application/json", golden.Open(t, "booking-create-unit-items-request.json"))
 		if err != nil {
 			t.Fatalf("failed to make request: %v", err)
 		}
@@ -338,36 +727,783 @@ func TestAPIBooking(t *testing.T) {
 
 		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "booking.json"))
 	})
-}
-
-func newTestServer(t *testing.T, svc api.Service) *httptest.Server {
-	t.Helper()
-
-	router := api.NewRouter(api.NewAPI(svc))
-	srv := httptest.NewServer(
-		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ctx := auth.ContextWithUser(r.Context(), user)
-			router.ServeHTTP(w, r.WithContext(ctx))
-		}),
-	)
-	t.Cleanup(srv.Close)
 
-	return srv
-}
+	t.Run("create booking for option", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		params := internal.CreateBookingRequest{
+			ProductID:      1,
+			OptionID:       internal.DefaultOptionID,
+			AvailabilityID: 123,
+			Units:          1,
+			UserID:         user.ID,
+		}
+		svc.On("CreateBooking", mock.Anything, params).Return(123, nil)
+		svc.On("Booking", mock.Anything, 123, user.ID, internal.CapabilityRequestNone).Return(booking, nil)
+		srv := newTestServer(t, svc)
 
-func assertEqualResponse(t *testing.T, resp *http.Response, wantStatus int, wantBody []byte) {
-	t.Helper()
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/bookings", "application/json", golden.Open(t, "booking-create-option-request.json"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
 
-	if resp.StatusCode != wantStatus {
-		t.Errorf("want status %d, got %d", wantStatus, resp.StatusCode)
-	}
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "booking.json"))
+	})
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		t.Fatalf("failed to read response body: %v", err)
-	}
+	t.Run("create booking in currency", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		params := internal.CreateBookingRequest{
+			ProductID:      1,
+			AvailabilityID: 123,
+			Units:          1,
+			UserID:         user.ID,
+			Currency:       "GBP", // body overrides the header
+		}
+		svc.On("CreateBooking", mock.Anything, params).Return(123, nil)
+		svc.On("Booking", mock.Anything, 123, user.ID, internal.CapabilityRequestNone).Return(booking, nil)
+		srv := newTestServer(t, svc)
 
-	assertEqualJSON(t, body, wantBody)
+		client := srv.Client()
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/bookings", golden.Open(t, "booking-create-currency-request.json"))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Octo-Currency", "USD")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "booking.json"))
+	})
+
+	t.Run("create booking unsupported currency", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("CreateBooking", mock.Anything, mock.Anything).
+			Return(0, internal.UnsupportedCurrencyError{Currency: "GBP", Available: []string{"EUR"}})
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/bookings", "application/json", golden.Open(t, "booking-create-currency-request.json"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "booking-unsupported-currency.json"))
+	})
+
+	t.Run("create booking with promo code", func(t *testing.T) {
+		discounted := bookingWithPrice
+		discounted.Price = 90
+		discounted.Pricing.Retail = 90
+		discounted.Pricing.IncludedTaxes = internal.IncludedTaxes{{Name: "VAT 19%", Retail: 13, Net: platform.ToPtr(12)}}
+		discounted.Discount = &internal.Discount{
+			PromoCode: "SUMMER10",
+			Type:      internal.DiscountTypePercentage,
+			Value:     10,
+			Amount:    10,
+			Currency:  "EUR",
+		}
+		svc := mocks.NewMockService(t)
+		params := internal.CreateBookingRequest{
+			ProductID:      1,
+			AvailabilityID: 123,
+			Units:          1,
+			UserID:         user.ID,
+			PromoCode:      "summer10",
+		}
+		svc.On("CreateBooking", mock.Anything, params).Return(123, nil)
+		svc.On("Booking", mock.Anything, 123, user.ID, internal.CapabilityRequestPrice).Return(discounted, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/bookings", golden.Open(t, "booking-create-promo-code-request.json"))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Capability", "price")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "booking-with-discount.json"))
+	})
+
+	t.Run("create booking invalid promo code", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("CreateBooking", mock.Anything, mock.Anything).
+			Return(0, internal.InvalidPromoCodeError{Code: "summer10", Reason: "expired"})
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/bookings", "application/json", golden.Open(t, "booking-create-promo-code-request.json"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "booking-invalid-promo-code.json"))
+	})
+
+	t.Run("create booking unknown unit", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("CreateBooking", mock.Anything, mock.Anything).Return(0, internal.ErrUnknownUnit)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/bookings", "application/json", golden.Open(t, "booking-create-unit-items-request.json"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "booking-unknown-unit.json"))
+	})
+
+	t.Run("create booking units mismatch", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		body := strings.NewReader(`{"productId":"1","availabilityId":"123","units":3,"unitItems":[{"unitId":"adult"}]}`)
+		resp, err := client.Post(srv.URL+"/bookings", "application/json", body)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "booking-units-mismatch.json"))
+	})
+
+	t.Run("confirm booking", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("ConfirmBooking", mock.Anything, internal.ConfirmBookingRequest{ID: 123, UserID: user.ID}).Return(nil)
+		svc.On("Booking", mock.Anything, 123, user.ID, internal.CapabilityRequestNone).Return(booking, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/bookings/123/confirm", "application/json", golden.Open(t, "booking-create-request.json"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "booking.json"))
+	})
+
+	t.Run("confirm booking with contact", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		params := internal.ConfirmBookingRequest{
+			ID:      123,
+			UserID:  user.ID,
+			Contact: &contact,
+		}
+		svc.On("ConfirmBooking", mock.Anything, params).Return(nil)
+		svc.On("Booking", mock.Anything, 123, user.ID, internal.CapabilityRequestNone).Return(booking, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/bookings/123/confirm", "application/json", golden.Open(t, "booking-confirm-request.json"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "booking.json"))
+	})
+
+	t.Run("confirm booking without required contact", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("ConfirmBooking", mock.Anything, internal.ConfirmBookingRequest{ID: 123, UserID: user.ID}).Return(internal.ErrContactRequired)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/bookings/123/confirm", "application/json", http.NoBody)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "booking-contact-required.json"))
+	})
+
+	t.Run("create booking with invalid contact", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/bookings", "application/json", golden.Open(t, "booking-create-invalid-contact-request.json"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "booking-invalid-contact.json"))
+	})
+
+	t.Run("confirm cancelled booking", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("ConfirmBooking", mock.Anything, internal.ConfirmBookingRequest{ID: 123, UserID: user.ID}).Return(internal.BookingStatusError{Status: internal.BookingStatusCancelled})
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/bookings/123/confirm", "application/json", http.NoBody)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusConflict, golden.ReadBytes(t, "booking-cancelled-conflict.json"))
+	})
+
+	t.Run("update booking", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		params := internal.UpdateBookingRequest{
+			ID:             123,
+			UserID:         user.ID,
+			AvailabilityID: 124,
+			Units:          2,
+		}
+		svc.On("UpdateBooking", mock.Anything, params).Return(nil)
+		svc.On("Booking", mock.Anything, 123, user.ID, internal.CapabilityRequestNone).Return(booking, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		req, err := http.NewRequest(http.MethodPatch, srv.URL+"/bookings/123", golden.Open(t, "booking-update-request.json"))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "booking.json"))
+	})
+
+	t.Run("update booking conflict", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		params := internal.UpdateBookingRequest{
+			ID:             123,
+			UserID:         user.ID,
+			AvailabilityID: 124,
+			Units:          2,
+		}
+		svc.On("UpdateBooking", mock.Anything, params).Return(internal.ErrNotAvailable)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		req, err := http.NewRequest(http.MethodPatch, srv.URL+"/bookings/123", golden.Open(t, "booking-update-request.json"))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusConflict, golden.ReadBytes(t, "booking-conflict.json"))
+	})
+
+	t.Run("update booking unit items", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		params := internal.UpdateBookingRequest{
+			ID:      123,
+			UserID:  user.ID,
+			Units:   2,
+			UnitIDs: []string{"adult", "child"},
+		}
+		svc.On("UpdateBooking", mock.Anything, params).Return(nil)
+		svc.On("Booking", mock.Anything, 123, user.ID, internal.CapabilityRequestNone).Return(booking, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		req, err := http.NewRequest(http.MethodPatch, srv.URL+"/bookings/123", golden.Open(t, "booking-update-unit-items-request.json"))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "booking.json"))
+	})
+
+	t.Run("update booking units without unit items", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		params := internal.UpdateBookingRequest{
+			ID:             123,
+			UserID:         user.ID,
+			AvailabilityID: 124,
+			Units:          2,
+		}
+		svc.On("UpdateBooking", mock.Anything, params).Return(internal.ErrUnitItemsRequired)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		req, err := http.NewRequest(http.MethodPatch, srv.URL+"/bookings/123", golden.Open(t, "booking-update-request.json"))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "booking-unit-items-required.json"))
+	})
+
+	t.Run("extend booking", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("ExtendBooking", mock.Anything, 123, user.ID, 15*time.Minute).Return(nil)
+		svc.On("Booking", mock.Anything, 123, user.ID, internal.CapabilityRequestNone).Return(booking, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		req, err := http.NewRequest(http.MethodPatch, srv.URL+"/bookings/123/extend", golden.Open(t, "booking-extend-request.json"))
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "booking.json"))
+	})
+
+	t.Run("extend confirmed booking", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("ExtendBooking", mock.Anything, 123, user.ID, time.Duration(0)).Return(internal.BookingStatusError{Status: internal.BookingStatusConfirmed})
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		req, err := http.NewRequest(http.MethodPatch, srv.URL+"/bookings/123/extend", http.NoBody)
+		if err != nil {
+			t.Fatalf("failed to create request: %v", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusConflict, golden.ReadBytes(t, "booking-confirmed-conflict.json"))
+	})
+
+	t.Run("cancel booking", func(t *testing.T) {
+		cancelledBooking := internal.BookingBase{
+			ID:             "123",
+			UUID:           bookingUUID.String(),
+			ProductID:      "1",
+			OptionID:       internal.DefaultOptionID,
+			AvailabilityID: "123",
+			Status:         internal.BookingStatusCancelled,
+			Contact:        contact,
+			Units: []internal.Unit{
+				internal.UnitBase{
+					ID: "1",
+				},
+			},
+			Cancellation: &internal.Cancellation{
+				Reason:         "Customer changed their plans",
+				UTCCancelledAt: platform.Must(time.Parse(time.RFC3339, "2025-01-20T10:00:00Z")),
+			},
+		}
+		svc := mocks.NewMockService(t)
+		svc.On("CancelBooking", mock.Anything, 123, user.ID, "Customer changed their plans").Return(nil)
+		svc.On("Booking", mock.Anything, 123, user.ID, internal.CapabilityRequestNone).Return(cancelledBooking, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/bookings/123/cancel", "application/json", golden.Open(t, "booking-cancel-request.json"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "booking-cancelled.json"))
+	})
+
+	t.Run("cancel booking without reason", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/bookings/123/cancel", "application/json", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "booking-cancel-no-reason.json"))
+	})
+}
+
+func TestAPITicketArtwork(t *testing.T) {
+	t.Run("QR code svg", func(t *testing.T) {
+		ticket := internal.UnitTicket{
+			Ticket:         "ABCDEFGHIJKLM",
+			DeliveryFormat: internal.DeliveryFormatQRCode,
+		}
+		svc := mocks.NewMockService(t)
+		svc.On("UnitTicket", mock.Anything, 123, 1, user.ID).Return(ticket, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Get(srv.URL + "/bookings/123/units/1/ticket.svg")
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualImage(t, resp, "image/svg+xml", golden.ReadBytes(t, "ticket-qrcode.svg"))
+	})
+
+	t.Run("code128 png", func(t *testing.T) {
+		ticket := internal.UnitTicket{
+			Ticket:         "signed code",
+			Reference:      "ABCDEFGHIJKLM",
+			DeliveryFormat: internal.DeliveryFormatCode128,
+		}
+		svc := mocks.NewMockService(t)
+		svc.On("UnitTicket", mock.Anything, 123, 1, user.ID).Return(ticket, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Get(srv.URL + "/bookings/123/units/1/ticket.png")
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualImage(t, resp, "image/png", golden.ReadBytes(t, "ticket-code128.png"))
+	})
+
+	t.Run("code128 too long", func(t *testing.T) {
+		ticket := internal.UnitTicket{
+			Ticket:         "signed code",
+			Reference:      strings.Repeat("A", 30),
+			DeliveryFormat: internal.DeliveryFormatCode128,
+		}
+		svc := mocks.NewMockService(t)
+		svc.On("UnitTicket", mock.Anything, 123, 1, user.ID).Return(ticket, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Get(srv.URL + "/bookings/123/units/1/ticket.png")
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusUnprocessableEntity, golden.ReadBytes(t, "ticket-too-long.json"))
+	})
+
+	t.Run("ticket not found", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("UnitTicket", mock.Anything, 123, 1, user.ID).Return(internal.UnitTicket{}, internal.ErrNotFound)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Get(srv.URL + "/bookings/123/units/1/ticket.png")
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusNotFound, golden.ReadBytes(t, "ticket-not-found.json"))
+	})
+}
+
+func TestAPIRedeemTicket(t *testing.T) {
+	redeemedAt := platform.Must(time.Parse(time.RFC3339, "2025-01-20T10:00:00Z"))
+
+	t.Run("redeem ticket", func(t *testing.T) {
+		redemption := internal.Redemption{
+			BookingID:      "123",
+			ProductID:      "1",
+			AvailabilityID: "123",
+			Unit: &internal.UnitBase{
+				ID:            "1",
+				UnitID:        platform.ToPtr("adult"),
+				Ticket:        platform.ToPtr("ticket 1"),
+				UTCRedeemedAt: &redeemedAt,
+			},
+		}
+		redemption.Units = []internal.UnitBase{*redemption.Unit}
+		svc := mocks.NewMockService(t)
+		svc.On("RedeemTicket", mock.Anything, "ticket 1", user.ID).Return(redemption, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/tickets/ticket%201/redeem", "application/json", nil)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "ticket-redeemed.json"))
+	})
+
+	t.Run("redeem voucher", func(t *testing.T) {
+		redemption := internal.Redemption{
+			BookingID:      "123",
+			ProductID:      "1",
+			AvailabilityID: "123",
+			Units: []internal.UnitBase{
+				{ID: "1", UnitID: platform.ToPtr("adult"), UTCRedeemedAt: &redeemedAt},
+				{ID: "2", UnitID: platform.ToPtr("child"), UTCRedeemedAt: &redeemedAt},
+			},
+		}
+		svc := mocks.NewMockService(t)
+		svc.On("RedeemTicket", mock.Anything, "voucher", user.ID).Return(redemption, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/tickets/voucher/redeem", "application/json", nil)
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "voucher-redeemed.json"))
+	})
+
+	tests := map[string]struct {
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		"ticket not found": {
+			err:        internal.ErrNotFound,
+			wantStatus: http.StatusNotFound,
+			wantBody:   "ticket-not-found.json",
+		},
+		"ticket already redeemed": {
+			err:        internal.TicketRedeemedError{UTCRedeemedAt: redeemedAt},
+			wantStatus: http.StatusConflict,
+			wantBody:   "ticket-already-redeemed.json",
+		},
+		"ticket for other date": {
+			err:        internal.ErrWrongDate,
+			wantStatus: http.StatusConflict,
+			wantBody:   "ticket-wrong-date.json",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := mocks.NewMockService(t)
+			svc.On("RedeemTicket", mock.Anything, "ABC", user.ID).Return(internal.Redemption{}, tt.err)
+			srv := newTestServer(t, svc)
+
+			client := srv.Client()
+			resp, err := client.Post(srv.URL+"/tickets/ABC/redeem", "application/json", nil)
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			assertEqualResponse(t, resp, tt.wantStatus, golden.ReadBytes(t, tt.wantBody))
+		})
+	}
+}
+
+func TestAPIWaitlist(t *testing.T) {
+	entry := internal.WaitlistEntry{
+		ID:             "789",
+		Status:         internal.WaitlistStatusWaiting,
+		OptionID:       internal.DefaultOptionID,
+		AvailabilityID: "123",
+		Units:          2,
+		Currency:       "EUR",
+		WebhookURL:     platform.ToPtr("https://reseller.example.com/waitlist"),
+		UTCCreatedAt:   platform.Must(time.Parse(time.RFC3339, "2025-01-20T10:00:00Z")),
+	}
+
+	t.Run("join waitlist", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		wantReq := internal.JoinWaitlistRequest{
+			AvailabilityID: 123,
+			OptionID:       internal.DefaultOptionID,
+			UserID:         user.ID,
+			Units:          2,
+			UnitIDs:        []string{"adult", "child"},
+			WebhookURL:     "https://reseller.example.com/waitlist",
+			Currency:       "EUR",
+		}
+		svc.On("JoinWaitlist", mock.Anything, wantReq).Return(789, nil)
+		svc.On("WaitlistEntry", mock.Anything, 789, user.ID).Return(entry, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/availability/123/waitlist", "application/json", golden.Open(t, "waitlist-request.json"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "waitlist-entry.json"))
+	})
+
+	t.Run("join waitlist of unknown availability", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("JoinWaitlist", mock.Anything, mock.Anything).Return(0, internal.ErrNotFound)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/availability/123/waitlist", "application/json", strings.NewReader(`{"units": 1}`))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusNotFound, golden.ReadBytes(t, "waitlist-availability-not-found.json"))
+	})
+
+	t.Run("join waitlist with invalid webhook", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/availability/123/waitlist", "application/json", strings.NewReader(`{"units": 1, "webhookUrl": "/relative"}`))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "waitlist-invalid-webhook.json"))
+	})
+
+	t.Run("join waitlist with non-public webhook", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("JoinWaitlist", mock.Anything, mock.Anything).Return(0, internal.ErrWebhookNotAllowed)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/availability/123/waitlist", "application/json", strings.NewReader(`{"units": 1, "webhookUrl": "http://169.254.169.254/"}`))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "waitlist-webhook-not-allowed.json"))
+	})
+
+	t.Run("join waitlist with unknown unit", func(t *testing.T) {
+		svc := mocks.NewMockService(t)
+		svc.On("JoinWaitlist", mock.Anything, mock.Anything).Return(0, internal.ErrUnknownUnit)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Post(srv.URL+"/availability/123/waitlist", "application/json", golden.Open(t, "waitlist-request.json"))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusBadRequest, golden.ReadBytes(t, "booking-unknown-unit.json"))
+	})
+
+	t.Run("poll reserved entry", func(t *testing.T) {
+		reserved := entry
+		reserved.Status = internal.WaitlistStatusReserved
+		reserved.BookingID = platform.ToPtr("321")
+		reserved.WebhookURL = nil
+		svc := mocks.NewMockService(t)
+		svc.On("WaitlistEntry", mock.Anything, 789, user.ID).Return(reserved, nil)
+		srv := newTestServer(t, svc)
+
+		client := srv.Client()
+		resp, err := client.Get(srv.URL + "/waitlist/789")
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		assertEqualResponse(t, resp, http.StatusOK, golden.ReadBytes(t, "waitlist-entry-reserved.json"))
+	})
+}
+
+// eurPrice returns a price with 20% reseller margin and 19% VAT included.
+func eurPrice(price int) internal.CapabilityPrice {
+	net := price * 4 / 5
+	return internal.CapabilityPrice{
+		Price:    price,
+		Currency: "EUR",
+		Pricing: internal.Pricing{
+			Original:          price,
+			Retail:            price,
+			Net:               &net,
+			Currency:          "EUR",
+			CurrencyPrecision: 2,
+			IncludedTaxes: internal.IncludedTaxes{
+				{Name: "VAT 19%", Retail: price * 19 / 119, Net: platform.ToPtr(net * 19 / 119)},
+			},
+		},
+	}
+}
+
+func newTestServer(t *testing.T, svc api.Service) *httptest.Server {
+	t.Helper()
+
+	router := api.NewRouter(api.NewAPI(svc), nil)
+	srv := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := auth.ContextWithUser(r.Context(), user)
+			router.ServeHTTP(w, r.WithContext(ctx))
+		}),
+	)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func assertEqualResponse(t *testing.T, resp *http.Response, wantStatus int, wantBody []byte) {
+	t.Helper()
+
+	if resp.StatusCode != wantStatus {
+		t.Errorf("want status %d, got %d", wantStatus, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+
+	assertEqualJSON(t, body, wantBody)
+}
+
+func assertEqualImage(t *testing.T, resp *http.Response, wantContentType string, wantBody []byte) {
+	t.Helper()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != wantContentType {
+		t.Errorf("want content type %s, got %s", wantContentType, contentType)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	if !bytes.Equal(body, wantBody) {
+		t.Errorf("response body differs from golden file")
+	}
 }
 
 func assertEqualJSON(t *testing.T, respBody, wantBody []byte) {
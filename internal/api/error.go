@@ -2,26 +2,13 @@ package api
 
 import (
 	"net/http"
+
+	"github.com/dmksnnk/octo/internal/platform/httpplatform"
 )
 
 // Error defines model for Error.
-type Error struct {
-	// Code is a status code.
-	Code int `json:"code"`
-
-	// Message is a developer-facing error message.
-	Message string `json:"message"`
-
-	// Details is the error details.
-	Details []string `json:"details"`
-}
+type Error = httpplatform.Error
 
 func writeError(w http.ResponseWriter, message string, code int, details ...string) {
-	apiError := Error{
-		Code:    code,
-		Message: message,
-		Details: details,
-	}
-
-	_ = writeJSON(w, code, apiError)
+	httpplatform.WriteError(w, message, code, details...)
 }
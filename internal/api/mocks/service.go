@@ -40,8 +40,8 @@ func (_m *MockService) EXPECT() *MockService_Expecter {
 }
 
 // Availabilities provides a mock function for the type MockService
-func (_mock *MockService) Availabilities(ctx context.Context, productID int, localDateStart time.Time, localDateEnd time.Time, capability internal.CapabilityRequest) ([]internal.Availability, error) {
-	ret := _mock.Called(ctx, productID, localDateStart, localDateEnd, capability)
+func (_mock *MockService) Availabilities(ctx context.Context, productID int, optionID string, localDateStart time.Time, localDateEnd time.Time, capability internal.CapabilityRequest, currency string) ([]internal.Availability, error) {
+	ret := _mock.Called(ctx, productID, optionID, localDateStart, localDateEnd, capability, currency)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Availabilities")
@@ -49,18 +49,18 @@ func (_mock *MockService) Availabilities(ctx context.Context, productID int, loc
 
 	var r0 []internal.Availability
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, time.Time, time.Time, internal.CapabilityRequest) ([]internal.Availability, error)); ok {
-		return returnFunc(ctx, productID, localDateStart, localDateEnd, capability)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, string, time.Time, time.Time, internal.CapabilityRequest, string) ([]internal.Availability, error)); ok {
+		return returnFunc(ctx, productID, optionID, localDateStart, localDateEnd, capability, currency)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, time.Time, time.Time, internal.CapabilityRequest) []internal.Availability); ok {
-		r0 = returnFunc(ctx, productID, localDateStart, localDateEnd, capability)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, string, time.Time, time.Time, internal.CapabilityRequest, string) []internal.Availability); ok {
+		r0 = returnFunc(ctx, productID, optionID, localDateStart, localDateEnd, capability, currency)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]internal.Availability)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, int, time.Time, time.Time, internal.CapabilityRequest) error); ok {
-		r1 = returnFunc(ctx, productID, localDateStart, localDateEnd, capability)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, string, time.Time, time.Time, internal.CapabilityRequest, string) error); ok {
+		r1 = returnFunc(ctx, productID, optionID, localDateStart, localDateEnd, capability, currency)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -75,16 +75,18 @@ type MockService_Availabilities_Call struct {
 // Availabilities is a helper method to define mock.On call
 //   - ctx
 //   - productID
+//   - optionID
 //   - localDateStart
 //   - localDateEnd
 //   - capability
-func (_e *MockService_Expecter) Availabilities(ctx interface{}, productID interface{}, localDateStart interface{}, localDateEnd interface{}, capability interface{}) *MockService_Availabilities_Call {
-	return &MockService_Availabilities_Call{Call: _e.mock.On("Availabilities", ctx, productID, localDateStart, localDateEnd, capability)}
+//   - currency
+func (_e *MockService_Expecter) Availabilities(ctx interface{}, productID interface{}, optionID interface{}, localDateStart interface{}, localDateEnd interface{}, capability interface{}, currency interface{}) *MockService_Availabilities_Call {
+	return &MockService_Availabilities_Call{Call: _e.mock.On("Availabilities", ctx, productID, optionID, localDateStart, localDateEnd, capability, currency)}
 }
 
-func (_c *MockService_Availabilities_Call) Run(run func(ctx context.Context, productID int, localDateStart time.Time, localDateEnd time.Time, capability internal.CapabilityRequest)) *MockService_Availabilities_Call {
+func (_c *MockService_Availabilities_Call) Run(run func(ctx context.Context, productID int, optionID string, localDateStart time.Time, localDateEnd time.Time, capability internal.CapabilityRequest, currency string)) *MockService_Availabilities_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(time.Time), args[3].(time.Time), args[4].(internal.CapabilityRequest))
+		run(args[0].(context.Context), args[1].(int), args[2].(string), args[3].(time.Time), args[4].(time.Time), args[5].(internal.CapabilityRequest), args[6].(string))
 	})
 	return _c
 }
@@ -94,66 +96,68 @@ func (_c *MockService_Availabilities_Call) Return(availabilitys []internal.Avail
 	return _c
 }
 
-func (_c *MockService_Availabilities_Call) RunAndReturn(run func(ctx context.Context, productID int, localDateStart time.Time, localDateEnd time.Time, capability internal.CapabilityRequest) ([]internal.Availability, error)) *MockService_Availabilities_Call {
+func (_c *MockService_Availabilities_Call) RunAndReturn(run func(ctx context.Context, productID int, optionID string, localDateStart time.Time, localDateEnd time.Time, capability internal.CapabilityRequest, currency string) ([]internal.Availability, error)) *MockService_Availabilities_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Availability provides a mock function for the type MockService
-func (_mock *MockService) Availability(ctx context.Context, productID int, localDate time.Time, capability internal.CapabilityRequest) (internal.Availability, error) {
-	ret := _mock.Called(ctx, productID, localDate, capability)
+// AvailabilityCalendar provides a mock function for the type MockService
+func (_mock *MockService) AvailabilityCalendar(ctx context.Context, productID int, localDateStart time.Time, localDateEnd time.Time, capability internal.CapabilityRequest, currency string) ([]internal.AvailabilityCalendar, error) {
+	ret := _mock.Called(ctx, productID, localDateStart, localDateEnd, capability, currency)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Availability")
+		panic("no return value specified for AvailabilityCalendar")
 	}
 
-	var r0 internal.Availability
+	var r0 []internal.AvailabilityCalendar
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, time.Time, internal.CapabilityRequest) (internal.Availability, error)); ok {
-		return returnFunc(ctx, productID, localDate, capability)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, time.Time, time.Time, internal.CapabilityRequest, string) ([]internal.AvailabilityCalendar, error)); ok {
+		return returnFunc(ctx, productID, localDateStart, localDateEnd, capability, currency)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, time.Time, internal.CapabilityRequest) internal.Availability); ok {
-		r0 = returnFunc(ctx, productID, localDate, capability)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, time.Time, time.Time, internal.CapabilityRequest, string) []internal.AvailabilityCalendar); ok {
+		r0 = returnFunc(ctx, productID, localDateStart, localDateEnd, capability, currency)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(internal.Availability)
+			r0 = ret.Get(0).([]internal.AvailabilityCalendar)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, int, time.Time, internal.CapabilityRequest) error); ok {
-		r1 = returnFunc(ctx, productID, localDate, capability)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, time.Time, time.Time, internal.CapabilityRequest, string) error); ok {
+		r1 = returnFunc(ctx, productID, localDateStart, localDateEnd, capability, currency)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockService_Availability_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Availability'
-type MockService_Availability_Call struct {
+// MockService_AvailabilityCalendar_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AvailabilityCalendar'
+type MockService_AvailabilityCalendar_Call struct {
 	*mock.Call
 }
 
-// Availability is a helper method to define mock.On call
+// AvailabilityCalendar is a helper method to define mock.On call
 //   - ctx
 //   - productID
-//   - localDate
+//   - localDateStart
+//   - localDateEnd
 //   - capability
-func (_e *MockService_Expecter) Availability(ctx interface{}, productID interface{}, localDate interface{}, capability interface{}) *MockService_Availability_Call {
-	return &MockService_Availability_Call{Call: _e.mock.On("Availability", ctx, productID, localDate, capability)}
+//   - currency
+func (_e *MockService_Expecter) AvailabilityCalendar(ctx interface{}, productID interface{}, localDateStart interface{}, localDateEnd interface{}, capability interface{}, currency interface{}) *MockService_AvailabilityCalendar_Call {
+	return &MockService_AvailabilityCalendar_Call{Call: _e.mock.On("AvailabilityCalendar", ctx, productID, localDateStart, localDateEnd, capability, currency)}
 }
 
-func (_c *MockService_Availability_Call) Run(run func(ctx context.Context, productID int, localDate time.Time, capability internal.CapabilityRequest)) *MockService_Availability_Call {
+func (_c *MockService_AvailabilityCalendar_Call) Run(run func(ctx context.Context, productID int, localDateStart time.Time, localDateEnd time.Time, capability internal.CapabilityRequest, currency string)) *MockService_AvailabilityCalendar_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(time.Time), args[3].(internal.CapabilityRequest))
+		run(args[0].(context.Context), args[1].(int), args[2].(time.Time), args[3].(time.Time), args[4].(internal.CapabilityRequest), args[5].(string))
 	})
 	return _c
 }
 
-func (_c *MockService_Availability_Call) Return(availability internal.Availability, err error) *MockService_Availability_Call {
-	_c.Call.Return(availability, err)
+func (_c *MockService_AvailabilityCalendar_Call) Return(availabilityCalendars []internal.AvailabilityCalendar, err error) *MockService_AvailabilityCalendar_Call {
+	_c.Call.Return(availabilityCalendars, err)
 	return _c
 }
 
-func (_c *MockService_Availability_Call) RunAndReturn(run func(ctx context.Context, productID int, localDate time.Time, capability internal.CapabilityRequest) (internal.Availability, error)) *MockService_Availability_Call {
+func (_c *MockService_AvailabilityCalendar_Call) RunAndReturn(run func(ctx context.Context, productID int, localDateStart time.Time, localDateEnd time.Time, capability internal.CapabilityRequest, currency string) ([]internal.AvailabilityCalendar, error)) *MockService_AvailabilityCalendar_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -217,17 +221,121 @@ func (_c *MockService_Booking_Call) RunAndReturn(run func(ctx context.Context, i
 	return _c
 }
 
+// Bookings provides a mock function for the type MockService
+func (_mock *MockService) Bookings(ctx context.Context, filter internal.BookingsFilter, capability internal.CapabilityRequest) (internal.BookingsPage, error) {
+	ret := _mock.Called(ctx, filter, capability)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Bookings")
+	}
+
+	var r0 internal.BookingsPage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, internal.BookingsFilter, internal.CapabilityRequest) (internal.BookingsPage, error)); ok {
+		return returnFunc(ctx, filter, capability)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, internal.BookingsFilter, internal.CapabilityRequest) internal.BookingsPage); ok {
+		r0 = returnFunc(ctx, filter, capability)
+	} else {
+		r0 = ret.Get(0).(internal.BookingsPage)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, internal.BookingsFilter, internal.CapabilityRequest) error); ok {
+		r1 = returnFunc(ctx, filter, capability)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockService_Bookings_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Bookings'
+type MockService_Bookings_Call struct {
+	*mock.Call
+}
+
+// Bookings is a helper method to define mock.On call
+//   - ctx
+//   - filter
+//   - capability
+func (_e *MockService_Expecter) Bookings(ctx interface{}, filter interface{}, capability interface{}) *MockService_Bookings_Call {
+	return &MockService_Bookings_Call{Call: _e.mock.On("Bookings", ctx, filter, capability)}
+}
+
+func (_c *MockService_Bookings_Call) Run(run func(ctx context.Context, filter internal.BookingsFilter, capability internal.CapabilityRequest)) *MockService_Bookings_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(internal.BookingsFilter), args[2].(internal.CapabilityRequest))
+	})
+	return _c
+}
+
+func (_c *MockService_Bookings_Call) Return(bookingsPage internal.BookingsPage, err error) *MockService_Bookings_Call {
+	_c.Call.Return(bookingsPage, err)
+	return _c
+}
+
+func (_c *MockService_Bookings_Call) RunAndReturn(run func(ctx context.Context, filter internal.BookingsFilter, capability internal.CapabilityRequest) (internal.BookingsPage, error)) *MockService_Bookings_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CancelBooking provides a mock function for the type MockService
+func (_mock *MockService) CancelBooking(ctx context.Context, id int, userID int, reason string) error {
+	ret := _mock.Called(ctx, id, userID, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CancelBooking")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, string) error); ok {
+		r0 = returnFunc(ctx, id, userID, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockService_CancelBooking_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CancelBooking'
+type MockService_CancelBooking_Call struct {
+	*mock.Call
+}
+
+// CancelBooking is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - userID
+//   - reason
+func (_e *MockService_Expecter) CancelBooking(ctx interface{}, id interface{}, userID interface{}, reason interface{}) *MockService_CancelBooking_Call {
+	return &MockService_CancelBooking_Call{Call: _e.mock.On("CancelBooking", ctx, id, userID, reason)}
+}
+
+func (_c *MockService_CancelBooking_Call) Run(run func(ctx context.Context, id int, userID int, reason string)) *MockService_CancelBooking_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockService_CancelBooking_Call) Return(err error) *MockService_CancelBooking_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockService_CancelBooking_Call) RunAndReturn(run func(ctx context.Context, id int, userID int, reason string) error) *MockService_CancelBooking_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ConfirmBooking provides a mock function for the type MockService
-func (_mock *MockService) ConfirmBooking(ctx context.Context, id int, userID int) error {
-	ret := _mock.Called(ctx, id, userID)
+func (_mock *MockService) ConfirmBooking(ctx context.Context, req internal.ConfirmBookingRequest) error {
+	ret := _mock.Called(ctx, req)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ConfirmBooking")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
-		r0 = returnFunc(ctx, id, userID)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, internal.ConfirmBookingRequest) error); ok {
+		r0 = returnFunc(ctx, req)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -241,15 +349,14 @@ type MockService_ConfirmBooking_Call struct {
 
 // ConfirmBooking is a helper method to define mock.On call
 //   - ctx
-//   - id
-//   - userID
-func (_e *MockService_Expecter) ConfirmBooking(ctx interface{}, id interface{}, userID interface{}) *MockService_ConfirmBooking_Call {
-	return &MockService_ConfirmBooking_Call{Call: _e.mock.On("ConfirmBooking", ctx, id, userID)}
+//   - req
+func (_e *MockService_Expecter) ConfirmBooking(ctx interface{}, req interface{}) *MockService_ConfirmBooking_Call {
+	return &MockService_ConfirmBooking_Call{Call: _e.mock.On("ConfirmBooking", ctx, req)}
 }
 
-func (_c *MockService_ConfirmBooking_Call) Run(run func(ctx context.Context, id int, userID int)) *MockService_ConfirmBooking_Call {
+func (_c *MockService_ConfirmBooking_Call) Run(run func(ctx context.Context, req internal.ConfirmBookingRequest)) *MockService_ConfirmBooking_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(int))
+		run(args[0].(context.Context), args[1].(internal.ConfirmBookingRequest))
 	})
 	return _c
 }
@@ -259,7 +366,7 @@ func (_c *MockService_ConfirmBooking_Call) Return(err error) *MockService_Confir
 	return _c
 }
 
-func (_c *MockService_ConfirmBooking_Call) RunAndReturn(run func(ctx context.Context, id int, userID int) error) *MockService_ConfirmBooking_Call {
+func (_c *MockService_ConfirmBooking_Call) RunAndReturn(run func(ctx context.Context, req internal.ConfirmBookingRequest) error) *MockService_ConfirmBooking_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -319,9 +426,112 @@ func (_c *MockService_CreateBooking_Call) RunAndReturn(run func(ctx context.Cont
 	return _c
 }
 
+// ExtendBooking provides a mock function for the type MockService
+func (_mock *MockService) ExtendBooking(ctx context.Context, id int, userID int, hold time.Duration) error {
+	ret := _mock.Called(ctx, id, userID, hold)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExtendBooking")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, time.Duration) error); ok {
+		r0 = returnFunc(ctx, id, userID, hold)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockService_ExtendBooking_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExtendBooking'
+type MockService_ExtendBooking_Call struct {
+	*mock.Call
+}
+
+// ExtendBooking is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - userID
+//   - hold
+func (_e *MockService_Expecter) ExtendBooking(ctx interface{}, id interface{}, userID interface{}, hold interface{}) *MockService_ExtendBooking_Call {
+	return &MockService_ExtendBooking_Call{Call: _e.mock.On("ExtendBooking", ctx, id, userID, hold)}
+}
+
+func (_c *MockService_ExtendBooking_Call) Run(run func(ctx context.Context, id int, userID int, hold time.Duration)) *MockService_ExtendBooking_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockService_ExtendBooking_Call) Return(err error) *MockService_ExtendBooking_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockService_ExtendBooking_Call) RunAndReturn(run func(ctx context.Context, id int, userID int, hold time.Duration) error) *MockService_ExtendBooking_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// JoinWaitlist provides a mock function for the type MockService
+func (_mock *MockService) JoinWaitlist(ctx context.Context, req internal.JoinWaitlistRequest) (int, error) {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for JoinWaitlist")
+	}
+
+	var r0 int
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, internal.JoinWaitlistRequest) (int, error)); ok {
+		return returnFunc(ctx, req)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, internal.JoinWaitlistRequest) int); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, internal.JoinWaitlistRequest) error); ok {
+		r1 = returnFunc(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockService_JoinWaitlist_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'JoinWaitlist'
+type MockService_JoinWaitlist_Call struct {
+	*mock.Call
+}
+
+// JoinWaitlist is a helper method to define mock.On call
+//   - ctx
+//   - req
+func (_e *MockService_Expecter) JoinWaitlist(ctx interface{}, req interface{}) *MockService_JoinWaitlist_Call {
+	return &MockService_JoinWaitlist_Call{Call: _e.mock.On("JoinWaitlist", ctx, req)}
+}
+
+func (_c *MockService_JoinWaitlist_Call) Run(run func(ctx context.Context, req internal.JoinWaitlistRequest)) *MockService_JoinWaitlist_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(internal.JoinWaitlistRequest))
+	})
+	return _c
+}
+
+func (_c *MockService_JoinWaitlist_Call) Return(n int, err error) *MockService_JoinWaitlist_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockService_JoinWaitlist_Call) RunAndReturn(run func(ctx context.Context, req internal.JoinWaitlistRequest) (int, error)) *MockService_JoinWaitlist_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Product provides a mock function for the type MockService
-func (_mock *MockService) Product(ctx context.Context, id int, capability internal.CapabilityRequest) (internal.Product, error) {
-	ret := _mock.Called(ctx, id, capability)
+func (_mock *MockService) Product(ctx context.Context, id int, capability internal.CapabilityRequest, currency string) (internal.Product, error) {
+	ret := _mock.Called(ctx, id, capability, currency)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Product")
@@ -329,18 +539,18 @@ func (_mock *MockService) Product(ctx context.Context, id int, capability intern
 
 	var r0 internal.Product
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, internal.CapabilityRequest) (internal.Product, error)); ok {
-		return returnFunc(ctx, id, capability)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, internal.CapabilityRequest, string) (internal.Product, error)); ok {
+		return returnFunc(ctx, id, capability, currency)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int, internal.CapabilityRequest) internal.Product); ok {
-		r0 = returnFunc(ctx, id, capability)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, internal.CapabilityRequest, string) internal.Product); ok {
+		r0 = returnFunc(ctx, id, capability, currency)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(internal.Product)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, int, internal.CapabilityRequest) error); ok {
-		r1 = returnFunc(ctx, id, capability)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, internal.CapabilityRequest, string) error); ok {
+		r1 = returnFunc(ctx, id, capability, currency)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -356,13 +566,14 @@ type MockService_Product_Call struct {
 //   - ctx
 //   - id
 //   - capability
-func (_e *MockService_Expecter) Product(ctx interface{}, id interface{}, capability interface{}) *MockService_Product_Call {
-	return &MockService_Product_Call{Call: _e.mock.On("Product", ctx, id, capability)}
+//   - currency
+func (_e *MockService_Expecter) Product(ctx interface{}, id interface{}, capability interface{}, currency interface{}) *MockService_Product_Call {
+	return &MockService_Product_Call{Call: _e.mock.On("Product", ctx, id, capability, currency)}
 }
 
-func (_c *MockService_Product_Call) Run(run func(ctx context.Context, id int, capability internal.CapabilityRequest)) *MockService_Product_Call {
+func (_c *MockService_Product_Call) Run(run func(ctx context.Context, id int, capability internal.CapabilityRequest, currency string)) *MockService_Product_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int), args[2].(internal.CapabilityRequest))
+		run(args[0].(context.Context), args[1].(int), args[2].(internal.CapabilityRequest), args[3].(string))
 	})
 	return _c
 }
@@ -372,14 +583,14 @@ func (_c *MockService_Product_Call) Return(product internal.Product, err error)
 	return _c
 }
 
-func (_c *MockService_Product_Call) RunAndReturn(run func(ctx context.Context, id int, capability internal.CapabilityRequest) (internal.Product, error)) *MockService_Product_Call {
+func (_c *MockService_Product_Call) RunAndReturn(run func(ctx context.Context, id int, capability internal.CapabilityRequest, currency string) (internal.Product, error)) *MockService_Product_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // Products provides a mock function for the type MockService
-func (_mock *MockService) Products(ctx context.Context, capability internal.CapabilityRequest) ([]internal.Product, error) {
-	ret := _mock.Called(ctx, capability)
+func (_mock *MockService) Products(ctx context.Context, capability internal.CapabilityRequest, currency string) ([]internal.Product, error) {
+	ret := _mock.Called(ctx, capability, currency)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Products")
@@ -387,18 +598,18 @@ func (_mock *MockService) Products(ctx context.Context, capability internal.Capa
 
 	var r0 []internal.Product
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, internal.CapabilityRequest) ([]internal.Product, error)); ok {
-		return returnFunc(ctx, capability)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, internal.CapabilityRequest, string) ([]internal.Product, error)); ok {
+		return returnFunc(ctx, capability, currency)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, internal.CapabilityRequest) []internal.Product); ok {
-		r0 = returnFunc(ctx, capability)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, internal.CapabilityRequest, string) []internal.Product); ok {
+		r0 = returnFunc(ctx, capability, currency)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]internal.Product)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, internal.CapabilityRequest) error); ok {
-		r1 = returnFunc(ctx, capability)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, internal.CapabilityRequest, string) error); ok {
+		r1 = returnFunc(ctx, capability, currency)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -413,13 +624,14 @@ type MockService_Products_Call struct {
 // Products is a helper method to define mock.On call
 //   - ctx
 //   - capability
-func (_e *MockService_Expecter) Products(ctx interface{}, capability interface{}) *MockService_Products_Call {
-	return &MockService_Products_Call{Call: _e.mock.On("Products", ctx, capability)}
+//   - currency
+func (_e *MockService_Expecter) Products(ctx interface{}, capability interface{}, currency interface{}) *MockService_Products_Call {
+	return &MockService_Products_Call{Call: _e.mock.On("Products", ctx, capability, currency)}
 }
 
-func (_c *MockService_Products_Call) Run(run func(ctx context.Context, capability internal.CapabilityRequest)) *MockService_Products_Call {
+func (_c *MockService_Products_Call) Run(run func(ctx context.Context, capability internal.CapabilityRequest, currency string)) *MockService_Products_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(internal.CapabilityRequest))
+		run(args[0].(context.Context), args[1].(internal.CapabilityRequest), args[2].(string))
 	})
 	return _c
 }
@@ -429,7 +641,222 @@ func (_c *MockService_Products_Call) Return(products []internal.Product, err err
 	return _c
 }
 
-func (_c *MockService_Products_Call) RunAndReturn(run func(ctx context.Context, capability internal.CapabilityRequest) ([]internal.Product, error)) *MockService_Products_Call {
+func (_c *MockService_Products_Call) RunAndReturn(run func(ctx context.Context, capability internal.CapabilityRequest, currency string) ([]internal.Product, error)) *MockService_Products_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RedeemTicket provides a mock function for the type MockService
+func (_mock *MockService) RedeemTicket(ctx context.Context, ticket string, userID int) (internal.Redemption, error) {
+	ret := _mock.Called(ctx, ticket, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RedeemTicket")
+	}
+
+	var r0 internal.Redemption
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int) (internal.Redemption, error)); ok {
+		return returnFunc(ctx, ticket, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int) internal.Redemption); ok {
+		r0 = returnFunc(ctx, ticket, userID)
+	} else {
+		r0 = ret.Get(0).(internal.Redemption)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = returnFunc(ctx, ticket, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockService_RedeemTicket_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RedeemTicket'
+type MockService_RedeemTicket_Call struct {
+	*mock.Call
+}
+
+// RedeemTicket is a helper method to define mock.On call
+//   - ctx
+//   - ticket
+//   - userID
+func (_e *MockService_Expecter) RedeemTicket(ctx interface{}, ticket interface{}, userID interface{}) *MockService_RedeemTicket_Call {
+	return &MockService_RedeemTicket_Call{Call: _e.mock.On("RedeemTicket", ctx, ticket, userID)}
+}
+
+func (_c *MockService_RedeemTicket_Call) Run(run func(ctx context.Context, ticket string, userID int)) *MockService_RedeemTicket_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockService_RedeemTicket_Call) Return(redemption internal.Redemption, err error) *MockService_RedeemTicket_Call {
+	_c.Call.Return(redemption, err)
+	return _c
+}
+
+func (_c *MockService_RedeemTicket_Call) RunAndReturn(run func(ctx context.Context, ticket string, userID int) (internal.Redemption, error)) *MockService_RedeemTicket_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnitTicket provides a mock function for the type MockService
+func (_mock *MockService) UnitTicket(ctx context.Context, bookingID int, unitID int, userID int) (internal.UnitTicket, error) {
+	ret := _mock.Called(ctx, bookingID, unitID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnitTicket")
+	}
+
+	var r0 internal.UnitTicket
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, int) (internal.UnitTicket, error)); ok {
+		return returnFunc(ctx, bookingID, unitID, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, int) internal.UnitTicket); ok {
+		r0 = returnFunc(ctx, bookingID, unitID, userID)
+	} else {
+		r0 = ret.Get(0).(internal.UnitTicket)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, int, int) error); ok {
+		r1 = returnFunc(ctx, bookingID, unitID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockService_UnitTicket_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnitTicket'
+type MockService_UnitTicket_Call struct {
+	*mock.Call
+}
+
+// UnitTicket is a helper method to define mock.On call
+//   - ctx
+//   - bookingID
+//   - unitID
+//   - userID
+func (_e *MockService_Expecter) UnitTicket(ctx interface{}, bookingID interface{}, unitID interface{}, userID interface{}) *MockService_UnitTicket_Call {
+	return &MockService_UnitTicket_Call{Call: _e.mock.On("UnitTicket", ctx, bookingID, unitID, userID)}
+}
+
+func (_c *MockService_UnitTicket_Call) Run(run func(ctx context.Context, bookingID int, unitID int, userID int)) *MockService_UnitTicket_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockService_UnitTicket_Call) Return(unitTicket internal.UnitTicket, err error) *MockService_UnitTicket_Call {
+	_c.Call.Return(unitTicket, err)
+	return _c
+}
+
+func (_c *MockService_UnitTicket_Call) RunAndReturn(run func(ctx context.Context, bookingID int, unitID int, userID int) (internal.UnitTicket, error)) *MockService_UnitTicket_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateBooking provides a mock function for the type MockService
+func (_mock *MockService) UpdateBooking(ctx context.Context, params internal.UpdateBookingRequest) error {
+	ret := _mock.Called(ctx, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateBooking")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, internal.UpdateBookingRequest) error); ok {
+		r0 = returnFunc(ctx, params)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockService_UpdateBooking_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateBooking'
+type MockService_UpdateBooking_Call struct {
+	*mock.Call
+}
+
+// UpdateBooking is a helper method to define mock.On call
+//   - ctx
+//   - params
+func (_e *MockService_Expecter) UpdateBooking(ctx interface{}, params interface{}) *MockService_UpdateBooking_Call {
+	return &MockService_UpdateBooking_Call{Call: _e.mock.On("UpdateBooking", ctx, params)}
+}
+
+func (_c *MockService_UpdateBooking_Call) Run(run func(ctx context.Context, params internal.UpdateBookingRequest)) *MockService_UpdateBooking_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(internal.UpdateBookingRequest))
+	})
+	return _c
+}
+
+func (_c *MockService_UpdateBooking_Call) Return(err error) *MockService_UpdateBooking_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockService_UpdateBooking_Call) RunAndReturn(run func(ctx context.Context, params internal.UpdateBookingRequest) error) *MockService_UpdateBooking_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WaitlistEntry provides a mock function for the type MockService
+func (_mock *MockService) WaitlistEntry(ctx context.Context, id int, userID int) (internal.WaitlistEntry, error) {
+	ret := _mock.Called(ctx, id, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WaitlistEntry")
+	}
+
+	var r0 internal.WaitlistEntry
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) (internal.WaitlistEntry, error)); ok {
+		return returnFunc(ctx, id, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) internal.WaitlistEntry); ok {
+		r0 = returnFunc(ctx, id, userID)
+	} else {
+		r0 = ret.Get(0).(internal.WaitlistEntry)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = returnFunc(ctx, id, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockService_WaitlistEntry_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WaitlistEntry'
+type MockService_WaitlistEntry_Call struct {
+	*mock.Call
+}
+
+// WaitlistEntry is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - userID
+func (_e *MockService_Expecter) WaitlistEntry(ctx interface{}, id interface{}, userID interface{}) *MockService_WaitlistEntry_Call {
+	return &MockService_WaitlistEntry_Call{Call: _e.mock.On("WaitlistEntry", ctx, id, userID)}
+}
+
+func (_c *MockService_WaitlistEntry_Call) Run(run func(ctx context.Context, id int, userID int)) *MockService_WaitlistEntry_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockService_WaitlistEntry_Call) Return(waitlistEntry internal.WaitlistEntry, err error) *MockService_WaitlistEntry_Call {
+	_c.Call.Return(waitlistEntry, err)
+	return _c
+}
+
+func (_c *MockService_WaitlistEntry_Call) RunAndReturn(run func(ctx context.Context, id int, userID int) (internal.WaitlistEntry, error)) *MockService_WaitlistEntry_Call {
 	_c.Call.Return(run)
 	return _c
 }
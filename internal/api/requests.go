@@ -1,9 +1,20 @@
 package api
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/dmksnnk/octo/internal"
 )
@@ -11,13 +22,61 @@ import (
 // AvailabilityRequest represents a request to check product availability.
 type AvailabilityRequest struct {
 	ProductID      IntString     `json:"productId"`
+	OptionID       string        `json:"optionId"` // defaults to internal.DefaultOptionID
 	LocalDate      internal.Date `json:"localDate"`
 	LocalDateStart internal.Date `json:"localDateStart"`
 	LocalDateEnd   internal.Date `json:"localDateEnd"`
 }
 
 func (a *AvailabilityRequest) UnmarshalHTTP(r *http.Request) error {
-	return json.NewDecoder(r.Body).Decode(&a)
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		return err
+	}
+
+	if a.OptionID == "" {
+		a.OptionID = internal.DefaultOptionID
+	}
+
+	return nil
+}
+
+// DateRange returns the local dates to get availabilities for.
+// A single local date covers all availability slots starting on that date.
+func (a AvailabilityRequest) DateRange() (time.Time, time.Time) {
+	if !time.Time(a.LocalDate).IsZero() {
+		return time.Time(a.LocalDate), time.Time(a.LocalDate)
+	}
+
+	return time.Time(a.LocalDateStart), time.Time(a.LocalDateEnd)
+}
+
+// maxCalendarDays limits the date range of an availability calendar request.
+const maxCalendarDays = 366
+
+// AvailabilityCalendarRequest represents a request to get product availability per day.
+type AvailabilityCalendarRequest struct {
+	ProductID      IntString     `json:"productId"`
+	LocalDateStart internal.Date `json:"localDateStart"`
+	LocalDateEnd   internal.Date `json:"localDateEnd"`
+}
+
+func (a *AvailabilityCalendarRequest) UnmarshalHTTP(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		return err
+	}
+
+	start, end := time.Time(a.LocalDateStart), time.Time(a.LocalDateEnd)
+	if start.IsZero() || end.IsZero() {
+		return errors.New("localDateStart and localDateEnd are required")
+	}
+	if end.Before(start) {
+		return errors.New("localDateEnd must not be before localDateStart")
+	}
+	if end.After(start.AddDate(0, 0, maxCalendarDays-1)) {
+		return fmt.Errorf("date range must be at most %d days", maxCalendarDays)
+	}
+
+	return nil
 }
 
 // IntString is a custom type that marshals and unmarshals an integer as a string.
@@ -43,15 +102,384 @@ func (i IntString) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s)
 }
 
+// maxResellerReferenceLength matches the bookings.reseller_reference column.
+const maxResellerReferenceLength = 255
+
 // BookingRequest represents a request to create a booking.
 type BookingRequest struct {
-	ProductID      IntString `json:"productId"`
-	AvailabilityID IntString `json:"availabilityId"`
-	Units          int       `json:"units"`
+	ProductID         IntString         `json:"productId"`
+	OptionID          string            `json:"optionId"` // optional, availability must belong to it if set
+	AvailabilityID    IntString         `json:"availabilityId"`
+	Units             int               `json:"units"`
+	UnitItems         []UnitItem        `json:"unitItems"` // replaces units
+	UUID              uuid.UUID         `json:"uuid"`
+	ResellerReference string            `json:"resellerReference"`
+	Contact           *internal.Contact `json:"contact"`
+	Currency          CurrencyRequest   `json:"currency"`  // overrides the Octo-Currency header
+	PromoCode         string            `json:"promoCode"` // optional, case insensitive
 }
 
 func (b *BookingRequest) UnmarshalHTTP(r *http.Request) error {
-	return json.NewDecoder(r.Body).Decode(&b)
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		return err
+	}
+
+	if len(b.ResellerReference) > maxResellerReferenceLength {
+		return fmt.Errorf("resellerReference must be at most %d characters", maxResellerReferenceLength)
+	}
+
+	if len(b.UnitItems) > 0 {
+		if b.Units != 0 && b.Units != len(b.UnitItems) {
+			return errors.New("units must match the number of unitItems")
+		}
+		for _, item := range b.UnitItems {
+			if item.UnitID == "" {
+				return errors.New("unitItems.unitId is required")
+			}
+		}
+		b.Units = len(b.UnitItems)
+	}
+	if b.Units < 1 {
+		return errors.New("units or unitItems is required")
+	}
+
+	return validateContact(b.Contact)
+}
+
+// UnitIDs returns unit type of each requested unit.
+func (b BookingRequest) UnitIDs() []string {
+	var ids []string
+	for _, item := range b.UnitItems {
+		ids = append(ids, item.UnitID)
+	}
+
+	return ids
+}
+
+// UnitItem is a unit of a given type in a booking request.
+type UnitItem struct {
+	UnitID string `json:"unitId"` // unit type, e.g. "adult"
+}
+
+// ConfirmBookingRequest represents a request to confirm a booking.
+type ConfirmBookingRequest struct {
+	Contact *internal.Contact `json:"contact"`
+}
+
+func (c *ConfirmBookingRequest) UnmarshalHTTP(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil && !errors.Is(err, io.EOF) { // body is optional
+		return err
+	}
+
+	return validateContact(c.Contact)
+}
+
+// UpdateBookingRequest represents a request to change a reservation.
+// Omitted fields are not changed.
+type UpdateBookingRequest struct {
+	AvailabilityID IntString         `json:"availabilityId"`
+	Units          *int              `json:"units"`
+	UnitItems      []UnitItem        `json:"unitItems"` // replaces units
+	Contact        *internal.Contact `json:"contact"`
+}
+
+func (u *UpdateBookingRequest) UnmarshalHTTP(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		return err
+	}
+
+	if len(u.UnitItems) > 0 {
+		if u.Units != nil && *u.Units != len(u.UnitItems) {
+			return errors.New("units must match the number of unitItems")
+		}
+		for _, item := range u.UnitItems {
+			if item.UnitID == "" {
+				return errors.New("unitItems.unitId is required")
+			}
+		}
+		units := len(u.UnitItems)
+		u.Units = &units
+	}
+	if u.Units != nil && *u.Units < 1 {
+		return errors.New("units must be positive")
+	}
+
+	return validateContact(u.Contact)
+}
+
+// UnitIDs returns unit type of each requested unit.
+func (u UpdateBookingRequest) UnitIDs() []string {
+	var ids []string
+	for _, item := range u.UnitItems {
+		ids = append(ids, item.UnitID)
+	}
+
+	return ids
+}
+
+// limits match the contacts table
+const (
+	maxFullNameLength     = 255
+	maxEmailAddressLength = 255
+	maxNotesLength        = 1000
+)
+
+var (
+	phoneNumberRe = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)                // E.164
+	localeRe      = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`) // BCP 47
+	countryRe     = regexp.MustCompile(`^[A-Z]{2}$`)                          // ISO 3166-1 alpha-2
+	currencyRe    = regexp.MustCompile(`^[A-Z]{3}$`)                          // ISO 4217
+)
+
+// validateContact validates an optional contact.
+func validateContact(c *internal.Contact) error {
+	if c == nil {
+		return nil
+	}
+
+	if c.FullName != nil && (strings.TrimSpace(*c.FullName) == "" || len(*c.FullName) > maxFullNameLength) {
+		return fmt.Errorf("contact.fullName must be between 1 and %d characters", maxFullNameLength)
+	}
+	if c.EmailAddress != nil {
+		addr, err := mail.ParseAddress(*c.EmailAddress)
+		if err != nil || addr.Address != *c.EmailAddress || len(*c.EmailAddress) > maxEmailAddressLength {
+			return fmt.Errorf("contact.emailAddress %q is not a valid email address", *c.EmailAddress)
+		}
+	}
+	if c.PhoneNumber != nil && !phoneNumberRe.MatchString(*c.PhoneNumber) {
+		return fmt.Errorf("contact.phoneNumber %q must be in E.164 format, e.g. +4915112345678", *c.PhoneNumber)
+	}
+	for _, locale := range c.Locales {
+		if !localeRe.MatchString(locale) {
+			return fmt.Errorf("contact.locales %q is not a valid language tag", locale)
+		}
+	}
+	if c.Country != nil && !countryRe.MatchString(*c.Country) {
+		return fmt.Errorf("contact.country %q must be an ISO 3166-1 alpha-2 code", *c.Country)
+	}
+	if c.Notes != nil && len(*c.Notes) > maxNotesLength {
+		return fmt.Errorf("contact.notes must be at most %d characters", maxNotesLength)
+	}
+
+	return nil
+}
+
+// ExtendBookingRequest represents a request to extend a reservation hold.
+type ExtendBookingRequest struct {
+	// ExpirationMinutes is how long from now the reservation should be held.
+	// If omitted, the default reservation hold is used.
+	ExpirationMinutes int `json:"expirationMinutes"`
+}
+
+func (e *ExtendBookingRequest) UnmarshalHTTP(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil && !errors.Is(err, io.EOF) { // body is optional
+		return err
+	}
+
+	if e.ExpirationMinutes < 0 {
+		return errors.New("expirationMinutes must not be negative")
+	}
+
+	return nil
+}
+
+// CancelBookingRequest represents a request to cancel a booking.
+type CancelBookingRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (c *CancelBookingRequest) UnmarshalHTTP(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		return err
+	}
+
+	if c.Reason == "" {
+		return errors.New("reason is required")
+	}
+
+	return nil
+}
+
+// WaitlistRequest represents a request to join the waitlist of an availability.
+type WaitlistRequest struct {
+	OptionID  string     `json:"optionId"` // optional, availability must belong to it if set
+	Units     int        `json:"units"`
+	UnitItems []UnitItem `json:"unitItems"` // replaces units
+	// WebhookURL is notified with the entry once it is reserved. Optional, the entry can be polled instead.
+	WebhookURL string          `json:"webhookUrl"`
+	Currency   CurrencyRequest `json:"currency"` // overrides the Octo-Currency header
+}
+
+func (w *WaitlistRequest) UnmarshalHTTP(r *http.Request) error {
+	if err := json.NewDecoder(r.Body).Decode(&w); err != nil {
+		return err
+	}
+
+	if len(w.UnitItems) > 0 {
+		if w.Units != 0 && w.Units != len(w.UnitItems) {
+			return errors.New("units must match the number of unitItems")
+		}
+		for _, item := range w.UnitItems {
+			if item.UnitID == "" {
+				return errors.New("unitItems.unitId is required")
+			}
+		}
+		w.Units = len(w.UnitItems)
+	}
+	if w.Units <= 0 {
+		return errors.New("units or unitItems is required")
+	}
+
+	if w.WebhookURL != "" {
+		u, err := url.Parse(w.WebhookURL)
+		if err != nil {
+			return fmt.Errorf("invalid webhookUrl: %w", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("webhookUrl must be an absolute http or https URL")
+		}
+	}
+
+	return nil
+}
+
+// UnitIDs returns unit type of each requested unit.
+func (w WaitlistRequest) UnitIDs() []string {
+	var ids []string
+	for _, item := range w.UnitItems {
+		ids = append(ids, item.UnitID)
+	}
+
+	return ids
+}
+
+const (
+	defaultBookingsLimit = 50
+	maxBookingsLimit     = 100
+)
+
+// BookingsRequest represents query parameters for listing bookings.
+type BookingsRequest struct {
+	Status            internal.BookingStatus
+	ProductID         int
+	LocalDateStart    time.Time
+	LocalDateEnd      time.Time
+	CreatedAtStart    time.Time
+	CreatedAtEnd      time.Time
+	UUID              uuid.UUID
+	ResellerReference string
+	Cursor            Cursor
+	Limit             int
+}
+
+func (b *BookingsRequest) UnmarshalHTTP(r *http.Request) error {
+	query := r.URL.Query()
+
+	switch status := internal.BookingStatus(query.Get("status")); status {
+	case "",
+		internal.BookingStatusReserved,
+		internal.BookingStatusConfirmed,
+		internal.BookingStatusCancelled,
+		internal.BookingStatusExpired:
+		b.Status = status
+	default:
+		return fmt.Errorf("unknown status %q", status)
+	}
+
+	var err error
+	if b.ProductID, err = parseQuery(query, "productId", strconv.Atoi); err != nil {
+		return err
+	}
+	if b.LocalDateStart, err = parseQuery(query, "localDateStart", parseDate); err != nil {
+		return err
+	}
+	if b.LocalDateEnd, err = parseQuery(query, "localDateEnd", parseDate); err != nil {
+		return err
+	}
+	if b.CreatedAtStart, err = parseQuery(query, "utcCreatedAtStart", parseDateTime); err != nil {
+		return err
+	}
+	if b.CreatedAtEnd, err = parseQuery(query, "utcCreatedAtEnd", parseDateTime); err != nil {
+		return err
+	}
+	if b.UUID, err = parseQuery(query, "uuid", uuid.Parse); err != nil {
+		return err
+	}
+	b.ResellerReference = query.Get("resellerReference")
+	if err := b.Cursor.UnmarshalText([]byte(query.Get("cursor"))); err != nil {
+		return fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	if b.Limit, err = parseQuery(query, "limit", strconv.Atoi); err != nil {
+		return err
+	}
+	switch {
+	case b.Limit == 0:
+		b.Limit = defaultBookingsLimit
+	case b.Limit < 0 || b.Limit > maxBookingsLimit:
+		return fmt.Errorf("limit must be between 1 and %d", maxBookingsLimit)
+	}
+
+	return nil
+}
+
+// parseQuery parses an optional query parameter, returning zero value if it is not set.
+func parseQuery[T any](query url.Values, key string, parse func(string) (T, error)) (T, error) {
+	var zero T
+	value := query.Get(key)
+	if value == "" {
+		return zero, nil
+	}
+
+	v, err := parse(value)
+	if err != nil {
+		return zero, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return v, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	return time.Parse(time.DateOnly, s)
+}
+
+func parseDateTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339, s)
+}
+
+// Cursor is an opaque pagination cursor.
+type Cursor int
+
+func (c Cursor) MarshalText() ([]byte, error) {
+	s := base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(int(c))))
+	return []byte(s), nil
+}
+
+func (c *Cursor) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*c = 0
+		return nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(string(decoded))
+	if err != nil {
+		return err
+	}
+
+	*c = Cursor(id)
+	return nil
+}
+
+// BookingsResponse is a page of bookings.
+type BookingsResponse struct {
+	Bookings []internal.Booking `json:"bookings"`
+	// NextCursor is passed as cursor to get the next page, nil if there are no more bookings.
+	NextCursor *Cursor `json:"nextCursor"`
 }
 
 type CapabilityRequest internal.CapabilityRequest
@@ -75,6 +503,24 @@ func (c *CapabilityRequest) UnmarshalText(text []byte) error {
 	return nil
 }
 
+// CurrencyRequest is the currency prices are requested in,
+// empty for the product's default currency.
+type CurrencyRequest string
+
+func (c *CurrencyRequest) UnmarshalHTTP(r *http.Request) error {
+	return c.UnmarshalText([]byte(r.Header.Get("Octo-Currency")))
+}
+
+func (c *CurrencyRequest) UnmarshalText(text []byte) error {
+	currency := strings.ToUpper(strings.TrimSpace(string(text)))
+	if currency != "" && !currencyRe.MatchString(currency) {
+		return fmt.Errorf("currency must be an ISO 4217 code, got %q", text)
+	}
+
+	*c = CurrencyRequest(currency)
+	return nil
+}
+
 type IDPathValue int
 
 func (i *IDPathValue) UnmarshalHTTP(r *http.Request) error {
@@ -2,16 +2,30 @@ package api
 
 import (
 	"net/http"
+
+	"github.com/dmksnnk/octo/internal/platform/httpplatform"
 )
 
-func NewRouter(api API) *http.ServeMux {
+// NewRouter creates a router for the API.
+// Booking creation and confirmation are wrapped with the idempotent middleware, if set.
+func NewRouter(api API, idempotent httpplatform.Middleware) *http.ServeMux {
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /products", api.Products)
 	mux.HandleFunc("GET /products/{id}", api.Product)
 	mux.HandleFunc("POST /availability", api.Availability)
-	mux.HandleFunc("POST /bookings", api.CreateBooking)
+	mux.HandleFunc("POST /availability/calendar", api.AvailabilityCalendar)
+	mux.HandleFunc("POST /availability/{id}/waitlist", api.JoinWaitlist)
+	mux.HandleFunc("POST /tickets/{code}/redeem", api.RedeemTicket)
+	mux.HandleFunc("GET /waitlist/{id}", api.WaitlistEntry)
+	mux.Handle("POST /bookings", httpplatform.Wrap(http.HandlerFunc(api.CreateBooking), idempotent))
+	mux.HandleFunc("GET /bookings", api.Bookings)
 	mux.HandleFunc("GET /bookings/{id}", api.Booking)
-	mux.HandleFunc("POST /bookings/{id}/confirm", api.ConfirmBooking)
+	mux.HandleFunc("PATCH /bookings/{id}", api.UpdateBooking)
+	mux.Handle("POST /bookings/{id}/confirm", httpplatform.Wrap(http.HandlerFunc(api.ConfirmBooking), idempotent))
+	mux.HandleFunc("PATCH /bookings/{id}/extend", api.ExtendBooking)
+	mux.HandleFunc("POST /bookings/{id}/cancel", api.CancelBooking)
+	mux.HandleFunc("GET /bookings/{id}/units/{unitId}/ticket.png", api.TicketPNG)
+	mux.HandleFunc("GET /bookings/{id}/units/{unitId}/ticket.svg", api.TicketSVG)
 
 	return mux
 }
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+
+	"github.com/dmksnnk/octo/internal"
+)
+
+const (
+	// modulePixels is the size of a barcode module in PNG and SVG.
+	modulePixels = 4
+	// qrQuietZone is the light margin around QR codes in modules.
+	qrQuietZone = 4
+	// code128QuietZone is the light margin around Code128 barcodes in modules.
+	code128QuietZone = 10
+	// code128Height is the height of Code128 bars in modules.
+	code128Height = 50
+	// maxCode128Modules is the widest Code128 barcode without quiet zones, about 20 characters.
+	// Wider barcodes need too thin bars to fit the field of view of common scanners.
+	maxCode128Modules = 256
+)
+
+// errTicketTooLong is returned when a ticket does not fit a scannable barcode.
+var errTicketTooLong = errors.New("ticket too long")
+
+// artwork is a scannable ticket, a barcode surrounded by a quiet zone.
+type artwork struct {
+	code      barcode.Barcode
+	quietZone int
+	height    int // in modules, bars of linear barcodes are stretched to it
+}
+
+// newArtwork encodes the ticket in the product's delivery format.
+// Code128 encodes the short reference of the ticket, the signed code fits QR codes only.
+// It returns errTicketTooLong if the Code128 barcode would be too wide to scan.
+func newArtwork(ticket internal.UnitTicket) (artwork, error) {
+	switch ticket.DeliveryFormat {
+	case internal.DeliveryFormatCode128:
+		code, err := code128.Encode(ticket.Reference)
+		if err != nil {
+			return artwork{}, fmt.Errorf("encode code128: %w", err)
+		}
+		if width := code.Bounds().Dx(); width > maxCode128Modules {
+			return artwork{}, fmt.Errorf("%w: code128 of %d modules, at most %d", errTicketTooLong, width, maxCode128Modules)
+		}
+		return artwork{
+			code:      code,
+			quietZone: code128QuietZone,
+			height:    code128Height,
+		}, nil
+	default:
+		code, err := qr.Encode(ticket.Ticket, qr.M, qr.Auto)
+		if err != nil {
+			return artwork{}, fmt.Errorf("encode QR code: %w", err)
+		}
+		return artwork{
+			code:      code,
+			quietZone: qrQuietZone,
+			height:    code.Bounds().Dy(),
+		}, nil
+	}
+}
+
+// size returns width and height in modules, including the quiet zone.
+func (a artwork) size() (int, int) {
+	return a.code.Bounds().Dx() + 2*a.quietZone, a.height + 2*a.quietZone
+}
+
+// dark reports whether the module at x, y is dark.
+func (a artwork) dark(x, y int) bool {
+	bounds := a.code.Bounds()
+	x, y = x-a.quietZone, y-a.quietZone
+	if x < 0 || y < 0 || x >= bounds.Dx() || y >= a.height {
+		return false
+	}
+	if bounds.Dy() == 1 { // linear barcode
+		y = 0
+	}
+
+	gray := color.GrayModel.Convert(a.code.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+	return gray.Y < 128
+}
+
+func (a artwork) writePNG(w io.Writer) error {
+	width, height := a.size()
+	img := image.NewGray(image.Rect(0, 0, width*modulePixels, height*modulePixels))
+	for y := range img.Rect.Dy() {
+		for x := range img.Rect.Dx() {
+			c := color.White
+			if a.dark(x/modulePixels, y/modulePixels) {
+				c = color.Black
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// writeSVG writes the artwork as SVG with a module per user unit,
+// merging dark modules of a row into a single rectangle.
+func (a artwork) writeSVG(w io.Writer) error {
+	width, height := a.size()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" shape-rendering="crispEdges">`,
+		width, height, width*modulePixels, height*modulePixels)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="#ffffff"/>`, width, height)
+	fmt.Fprint(bw, `<path fill="#000000" d="`)
+	for y := range height {
+		for x := 0; x < width; x++ {
+			if !a.dark(x, y) {
+				continue
+			}
+			start := x
+			for x < width && a.dark(x, y) {
+				x++
+			}
+			fmt.Fprintf(bw, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+	fmt.Fprint(bw, `"/></svg>`)
+
+	return bw.Flush()
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dmksnnk/octo/internal"
+	"github.com/dmksnnk/octo/internal/ticket"
+)
+
+func TestCode128Width(t *testing.T) {
+	t.Run("reference fits", func(t *testing.T) {
+		unitTicket := internal.UnitTicket{
+			Ticket:         strings.Repeat("A", 120), // signed codes are too long for Code128
+			Reference:      ticket.Reference(),
+			DeliveryFormat: internal.DeliveryFormatCode128,
+		}
+		art, err := newArtwork(unitTicket)
+		if err != nil {
+			t.Fatalf("new artwork: %v", err)
+		}
+
+		if width := art.code.Bounds().Dx(); width > maxCode128Modules {
+			t.Errorf("want at most %d modules, got %d", maxCode128Modules, width)
+		}
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		unitTicket := internal.UnitTicket{
+			Reference:      strings.Repeat("A", 30),
+			DeliveryFormat: internal.DeliveryFormatCode128,
+		}
+		_, err := newArtwork(unitTicket)
+		if !errors.Is(err, errTicketTooLong) {
+			t.Errorf("want error %v, got %v", errTicketTooLong, err)
+		}
+	})
+}
@@ -2,6 +2,9 @@ package internal
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 )
 
 var (
@@ -9,4 +12,54 @@ var (
 	ErrNotFound = errors.New("not found")
 	// ErrNotAvailable is returned when a product is not available for booking.
 	ErrNotAvailable = errors.New("not available")
+	// ErrAlreadyExists is returned when a resource with the same unique identifier already exists.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrContactRequired is returned when a booking cannot be confirmed without the lead contact details.
+	ErrContactRequired = errors.New("contact required")
+	// ErrUnknownUnit is returned when a unit type does not exist for the product.
+	ErrUnknownUnit = errors.New("unknown unit")
+	// ErrUnitItemsRequired is returned when units of a booking with unit types are changed by number only.
+	ErrUnitItemsRequired = errors.New("unit items required")
+	// ErrWrongDate is returned when a ticket is redeemed on a date other than the booked one.
+	ErrWrongDate = errors.New("wrong date")
+	// ErrWebhookNotAllowed is returned when a webhook URL does not point to a public address.
+	ErrWebhookNotAllowed = errors.New("webhook not allowed")
 )
+
+// BookingStatusError is returned when an operation is not allowed for the current booking status.
+type BookingStatusError struct {
+	Status BookingStatus
+}
+
+func (e BookingStatusError) Error() string {
+	return fmt.Sprintf("booking is %s", strings.ToLower(string(e.Status)))
+}
+
+// UnsupportedCurrencyError is returned when a product is not priced in the requested currency.
+type UnsupportedCurrencyError struct {
+	Currency  string
+	Available []string
+}
+
+func (e UnsupportedCurrencyError) Error() string {
+	return fmt.Sprintf("currency %s is not supported, available: %s", e.Currency, strings.Join(e.Available, ", "))
+}
+
+// InvalidPromoCodeError is returned when a promo code cannot be applied to a booking.
+type InvalidPromoCodeError struct {
+	Code   string
+	Reason string // e.g. "expired"
+}
+
+func (e InvalidPromoCodeError) Error() string {
+	return fmt.Sprintf("promo code %s is %s", e.Code, e.Reason)
+}
+
+// TicketRedeemedError is returned when a ticket has already been redeemed.
+type TicketRedeemedError struct {
+	UTCRedeemedAt time.Time
+}
+
+func (e TicketRedeemedError) Error() string {
+	return fmt.Sprintf("ticket already redeemed at %s", e.UTCRedeemedAt.Format(time.RFC3339))
+}
@@ -0,0 +1,201 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/dmksnnk/octo/internal/auth"
+	"github.com/dmksnnk/octo/internal/platform/httpplatform"
+)
+
+// HeaderIdempotencyKey header name for the idempotency key.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// HeaderIdempotentReplayed is set on responses replayed from the storage.
+const HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+const maxKeyLength = 255
+
+// hashedHeaders change the response for the same body, so they are part of the request hash.
+var hashedHeaders = []string{"Octo-Currency", "Capability"}
+
+// Request stored under an idempotency key.
+type Request struct {
+	Hash []byte
+	// Response is nil while the first request with the key is being processed.
+	Response *Response
+}
+
+// Response stored for replaying.
+type Response struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// DB for storing idempotent requests.
+type DB interface {
+	// StartIdempotentRequest stores the request hash under the user's key.
+	// If the key is already used, it returns the stored request and ErrKeyExists.
+	StartIdempotentRequest(ctx context.Context, userID int, key string, hash []byte) (Request, error)
+	// SaveIdempotentResponse stores the response for the user's key.
+	SaveIdempotentResponse(ctx context.Context, userID int, key string, resp Response) error
+	// DeleteIdempotentRequest deletes the user's key if it has no response yet.
+	DeleteIdempotentRequest(ctx context.Context, userID int, key string) error
+}
+
+// ErrKeyExists is returned when the idempotency key is already used.
+var ErrKeyExists = errors.New("idempotency key exists")
+
+// Check is an HTTP middleware that makes requests with the Idempotency-Key header safe to retry.
+// The first response for a key is stored and replayed for subsequent requests with the same key.
+// Reusing the key with a different request returns an error response.
+// Server errors are not stored, so the request can be retried.
+// The key is released if the handler panics too.
+func Check(db DB) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxKeyLength {
+				httpplatform.WriteError(w, "idempotency key is too long", http.StatusBadRequest)
+				return
+			}
+
+			user, ok := auth.ContextUser(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				httpplatform.WriteError(w, "failed to read request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			hash := requestHash(r, body)
+			stored, err := db.StartIdempotentRequest(r.Context(), user.ID, key, hash)
+			if err != nil {
+				if errors.Is(err, ErrKeyExists) {
+					replay(w, stored, hash)
+					return
+				}
+
+				httpplatform.WriteError(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			// the client might be gone already, still finish bookkeeping
+			ctx := context.WithoutCancel(r.Context())
+			defer func() {
+				if v := recover(); v != nil {
+					// the key would be in progress forever otherwise
+					_ = db.DeleteIdempotentRequest(ctx, user.ID, key)
+					panic(v)
+				}
+			}()
+
+			rec := newRecorder()
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= http.StatusInternalServerError {
+				if err := db.DeleteIdempotentRequest(ctx, user.ID, key); err != nil {
+					httpplatform.WriteError(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+
+				rec.writeTo(w)
+				return
+			}
+
+			resp := Response{
+				StatusCode:  rec.status,
+				ContentType: rec.header.Get("Content-Type"),
+				Body:        rec.body.Bytes(),
+			}
+			if err := db.SaveIdempotentResponse(ctx, user.ID, key, resp); err != nil {
+				httpplatform.WriteError(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			rec.writeTo(w)
+		})
+	}
+}
+
+func replay(w http.ResponseWriter, stored Request, hash []byte) {
+	if !bytes.Equal(stored.Hash, hash) {
+		httpplatform.WriteError(w, "idempotency key is already used for a different request", http.StatusUnprocessableEntity)
+		return
+	}
+	if stored.Response == nil {
+		httpplatform.WriteError(w, "request with the same idempotency key is in progress", http.StatusConflict)
+		return
+	}
+
+	if stored.Response.ContentType != "" {
+		w.Header().Set("Content-Type", stored.Response.ContentType)
+	}
+	w.Header().Set(HeaderIdempotentReplayed, "true")
+	w.WriteHeader(stored.Response.StatusCode)
+	_, _ = w.Write(stored.Response.Body)
+}
+
+// requestHash identifies the request by its method, path, hashed headers and body.
+func requestHash(r *http.Request, body []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	for _, name := range hashedHeaders {
+		h.Write([]byte(r.Header.Get(name)))
+		h.Write([]byte{0})
+	}
+	h.Write(body)
+
+	return h.Sum(nil)
+}
+
+// recorder buffers the response, so it is stored before being sent to the client.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		header: make(http.Header),
+		status: http.StatusOK,
+	}
+}
+
+func (rec *recorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *recorder) WriteHeader(code int) {
+	rec.status = code
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	return rec.body.Write(b)
+}
+
+func (rec *recorder) writeTo(w http.ResponseWriter) {
+	for k, v := range rec.header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rec.status)
+	_, _ = w.Write(rec.body.Bytes())
+}
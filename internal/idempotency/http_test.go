@@ -0,0 +1,255 @@
+package idempotency_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dmksnnk/octo/internal"
+	"github.com/dmksnnk/octo/internal/auth"
+	"github.com/dmksnnk/octo/internal/idempotency"
+)
+
+func TestCheck(t *testing.T) {
+	t.Run("replays response", func(t *testing.T) {
+		var calls int
+		srv := newTestServer(t, newMemoryDB(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = io.Copy(w, r.Body)
+		}))
+
+		first := doRequest(t, srv, "key", `{"units":1}`)
+		second := doRequest(t, srv, "key", `{"units":1}`)
+
+		if calls != 1 {
+			t.Errorf("want handler called once, got %d", calls)
+		}
+		assertResponse(t, first, http.StatusCreated, `{"units":1}`)
+		assertResponse(t, second, http.StatusCreated, `{"units":1}`)
+		if got := second.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("want replayed content type, got %q", got)
+		}
+		if got := second.Header.Get(idempotency.HeaderIdempotentReplayed); got != "true" {
+			t.Errorf("want replayed header, got %q", got)
+		}
+	})
+
+	t.Run("different payload", func(t *testing.T) {
+		srv := newTestServer(t, newMemoryDB(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		doRequest(t, srv, "key", `{"units":1}`)
+		resp := doRequest(t, srv, "key", `{"units":2}`)
+
+		assertResponse(t, resp, http.StatusUnprocessableEntity,
+			`{"code":422,"message":"idempotency key is already used for a different request","details":null}`+"\n")
+		if got := resp.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("want JSON error, got content type %q", got)
+		}
+	})
+
+	t.Run("different currency", func(t *testing.T) {
+		srv := newTestServer(t, newMemoryDB(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		doRequest(t, srv, "key", `{"units":1}`)
+		resp := doRequest(t, srv, "key", `{"units":1}`, func(r *http.Request) {
+			r.Header.Set("Octo-Currency", "USD")
+		})
+
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("want status %d, got %d", http.StatusUnprocessableEntity, resp.StatusCode)
+		}
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		var calls int
+		srv := newTestServer(t, newMemoryDB(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		doRequest(t, srv, "", `{"units":1}`)
+		doRequest(t, srv, "", `{"units":1}`)
+
+		if calls != 2 {
+			t.Errorf("want handler called twice, got %d", calls)
+		}
+	})
+
+	t.Run("retry after server error", func(t *testing.T) {
+		var calls int
+		srv := newTestServer(t, newMemoryDB(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				http.Error(w, "boom", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		first := doRequest(t, srv, "key", `{"units":1}`)
+		second := doRequest(t, srv, "key", `{"units":1}`)
+
+		if first.StatusCode != http.StatusInternalServerError {
+			t.Errorf("want status %d, got %d", http.StatusInternalServerError, first.StatusCode)
+		}
+		if second.StatusCode != http.StatusCreated {
+			t.Errorf("want status %d, got %d", http.StatusCreated, second.StatusCode)
+		}
+	})
+
+	t.Run("retry after panic", func(t *testing.T) {
+		db := newMemoryDB()
+		check := idempotency.Check(db)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}))
+		req := httptest.NewRequest(http.MethodPost, "/bookings", strings.NewReader(`{"units":1}`))
+		req.Header.Set(idempotency.HeaderIdempotencyKey, "key")
+		req = req.WithContext(auth.ContextWithUser(req.Context(), user))
+
+		func() {
+			defer func() {
+				if v := recover(); v == nil {
+					t.Error("want panic to be propagated")
+				}
+			}()
+			check.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+
+		if len(db.requests) != 0 {
+			t.Errorf("want key to be released, got %+v", db.requests)
+		}
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		var srv *httptest.Server
+		srv = newTestServer(t, newMemoryDB(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// a concurrent request with the same key
+			resp := doRequest(t, srv, "key", `{"units":1}`)
+			if resp.StatusCode != http.StatusConflict {
+				t.Errorf("want status %d, got %d", http.StatusConflict, resp.StatusCode)
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		resp := doRequest(t, srv, "key", `{"units":1}`)
+		if resp.StatusCode != http.StatusCreated {
+			t.Errorf("want status %d, got %d", http.StatusCreated, resp.StatusCode)
+		}
+	})
+}
+
+var user = internal.User{ID: 1, Email: "test@example.com"}
+
+func newTestServer(t *testing.T, db idempotency.DB, handler http.Handler) *httptest.Server {
+	t.Helper()
+
+	check := idempotency.Check(db)(handler)
+	srv := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := auth.ContextWithUser(r.Context(), user)
+			check.ServeHTTP(w, r.WithContext(ctx))
+		}),
+	)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func doRequest(t *testing.T, srv *httptest.Server, key, body string, ops ...func(*http.Request)) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/bookings", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	if key != "" {
+		req.Header.Set(idempotency.HeaderIdempotencyKey, key)
+	}
+	for _, op := range ops {
+		op(req)
+	}
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+func assertResponse(t *testing.T, resp *http.Response, wantStatus int, wantBody string) {
+	t.Helper()
+
+	if resp.StatusCode != wantStatus {
+		t.Errorf("want status %d, got %d", wantStatus, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	if string(body) != wantBody {
+		t.Errorf("want body %q, got %q", wantBody, body)
+	}
+}
+
+type memoryKey struct {
+	userID int
+	key    string
+}
+
+type memoryDB struct {
+	mu       sync.Mutex
+	requests map[memoryKey]idempotency.Request
+}
+
+func newMemoryDB() *memoryDB {
+	return &memoryDB{requests: make(map[memoryKey]idempotency.Request)}
+}
+
+func (db *memoryDB) StartIdempotentRequest(_ context.Context, userID int, key string, hash []byte) (idempotency.Request, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k := memoryKey{userID, key}
+	if req, ok := db.requests[k]; ok {
+		return req, idempotency.ErrKeyExists
+	}
+
+	req := idempotency.Request{Hash: hash}
+	db.requests[k] = req
+
+	return req, nil
+}
+
+func (db *memoryDB) SaveIdempotentResponse(_ context.Context, userID int, key string, resp idempotency.Response) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k := memoryKey{userID, key}
+	req := db.requests[k]
+	req.Response = &resp
+	db.requests[k] = req
+
+	return nil
+}
+
+func (db *memoryDB) DeleteIdempotentRequest(_ context.Context, userID int, key string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	delete(db.requests, memoryKey{userID, key})
+
+	return nil
+}
@@ -1,14 +1,20 @@
 package internal
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 const dateFormat = "2006-01-02"
 
+// DefaultOptionID is the option of products without variants.
+const DefaultOptionID = "DEFAULT"
+
 // User represents an application user.
 type User struct {
 	ID    int    `json:"id"`
@@ -27,6 +33,16 @@ type ProductBase struct {
 	ID       string `json:"id"`
 	Name     string `json:"name"`
 	Capacity int    `json:"capacity"`
+	// ContactRequiredToConfirm is set when bookings need the lead contact's name and email to be confirmed.
+	ContactRequiredToConfirm bool             `json:"contactRequiredToConfirm"`
+	AvailabilityType         AvailabilityType `json:"availabilityType"`
+	Timezone                 string           `json:"timezone"` // IANA time zone of availability local date-times
+	DeliveryFormat           DeliveryFormat   `json:"deliveryFormat"`
+	DeliveryMethods          []DeliveryMethod `json:"deliveryMethods"`
+	DefaultCurrency          string           `json:"defaultCurrency"`     // used when no currency is requested
+	AvailableCurrencies      []string         `json:"availableCurrencies"` // the product can be priced and booked in
+	Options                  []Option         `json:"options"`
+	Units                    []UnitType       `json:"units"`
 }
 
 func (p ProductBase) IsProduct() {}
@@ -42,24 +58,161 @@ type Product interface {
 	IsProduct()
 }
 
+// OptionBase is a product option without any additional capabilities.
+type OptionBase struct {
+	ID       string `json:"id"` // e.g. "DEFAULT"
+	Name     string `json:"name"`
+	Capacity int    `json:"capacity"`
+}
+
+func (o OptionBase) IsOption() {}
+
+// OptionWithPrice is a product option with price capability.
+type OptionWithPrice struct {
+	OptionBase
+	CapabilityPrice
+}
+
+// Option represents a variant of a product, e.g. a morning or an evening tour.
+// Each option has its own availability.
+type Option interface {
+	IsOption()
+}
+
+// UnitTypeBase is a unit type without any additional capabilities.
+type UnitTypeBase struct {
+	ID   string `json:"id"` // e.g. "adult"
+	Name string `json:"name"`
+}
+
+func (u UnitTypeBase) IsUnitType() {}
+
+// UnitTypeWithPrice is a unit type with price capability.
+type UnitTypeWithPrice struct {
+	UnitTypeBase
+	CapabilityPrice
+}
+
+// UnitType represents a type of unit a product can be booked for, e.g. adult or child.
+type UnitType interface {
+	IsUnitType()
+}
+
 // AvailabilityBase is avalability without any additional capabilities.
 type AvailabilityBase struct {
-	ID        string             `json:"id"`
-	LocalDate Date               `json:"localDate"`
-	Status    AvailabilityStatus `json:"status"`
-	Vacancies int                `json:"vacancies"`
-	Available bool               `json:"available"`
+	ID                 string             `json:"id"`
+	LocalDate          Date               `json:"localDate"`
+	LocalDateTimeStart time.Time          `json:"localDateTimeStart"` // in the product's time zone
+	LocalDateTimeEnd   time.Time          `json:"localDateTimeEnd"`   // in the product's time zone
+	AllDay             bool               `json:"allDay"`
+	Status             AvailabilityStatus `json:"status"`
+	Capacity           int                `json:"capacity"`
+	Vacancies          int                `json:"vacancies"`
+	Utilization        float64            `json:"utilization"` // share of capacity sold, from 0 to 1
+	Available          bool               `json:"available"`
+	OpeningHours       []OpeningHours     `json:"openingHours"` // empty unless the product has OPENING_HOURS availability type
 }
 
 func (a AvailabilityBase) IsAvailability() {}
 
+// OpeningHours is a range of local times in the product's time zone,
+// e.g. when a day pass can be used.
+type OpeningHours struct {
+	From string `json:"from"` // e.g. "09:00"
+	To   string `json:"to"`   // e.g. "17:00"
+}
+
 type AvailabilityStatus string
 
 const (
 	AvailabilityStatusAvailable AvailabilityStatus = "AVAILABLE"
-	AvailabilityStatusSoldOut   AvailabilityStatus = "SOLD_OUT"
+	// AvailabilityStatusFreesale has unlimited vacancies.
+	AvailabilityStatusFreesale AvailabilityStatus = "FREESALE"
+	// AvailabilityStatusLimited has fewer vacancies than the product's limited threshold.
+	AvailabilityStatusLimited AvailabilityStatus = "LIMITED"
+	AvailabilityStatusSoldOut AvailabilityStatus = "SOLD_OUT"
+	// AvailabilityStatusClosed is shut by the operator and cannot be booked, existing bookings are kept.
+	AvailabilityStatusClosed AvailabilityStatus = "CLOSED"
+)
+
+// Available reports whether an availability with the status can be booked.
+func (s AvailabilityStatus) Available() bool {
+	switch s {
+	case AvailabilityStatusAvailable, AvailabilityStatusFreesale, AvailabilityStatusLimited:
+		return true
+	default:
+		return false
+	}
+}
+
+// AvailabilityType is how availabilities of a product are scheduled.
+type AvailabilityType string
+
+const (
+	// AvailabilityTypeStartTime availabilities are slots with a fixed start time.
+	AvailabilityTypeStartTime AvailabilityType = "START_TIME"
+	// AvailabilityTypeOpeningHours availabilities last the whole day and are open during opening hours.
+	AvailabilityTypeOpeningHours AvailabilityType = "OPENING_HOURS"
+)
+
+// Scan implements the [sql.Scanner] interface.
+func (a *AvailabilityType) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		*a = AvailabilityType(v)
+		return nil
+	case []byte:
+		*a = AvailabilityType(string(v))
+		return nil
+	default:
+		return fmt.Errorf("cannot convert %T to AvailabilityType", value)
+	}
+}
+
+// DeliveryFormat is how tickets of a product are rendered for scanning.
+type DeliveryFormat string
+
+const (
+	DeliveryFormatQRCode  DeliveryFormat = "QRCODE"
+	DeliveryFormatCode128 DeliveryFormat = "CODE128"
+)
+
+// Scan implements the [sql.Scanner] interface.
+func (d *DeliveryFormat) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		*d = DeliveryFormat(v)
+		return nil
+	case []byte:
+		*d = DeliveryFormat(string(v))
+		return nil
+	default:
+		return fmt.Errorf("cannot convert %T to DeliveryFormat", value)
+	}
+}
+
+// DeliveryMethod is what is issued for a confirmed booking of a product.
+type DeliveryMethod string
+
+const (
+	DeliveryMethodTicket  DeliveryMethod = "TICKET"  // a ticket per unit
+	DeliveryMethodVoucher DeliveryMethod = "VOUCHER" // a voucher per booking, admitting all of its units
 )
 
+// Scan implements the [sql.Scanner] interface.
+func (d *DeliveryMethod) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		*d = DeliveryMethod(v)
+		return nil
+	case []byte:
+		*d = DeliveryMethod(string(v))
+		return nil
+	default:
+		return fmt.Errorf("cannot convert %T to DeliveryMethod", value)
+	}
+}
+
 // AvailabilityWithPrice is an availability with price capability.
 type AvailabilityWithPrice struct {
 	AvailabilityBase
@@ -71,13 +224,43 @@ type Availability interface {
 	IsAvailability()
 }
 
+// AvailabilityCalendarBase is availability of a product on a local date
+// without any additional capabilities.
+type AvailabilityCalendarBase struct {
+	LocalDate Date               `json:"localDate"`
+	Status    AvailabilityStatus `json:"status"`
+	Vacancies int                `json:"vacancies"` // total of all slots on the date
+	Available bool               `json:"available"`
+}
+
+func (a AvailabilityCalendarBase) IsAvailabilityCalendar() {}
+
+// AvailabilityCalendarWithPrice is a calendar date with price capability,
+// the lowest price of the slots on the date.
+type AvailabilityCalendarWithPrice struct {
+	AvailabilityCalendarBase
+	CapabilityPrice
+}
+
+// AvailabilityCalendar represents the availability of a product aggregated per local date.
+type AvailabilityCalendar interface {
+	IsAvailabilityCalendar()
+}
+
 // BookingBase is a booking without any additional capabilities.
 type BookingBase struct {
-	ID             string        `json:"id"`
-	Status         BookingStatus `json:"status"`
-	ProductID      string        `json:"productId"`
-	AvailabilityID string        `json:"availabilityId"`
-	Units          []Unit        `json:"units"`
+	ID                string        `json:"id"`
+	UUID              string        `json:"uuid"`
+	ResellerReference *string       `json:"resellerReference"`
+	Status            BookingStatus `json:"status"`
+	ProductID         string        `json:"productId"`
+	OptionID          string        `json:"optionId"`
+	AvailabilityID    string        `json:"availabilityId"`
+	Units             []Unit        `json:"units"`
+	Voucher           *string       `json:"voucher"` // generated when the booking is CONFIRMED if the product is delivered with vouchers
+	UTCExpiresAt      *time.Time    `json:"utcExpiresAt"`
+	Cancellation      *Cancellation `json:"cancellation"`
+	Contact           Contact       `json:"contact"`
 }
 
 func (b BookingBase) IsBooking() {}
@@ -86,18 +269,59 @@ func (b BookingBase) IsBooking() {}
 type BookingWithPrice struct {
 	BookingBase
 	CapabilityPrice
+	Discount *Discount `json:"discount"` // nil if booked without a promo code
+}
+
+// Discount is the discount of a promo code applied to a booking total.
+type Discount struct {
+	PromoCode string       `json:"promoCode"`
+	Type      DiscountType `json:"type"`
+	Value     int          `json:"value"`  // percent or amount in minor units of the currency, by type
+	Amount    int          `json:"amount"` // subtracted from the booking total
+	Currency  string       `json:"currency"`
 }
 
+type DiscountType string
+
+const (
+	DiscountTypePercentage DiscountType = "PERCENTAGE"
+	DiscountTypeFixed      DiscountType = "FIXED"
+)
+
 // Booking represents a booking in the system.
 type Booking interface {
 	IsBooking()
 }
 
+// Cancellation holds details of a cancelled booking.
+type Cancellation struct {
+	Reason         string    `json:"reason"`
+	UTCCancelledAt time.Time `json:"utcCancelledAt"`
+}
+
+// Contact holds the lead traveller's contact details.
+type Contact struct {
+	FullName     *string  `json:"fullName"`
+	EmailAddress *string  `json:"emailAddress"`
+	PhoneNumber  *string  `json:"phoneNumber"` // E.164
+	Locales      []string `json:"locales"`     // BCP 47 language tags
+	Country      *string  `json:"country"`     // ISO 3166-1 alpha-2
+	Notes        *string  `json:"notes"`
+}
+
+// Complete reports whether the contact is enough to confirm a booking
+// for products requiring it.
+func (c Contact) Complete() bool {
+	return c.FullName != nil && c.EmailAddress != nil
+}
+
 type BookingStatus string
 
 const (
 	BookingStatusReserved  BookingStatus = "RESERVED"
 	BookingStatusConfirmed BookingStatus = "CONFIRMED"
+	BookingStatusCancelled BookingStatus = "CANCELLED"
+	BookingStatusExpired   BookingStatus = "EXPIRED"
 )
 
 // Scan implements the [sql.Scanner] interface.
@@ -114,6 +338,57 @@ func (b *BookingStatus) Scan(value any) error {
 	}
 }
 
+// UnitTicket is the ticket of a unit with the format to render it in.
+type UnitTicket struct {
+	Ticket         string
+	Reference      string // short code redeemed like the ticket, for linear barcodes
+	DeliveryFormat DeliveryFormat
+}
+
+// Redemption is a ticket or voucher redeemed at the gate.
+type Redemption struct {
+	BookingID      string     `json:"bookingId"`
+	ProductID      string     `json:"productId"`
+	AvailabilityID string     `json:"availabilityId"`
+	Unit           *UnitBase  `json:"unit"`  // redeemed with a ticket, nil for vouchers
+	Units          []UnitBase `json:"units"` // all redeemed units, the booking's units not redeemed yet for vouchers
+}
+
+// WaitlistEntry is a user's demand for units of an availability which had not enough vacancies.
+// Once enough vacancies return, the entry is reserved with a new booking.
+type WaitlistEntry struct {
+	ID             string         `json:"id"`
+	Status         WaitlistStatus `json:"status"`
+	OptionID       string         `json:"optionId"`
+	AvailabilityID string         `json:"availabilityId"`
+	Units          int            `json:"units"`
+	Currency       string         `json:"currency"` // the reservation is priced in
+	BookingID      *string        `json:"bookingId"`
+	WebhookURL     *string        `json:"webhookUrl"`
+	UTCCreatedAt   time.Time      `json:"utcCreatedAt"`
+}
+
+type WaitlistStatus string
+
+const (
+	WaitlistStatusWaiting  WaitlistStatus = "WAITING"
+	WaitlistStatusReserved WaitlistStatus = "RESERVED"
+)
+
+// Scan implements the [sql.Scanner] interface.
+func (w *WaitlistStatus) Scan(value any) error {
+	switch v := value.(type) {
+	case string:
+		*w = WaitlistStatus(v)
+		return nil
+	case []byte:
+		*w = WaitlistStatus(string(v))
+		return nil
+	default:
+		return fmt.Errorf("cannot convert %T to WaitlistStatus", value)
+	}
+}
+
 type CapabilityRequest int
 
 const (
@@ -128,8 +403,10 @@ type Capability interface {
 
 // UnitBase is a unit without any additional capabilities.
 type UnitBase struct {
-	ID     string  `json:"id"`
-	Ticket *string `json:"ticket"`
+	ID            string     `json:"id"`
+	UnitID        *string    `json:"unitId"` // unit type, nil for units without type
+	Ticket        *string    `json:"ticket"`
+	UTCRedeemedAt *time.Time `json:"utcRedeemedAt"` // nil until the ticket is redeemed
 }
 
 func (u UnitBase) IsUnit() {}
@@ -152,17 +429,118 @@ func (c CapabilityNone) IsCapability() {}
 
 // CapabilityPrice adds a price capability for objects.
 type CapabilityPrice struct {
-	Price    int    `json:"price"`
-	Currency string `json:"currency"`
+	Price    int     `json:"price"` // retail price, same as Pricing.Retail
+	Currency string  `json:"currency"`
+	Pricing  Pricing `json:"pricing"`
 }
 
 func (c CapabilityPrice) IsCapability() {}
 
+// Pricing is a price breakdown, like the OCTO pricing capability.
+// Amounts are in the minor units of the currency.
+type Pricing struct {
+	Original          int           `json:"original"` // before discounts
+	Retail            int           `json:"retail"`   // paid by the customer
+	Net               *int          `json:"net"`      // paid by the reseller, nil if unknown
+	Currency          string        `json:"currency"`
+	CurrencyPrecision int           `json:"currencyPrecision"` // number of minor unit digits, e.g. 2 for EUR
+	IncludedTaxes     IncludedTaxes `json:"includedTaxes"`
+}
+
+// Tax is a tax included in a price.
+type Tax struct {
+	Name   string `json:"name"`
+	Retail int    `json:"retail"`
+	Net    *int   `json:"net"` // nil if unknown
+}
+
+// IncludedTaxes are taxes included in a price, stored as JSON.
+type IncludedTaxes []Tax
+
+// Scan implements the [sql.Scanner] interface.
+func (t *IncludedTaxes) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	default:
+		return fmt.Errorf("cannot convert %T to IncludedTaxes", value)
+	}
+}
+
+// Value implements the [driver.Valuer] interface.
+func (t IncludedTaxes) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
 type CreateBookingRequest struct {
-	ProductID      int
+	ProductID         int
+	OptionID          string // availability must belong to the option if set
+	AvailabilityID    int
+	Units             int
+	UnitIDs           []string // unit type of each unit, units have no type if empty
+	UserID            int
+	UUID              uuid.UUID // generated if zero
+	ResellerReference string
+	Contact           *Contact // optional
+	Currency          string   // the booking is priced in, the product's default currency if empty
+	PromoCode         string   // optional
+}
+
+// ConfirmBookingRequest confirms a reservation.
+type ConfirmBookingRequest struct {
+	ID      int
+	UserID  int
+	Contact *Contact // replaces the current contact if set
+}
+
+// UpdateBookingRequest changes a reservation.
+// Zero values keep the current availability or number of units.
+type UpdateBookingRequest struct {
+	ID             int
+	UserID         int
 	AvailabilityID int
 	Units          int
+	UnitIDs        []string // unit type of each unit, replaces the units if set
+	Contact        *Contact // replaces the current contact if set
+}
+
+// JoinWaitlistRequest records demand for units of an availability.
+type JoinWaitlistRequest struct {
+	AvailabilityID int
 	UserID         int
+	OptionID       string // optional, availability must belong to the option if set
+	Units          int
+	UnitIDs        []string // unit type of each unit, units have no type if empty
+	WebhookURL     string   // optional, notified when the entry is reserved
+	Currency       string   // the reservation is priced in, the product's default currency if empty
+}
+
+// BookingsFilter selects bookings of a user.
+// Zero values do not filter.
+type BookingsFilter struct {
+	UserID            int
+	Status            BookingStatus
+	ProductID         int
+	LocalDateStart    time.Time // availability date
+	LocalDateEnd      time.Time // availability date
+	CreatedAtStart    time.Time
+	CreatedAtEnd      time.Time
+	UUID              uuid.UUID
+	ResellerReference string
+	Cursor            int // continue after this booking ID
+	Limit             int
+}
+
+// BookingsPage is a page of bookings.
+type BookingsPage struct {
+	Bookings []Booking
+	// NextCursor is a cursor for the next page, zero if there are no more bookings.
+	NextCursor int
 }
 
 // Date is custom type for handling date JSON serialization and deserialization.
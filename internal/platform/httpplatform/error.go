@@ -0,0 +1,29 @@
+package httpplatform
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Error is the error response of the API.
+type Error struct {
+	// Code is a status code.
+	Code int `json:"code"`
+
+	// Message is a developer-facing error message.
+	Message string `json:"message"`
+
+	// Details is the error details.
+	Details []string `json:"details"`
+}
+
+// WriteError writes the error as JSON with the status code.
+func WriteError(w http.ResponseWriter, message string, code int, details ...string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(Error{
+		Code:    code,
+		Message: message,
+		Details: details,
+	})
+}
@@ -0,0 +1,89 @@
+package httpplatform
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// nonPublicPrefixes are ranges not reachable on the internet which
+// netip.Addr methods do not cover, e.g. shared address space of carrier-grade NAT, often used by clusters.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // this network
+	netip.MustParsePrefix("100.64.0.0/10"),  // shared address space
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, can map to private IPv4 addresses
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),  // documentation
+}
+
+// IsPublic reports whether the address is a public unicast address,
+// i.e. not loopback, private, link-local, unspecified or multicast.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckPublicHost resolves the host and returns an error if any of its addresses is not public.
+func CheckPublicHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !IsPublic(addr) {
+			return fmt.Errorf("%s resolves to non-public address %s", host, addr.Unmap())
+		}
+	}
+
+	return nil
+}
+
+// NewPublicClient returns an HTTP client which connects to public addresses only, see IsPublic.
+// Addresses are checked when connecting, so hosts resolving to other addresses later are refused too.
+// Redirects are not followed, the redirect response is returned instead.
+func NewPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: dialPublic,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // connect to the checked address, not to a proxy
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// dialPublic refuses connections to non-public addresses, it is called with the resolved address.
+func dialPublic(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("parse address: %w", err)
+	}
+	if !IsPublic(addrPort.Addr()) {
+		return fmt.Errorf("connection to non-public address %s refused", addrPort.Addr().Unmap())
+	}
+
+	return nil
+}
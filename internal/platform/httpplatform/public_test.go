@@ -0,0 +1,58 @@
+package httpplatform_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/dmksnnk/octo/internal/platform/httpplatform"
+)
+
+func TestIsPublic(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.215.14":          true,
+		"2606:2800:21f:cb07::1":  true,
+		"127.0.0.1":              false,
+		"::1":                    false,
+		"10.0.0.1":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false, // cloud metadata
+		"fe80::1":                false,
+		"fd00::1":                false,
+		"0.0.0.0":                false,
+		"::":                     false,
+		"100.64.0.1":             false,
+		"224.0.0.1":              false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+	} {
+		if got := httpplatform.IsPublic(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("IsPublic(%s) = %t, want %t", addr, got, want)
+		}
+	}
+}
+
+func TestCheckPublicHost(t *testing.T) {
+	for _, host := range []string{"127.0.0.1", "169.254.169.254", "::1", "localhost"} {
+		if err := httpplatform.CheckPublicHost(context.TODO(), host); err == nil {
+			t.Errorf("want error for host %s", host)
+		}
+	}
+}
+
+func TestPublicClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request to loopback server must not be sent")
+	}))
+	t.Cleanup(srv.Close)
+
+	resp, err := httpplatform.NewPublicClient(time.Second).Get(srv.URL)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("want error connecting to loopback address")
+	}
+}
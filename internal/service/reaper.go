@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Reaper periodically expires reservations which were not confirmed in time,
+// so abandoned reservations do not hold vacancies forever.
+// It expires idempotency keys as well.
+type Reaper struct {
+	service  Service
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewReaper(service Service, interval time.Duration, logger *slog.Logger) Reaper {
+	return Reaper{
+		service:  service,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run expires reservations and idempotency keys every interval until the context is cancelled.
+func (r Reaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.expire(ctx)
+			r.expireIdempotencyKeys(ctx)
+		}
+	}
+}
+
+func (r Reaper) expire(ctx context.Context) {
+	expired, err := r.service.ExpireReservations(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Error("failed to expire reservations", "error", err)
+		}
+		return
+	}
+
+	if expired > 0 {
+		r.logger.Info("expired reservations", "count", expired)
+	}
+}
+
+func (r Reaper) expireIdempotencyKeys(ctx context.Context) {
+	deleted, err := r.service.ExpireIdempotencyKeys(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Error("failed to expire idempotency keys", "error", err)
+		}
+		return
+	}
+
+	if deleted > 0 {
+		r.logger.Info("expired idempotency keys", "count", deleted)
+	}
+}
@@ -4,38 +4,127 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/url"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/dmksnnk/octo/internal"
+	"github.com/dmksnnk/octo/internal/platform/httpplatform"
 )
 
 type DB interface {
 	// Products returns all products.
-	Products(ctx context.Context, capability internal.CapabilityRequest) ([]internal.Product, error)
+	// With price capability, products are priced in the currency, or in their default currency if empty;
+	// products not priced in the currency are skipped.
+	// It returns internal.UnsupportedCurrencyError if none of the products is priced in the currency, regardless of capability.
+	Products(ctx context.Context, capability internal.CapabilityRequest, currency string) ([]internal.Product, error)
 	// Product returns a product by id.
+	// With price capability, it is priced in the currency, or in its default currency if empty.
 	// It returns ErrNotFound if the product is not found.
-	Product(ctx context.Context, id int, capability internal.CapabilityRequest) (internal.Product, error)
-	// Availability returns availability for a product on a given date.
-	// It returns ErrNotFound if the product is not found.
-	Availability(ctx context.Context, productID int, localDate time.Time, capability internal.CapabilityRequest) (internal.Availability, error)
-	// Availabilities returns availabilities for a product in a given date range.
-	Availabilities(ctx context.Context, productID int, localDateStart, localDateEnd time.Time, capability internal.CapabilityRequest) ([]internal.Availability, error)
+	// It returns internal.UnsupportedCurrencyError if the product is not priced in the currency, regardless of capability.
+	Product(ctx context.Context, id int, capability internal.CapabilityRequest, currency string) (internal.Product, error)
+	// Availabilities returns availabilities for a product option starting in a given local date range,
+	// ordered by start time. Prices are in the currency, or in the product's default currency if empty.
+	// It returns internal.UnsupportedCurrencyError if the product is not priced in the currency, regardless of capability.
+	Availabilities(ctx context.Context, productID int, optionID string, localDateStart, localDateEnd time.Time, capability internal.CapabilityRequest, currency string) ([]internal.Availability, error)
+	// AvailabilityCalendar returns availability of a product per local date in a given range,
+	// aggregated across its options and slots. Dates without availabilities are closed.
+	// Prices are in the currency, or in the product's default currency if empty.
+	// It returns no dates if the product is not found.
+	// It returns internal.UnsupportedCurrencyError if the product is not priced in the currency, regardless of capability.
+	AvailabilityCalendar(ctx context.Context, productID int, localDateStart, localDateEnd time.Time, capability internal.CapabilityRequest, currency string) ([]internal.AvailabilityCalendar, error)
 	// CreateBooking creates a booking for a product.
-	// It returns ErrNotAvailable if the product is not available for booking.
+	// It returns ErrNotAvailable if the product is not available for booking,
+	// e.g. the availability is closed or out of vacancies, or the availability does not belong to the option.
+	// Vacancies of freesale availabilities are not checked.
+	// It returns ErrAlreadyExists if the user already has a booking with the same UUID or reseller reference.
+	// It returns ErrUnknownUnit if a unit type does not exist for the product.
+	// It returns internal.UnsupportedCurrencyError if the product is not priced in the currency.
+	// It returns internal.InvalidPromoCodeError if the promo code does not exist or cannot be applied.
 	CreateBooking(ctx context.Context, params CreateBookingParams) (int, error)
-	// ConfirmBooking confirms a booking.
+	// ConfirmBooking confirms a booking, storing the contact if set.
+	// It returns ErrNotFound if the booking is not found.
+	// It returns ErrContactRequired if the product requires a complete contact and the booking has none.
+	// It returns internal.BookingStatusError if the booking cannot be confirmed,
+	// e.g. it is cancelled or the reservation has expired.
+	ConfirmBooking(ctx context.Context, params ConfirmBookingParams) error
+	// UpdateBooking changes units, availability or contact of a reservation.
+	// Vacancies are released on the old availability and reserved on the new one.
+	// Released vacancies are reserved for waiting entries of the old availability, like in ReserveWaitlisted.
+	// Units of a type are kept if the type is still wanted, added units are priced like in CreateBooking.
+	// It returns ErrNotFound if the booking is not found.
+	// It returns ErrNotAvailable if the new availability does not have enough vacancies.
+	// It returns ErrUnknownUnit if a unit type does not exist for the product.
+	// It returns ErrUnitItemsRequired if only the number of units is changed, but the booking has units with types.
+	// It returns internal.BookingStatusError if the booking is not reserved or the reservation has expired.
+	UpdateBooking(ctx context.Context, params UpdateBookingParams) error
+	// ExtendBooking moves expiry of a reservation forward, up to the product's maximum reservation hold.
+	// It returns ErrNotFound if the booking is not found.
+	// It returns internal.BookingStatusError if the booking is not reserved or the reservation has expired.
+	ExtendBooking(ctx context.Context, params ExtendBookingParams) error
+	// CancelBooking cancels a booking and returns its units to the availability.
+	// Returned vacancies are reserved for waiting entries, like in ReserveWaitlisted, until waitlistExpiresAt.
 	// It returns ErrNotFound if the booking is not found.
-	ConfirmBooking(ctx context.Context, id int, userID int) error
+	// It returns internal.BookingStatusError if the booking has already expired.
+	CancelBooking(ctx context.Context, id int, userID int, reason string, waitlistExpiresAt time.Time) error
+	// ExpireBookings expires reservations which were not confirmed before now
+	// and returns their units to the availability.
+	// Returned vacancies are reserved for waiting entries, like in ReserveWaitlisted, until waitlistExpiresAt.
+	// It returns the number of expired bookings.
+	ExpireBookings(ctx context.Context, now, waitlistExpiresAt time.Time) (int, error)
+	// DeleteExpiredIdempotentRequests deletes idempotency keys still in progress since before inProgressBefore,
+	// e.g. left by a crash, and keys with a response stored before completedBefore.
+	// It returns the number of deleted keys.
+	DeleteExpiredIdempotentRequests(ctx context.Context, inProgressBefore, completedBefore time.Time) (int, error)
 	// Booking returns a booking by id.
 	// It returns ErrNotFound if the booking is not found.
 	Booking(ctx context.Context, id int, userID int, capability internal.CapabilityRequest) (internal.Booking, error)
+	// Bookings returns a page of bookings matching the filter, ordered by ID.
+	Bookings(ctx context.Context, filter internal.BookingsFilter, capability internal.CapabilityRequest) (internal.BookingsPage, error)
+	// UnitTicket returns the ticket of a booking's unit.
+	// It returns ErrNotFound if the unit is not found or has no ticket, e.g. the booking is not confirmed.
+	UnitTicket(ctx context.Context, bookingID, unitID, userID int) (internal.UnitTicket, error)
+	// RedeemTicket marks the unit of the user's booking with the ticket as redeemed by the user.
+	// If no unit has the ticket, it redeems the units of the user's booking with the voucher which are not redeemed yet.
+	// It returns ErrNotFound if no unit of the user's bookings has the ticket and none of them has the voucher.
+	// It returns internal.BookingStatusError if the booking is not confirmed.
+	// It returns internal.TicketRedeemedError if the ticket or all units of the voucher have already been redeemed.
+	// It returns ErrWrongDate if the availability is not on the local date of now in the product's time zone.
+	RedeemTicket(ctx context.Context, ticket string, userID int, now time.Time) (internal.Redemption, error)
+	// CreateWaitlistEntry records demand for units of an availability
+	// and reserves it right away if the availability has enough vacancies.
+	// It returns ErrNotFound if the availability is not found or does not belong to the option.
+	// It returns ErrUnknownUnit if a unit type does not exist for the product.
+	// It returns internal.UnsupportedCurrencyError if the product is not priced in the currency.
+	CreateWaitlistEntry(ctx context.Context, params CreateWaitlistEntryParams) (int, error)
+	// WaitlistEntry returns a waitlist entry by id.
+	// It returns ErrNotFound if the entry is not found.
+	WaitlistEntry(ctx context.Context, id int, userID int) (internal.WaitlistEntry, error)
+	// ReserveWaitlisted creates reservations for waiting entries which fit into the vacancies,
+	// oldest entries first. Entries which do not fit keep waiting.
+	// Units are priced like in CreateBooking.
+	// It returns the number of reserved entries.
+	ReserveWaitlisted(ctx context.Context, expiresAt time.Time) (int, error)
+	// UnnotifiedWaitlistEntries returns reserved entries with a webhook which was not notified yet.
+	UnnotifiedWaitlistEntries(ctx context.Context) ([]internal.WaitlistEntry, error)
+	// SetWaitlistEntryNotified marks the webhook of a waitlist entry as notified.
+	SetWaitlistEntryNotified(ctx context.Context, id int) error
 }
 
 type CreateBookingParams struct {
-	ProductID      int
-	AvailabilityID int
-	Units          int
-	UserID         int
+	ProductID         int
+	OptionID          string // availability must belong to the option if set
+	AvailabilityID    int
+	Units             int
+	UnitIDs           []string // unit type of each unit, units have no type if empty
+	UserID            int
+	ExpiresAt         time.Time
+	UUID              uuid.UUID // generated if zero
+	ResellerReference string
+	Contact           *internal.Contact
+	Currency          string // the product's default currency if empty
+	PromoCode         string // optional, applied and used in the same transaction
 }
 
 var (
@@ -43,20 +132,78 @@ var (
 	ErrNotFound = fmt.Errorf("not found")
 	// ErrNotAvailable is returned by database when a product is not available for booking.
 	ErrNotAvailable = fmt.Errorf("not available")
+	// ErrAlreadyExists is returned by database when a resource violates a uniqueness constraint.
+	ErrAlreadyExists = fmt.Errorf("already exists")
+	// ErrContactRequired is returned by database when a booking cannot be confirmed without a contact.
+	ErrContactRequired = fmt.Errorf("contact required")
+	// ErrUnknownUnit is returned by database when a unit type does not exist for the product.
+	ErrUnknownUnit = fmt.Errorf("unknown unit")
+	// ErrUnitItemsRequired is returned by database when units of a booking with unit types are changed by number only.
+	ErrUnitItemsRequired = fmt.Errorf("unit items required")
+	// ErrWrongDate is returned by database when a ticket is not for the current local date.
+	ErrWrongDate = fmt.Errorf("wrong date")
 )
 
+type UpdateBookingParams struct {
+	ID             int
+	UserID         int
+	AvailabilityID int               // zero keeps current availability
+	Units          int               // zero keeps current number of units
+	UnitIDs        []string          // unit type of each unit, replaces units if set
+	Contact        *internal.Contact // nil keeps current contact
+	// WaitlistExpiresAt is the expiry of reservations made for waiting entries from released vacancies.
+	WaitlistExpiresAt time.Time
+}
+
+type ConfirmBookingParams struct {
+	ID      int
+	UserID  int
+	Contact *internal.Contact // nil keeps current contact
+}
+
+type ExtendBookingParams struct {
+	ID        int
+	UserID    int
+	ExpiresAt time.Time
+}
+
+type CreateWaitlistEntryParams struct {
+	AvailabilityID int
+	OptionID       string // optional, availability must belong to the option if set
+	UserID         int
+	Units          int
+	UnitIDs        []string  // unit type of each unit, units have no type if empty
+	Currency       string    // the product's default currency if empty
+	WebhookURL     string    // optional
+	ExpiresAt      time.Time // expiry of the reservation if made right away
+}
+
+// Config configures the service.
+type Config struct {
+	// ReservationHold is how long a reservation holds vacancies before it expires.
+	ReservationHold time.Duration
+	// IdempotencyKeyTTL is how long responses are replayed for idempotency keys.
+	IdempotencyKeyTTL time.Duration
+}
+
+// idempotencyKeyInProgressTimeout is how long a key can be in progress,
+// after that the request is considered lost and the key is released.
+const idempotencyKeyInProgressTimeout = 10 * time.Minute
+
 type Service struct {
-	db DB
+	db  DB
+	cfg Config
 }
 
-func NewService(db DB) Service {
+func NewService(db DB, cfg Config) Service {
 	return Service{
-		db: db,
+		db:  db,
+		cfg: cfg,
 	}
 }
 
-func (s Service) Products(ctx context.Context, capability internal.CapabilityRequest) ([]internal.Product, error) {
-	products, err := s.db.Products(ctx, capability)
+func (s Service) Products(ctx context.Context, capability internal.CapabilityRequest, currency string) ([]internal.Product, error) {
+	products, err := s.db.Products(ctx, capability, currency)
 	if err != nil {
 		return nil, fmt.Errorf("get products: %w", err)
 	}
@@ -64,8 +211,8 @@ func (s Service) Products(ctx context.Context, capability internal.CapabilityReq
 	return products, nil
 }
 
-func (s Service) Product(ctx context.Context, id int, capability internal.CapabilityRequest) (internal.Product, error) {
-	product, err := s.db.Product(ctx, id, capability)
+func (s Service) Product(ctx context.Context, id int, capability internal.CapabilityRequest, currency string) (internal.Product, error) {
+	product, err := s.db.Product(ctx, id, capability, currency)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
 			return nil, internal.ErrNotFound
@@ -77,39 +224,50 @@ func (s Service) Product(ctx context.Context, id int, capability internal.Capabi
 	return product, nil
 }
 
-func (s Service) Availability(ctx context.Context, productID int, localDate time.Time, capability internal.CapabilityRequest) (internal.Availability, error) {
-	availability, err := s.db.Availability(ctx, productID, localDate, capability)
+func (s Service) Availabilities(ctx context.Context, productID int, optionID string, localDateStart, localDateEnd time.Time, capability internal.CapabilityRequest, currency string) ([]internal.Availability, error) {
+	availabilities, err := s.db.Availabilities(ctx, productID, optionID, localDateStart, localDateEnd, capability, currency)
 	if err != nil {
-		if errors.Is(err, ErrNotFound) {
-			return nil, internal.ErrNotFound
-		}
-		return nil, fmt.Errorf("get availability: %w", err)
+		return nil, fmt.Errorf("get availabilities: %w", err)
 	}
 
-	return availability, nil
+	return availabilities, nil
 }
 
-func (s Service) Availabilities(ctx context.Context, productID int, localDateStart, localDateEnd time.Time, capability internal.CapabilityRequest) ([]internal.Availability, error) {
-	availabilities, err := s.db.Availabilities(ctx, productID, localDateStart, localDateEnd, capability)
+func (s Service) AvailabilityCalendar(ctx context.Context, productID int, localDateStart, localDateEnd time.Time, capability internal.CapabilityRequest, currency string) ([]internal.AvailabilityCalendar, error) {
+	calendar, err := s.db.AvailabilityCalendar(ctx, productID, localDateStart, localDateEnd, capability, currency)
 	if err != nil {
-		return nil, fmt.Errorf("get availabilities: %w", err)
+		return nil, fmt.Errorf("get availability calendar: %w", err)
 	}
 
-	return availabilities, nil
+	return calendar, nil
 }
 
 func (s Service) CreateBooking(ctx context.Context, req internal.CreateBookingRequest) (int, error) {
 	params := CreateBookingParams{
-		ProductID:      req.ProductID,
-		AvailabilityID: req.AvailabilityID,
-		Units:          req.Units,
-		UserID:         req.UserID,
+		ProductID:         req.ProductID,
+		OptionID:          req.OptionID,
+		AvailabilityID:    req.AvailabilityID,
+		Units:             req.Units,
+		UnitIDs:           req.UnitIDs,
+		UserID:            req.UserID,
+		ExpiresAt:         time.Now().Add(s.cfg.ReservationHold),
+		UUID:              req.UUID,
+		ResellerReference: req.ResellerReference,
+		Contact:           req.Contact,
+		Currency:          req.Currency,
+		PromoCode:         req.PromoCode,
 	}
 	id, err := s.db.CreateBooking(ctx, params)
 	if err != nil {
 		if errors.Is(err, ErrNotAvailable) {
 			return 0, internal.ErrNotAvailable
 		}
+		if errors.Is(err, ErrAlreadyExists) {
+			return 0, internal.ErrAlreadyExists
+		}
+		if errors.Is(err, ErrUnknownUnit) {
+			return 0, internal.ErrUnknownUnit
+		}
 
 		return 0, fmt.Errorf("create booking: %w", err)
 	}
@@ -117,17 +275,128 @@ func (s Service) CreateBooking(ctx context.Context, req internal.CreateBookingRe
 	return id, nil
 }
 
-func (s Service) ConfirmBooking(ctx context.Context, id, userID int) error {
-	if err := s.db.ConfirmBooking(ctx, id, userID); err != nil {
+func (s Service) ConfirmBooking(ctx context.Context, req internal.ConfirmBookingRequest) error {
+	params := ConfirmBookingParams{
+		ID:      req.ID,
+		UserID:  req.UserID,
+		Contact: req.Contact,
+	}
+	if err := s.db.ConfirmBooking(ctx, params); err != nil {
 		if errors.Is(err, ErrNotFound) {
 			return internal.ErrNotFound
 		}
+		if errors.Is(err, ErrContactRequired) {
+			return internal.ErrContactRequired
+		}
+		var statusErr internal.BookingStatusError
+		if errors.As(err, &statusErr) {
+			return statusErr
+		}
 		return fmt.Errorf("confirm booking: %w", err)
 	}
 
 	return nil
 }
 
+func (s Service) UpdateBooking(ctx context.Context, req internal.UpdateBookingRequest) error {
+	params := UpdateBookingParams{
+		ID:             req.ID,
+		UserID:         req.UserID,
+		AvailabilityID: req.AvailabilityID,
+		Units:          req.Units,
+		UnitIDs:        req.UnitIDs,
+		Contact:        req.Contact,
+		// vacancies released by the update go to the waitlist first
+		WaitlistExpiresAt: time.Now().Add(s.cfg.ReservationHold),
+	}
+	if err := s.db.UpdateBooking(ctx, params); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return internal.ErrNotFound
+		}
+		if errors.Is(err, ErrNotAvailable) {
+			return internal.ErrNotAvailable
+		}
+		if errors.Is(err, ErrUnknownUnit) {
+			return internal.ErrUnknownUnit
+		}
+		if errors.Is(err, ErrUnitItemsRequired) {
+			return internal.ErrUnitItemsRequired
+		}
+		var statusErr internal.BookingStatusError
+		if errors.As(err, &statusErr) {
+			return statusErr
+		}
+		return fmt.Errorf("update booking: %w", err)
+	}
+
+	return nil
+}
+
+// ExtendBooking extends a reservation's hold by the given duration from now.
+// If hold is zero, the default reservation hold is used.
+func (s Service) ExtendBooking(ctx context.Context, id, userID int, hold time.Duration) error {
+	if hold == 0 {
+		hold = s.cfg.ReservationHold
+	}
+
+	params := ExtendBookingParams{
+		ID:        id,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(hold),
+	}
+	if err := s.db.ExtendBooking(ctx, params); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return internal.ErrNotFound
+		}
+		var statusErr internal.BookingStatusError
+		if errors.As(err, &statusErr) {
+			return statusErr
+		}
+		return fmt.Errorf("extend booking: %w", err)
+	}
+
+	return nil
+}
+
+func (s Service) CancelBooking(ctx context.Context, id, userID int, reason string) error {
+	if err := s.db.CancelBooking(ctx, id, userID, reason, time.Now().Add(s.cfg.ReservationHold)); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return internal.ErrNotFound
+		}
+		var statusErr internal.BookingStatusError
+		if errors.As(err, &statusErr) {
+			return statusErr
+		}
+		return fmt.Errorf("cancel booking: %w", err)
+	}
+
+	return nil
+}
+
+// ExpireReservations expires reservations which were not confirmed in time.
+// It returns the number of expired reservations.
+func (s Service) ExpireReservations(ctx context.Context) (int, error) {
+	now := time.Now()
+	expired, err := s.db.ExpireBookings(ctx, now, now.Add(s.cfg.ReservationHold))
+	if err != nil {
+		return 0, fmt.Errorf("expire bookings: %w", err)
+	}
+
+	return expired, nil
+}
+
+// ExpireIdempotencyKeys releases keys of lost requests and deletes keys older than the TTL.
+// It returns the number of deleted keys.
+func (s Service) ExpireIdempotencyKeys(ctx context.Context) (int, error) {
+	now := time.Now()
+	deleted, err := s.db.DeleteExpiredIdempotentRequests(ctx, now.Add(-idempotencyKeyInProgressTimeout), now.Add(-s.cfg.IdempotencyKeyTTL))
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotent requests: %w", err)
+	}
+
+	return deleted, nil
+}
+
 func (s Service) Booking(ctx context.Context, id, userID int, capability internal.CapabilityRequest) (internal.Booking, error) {
 	booking, err := s.db.Booking(ctx, id, userID, capability)
 	if err != nil {
@@ -139,3 +408,130 @@ func (s Service) Booking(ctx context.Context, id, userID int, capability interna
 
 	return booking, nil
 }
+
+func (s Service) Bookings(ctx context.Context, filter internal.BookingsFilter, capability internal.CapabilityRequest) (internal.BookingsPage, error) {
+	page, err := s.db.Bookings(ctx, filter, capability)
+	if err != nil {
+		return internal.BookingsPage{}, fmt.Errorf("get bookings: %w", err)
+	}
+
+	return page, nil
+}
+
+func (s Service) UnitTicket(ctx context.Context, bookingID, unitID, userID int) (internal.UnitTicket, error) {
+	ticket, err := s.db.UnitTicket(ctx, bookingID, unitID, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return internal.UnitTicket{}, internal.ErrNotFound
+		}
+		return internal.UnitTicket{}, fmt.Errorf("get unit ticket: %w", err)
+	}
+
+	return ticket, nil
+}
+
+// RedeemTicket redeems a ticket or voucher at the gate on the booked date.
+func (s Service) RedeemTicket(ctx context.Context, ticket string, userID int) (internal.Redemption, error) {
+	redemption, err := s.db.RedeemTicket(ctx, ticket, userID, time.Now())
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return internal.Redemption{}, internal.ErrNotFound
+		}
+		if errors.Is(err, ErrWrongDate) {
+			return internal.Redemption{}, internal.ErrWrongDate
+		}
+		var statusErr internal.BookingStatusError
+		if errors.As(err, &statusErr) {
+			return internal.Redemption{}, statusErr
+		}
+		var redeemedErr internal.TicketRedeemedError
+		if errors.As(err, &redeemedErr) {
+			return internal.Redemption{}, redeemedErr
+		}
+		return internal.Redemption{}, fmt.Errorf("redeem ticket: %w", err)
+	}
+
+	return redemption, nil
+}
+
+// JoinWaitlist records demand for units of an availability.
+// The entry is reserved once enough vacancies return.
+// It returns internal.ErrWebhookNotAllowed if the webhook does not resolve to public addresses only.
+// It returns internal.ErrUnknownUnit if a unit type does not exist for the product.
+func (s Service) JoinWaitlist(ctx context.Context, req internal.JoinWaitlistRequest) (int, error) {
+	if req.WebhookURL != "" {
+		u, err := url.Parse(req.WebhookURL)
+		if err != nil {
+			return 0, fmt.Errorf("parse webhook URL: %w", err)
+		}
+		// the webhook client checks addresses again when connecting, hosts can resolve differently later
+		if err := httpplatform.CheckPublicHost(ctx, u.Hostname()); err != nil {
+			return 0, fmt.Errorf("%w: %w", internal.ErrWebhookNotAllowed, err)
+		}
+	}
+
+	params := CreateWaitlistEntryParams{
+		AvailabilityID: req.AvailabilityID,
+		OptionID:       req.OptionID,
+		UserID:         req.UserID,
+		Units:          req.Units,
+		UnitIDs:        req.UnitIDs,
+		Currency:       req.Currency,
+		WebhookURL:     req.WebhookURL,
+		ExpiresAt:      time.Now().Add(s.cfg.ReservationHold),
+	}
+	id, err := s.db.CreateWaitlistEntry(ctx, params)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return 0, internal.ErrNotFound
+		}
+		if errors.Is(err, ErrUnknownUnit) {
+			return 0, internal.ErrUnknownUnit
+		}
+		return 0, fmt.Errorf("create waitlist entry: %w", err)
+	}
+
+	return id, nil
+}
+
+func (s Service) WaitlistEntry(ctx context.Context, id, userID int) (internal.WaitlistEntry, error) {
+	entry, err := s.db.WaitlistEntry(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return internal.WaitlistEntry{}, internal.ErrNotFound
+		}
+		return internal.WaitlistEntry{}, fmt.Errorf("get waitlist entry: %w", err)
+	}
+
+	return entry, nil
+}
+
+// ReserveWaitlisted reserves waitlist entries for which vacancies returned outside of bookings,
+// e.g. after a top-up. Vacancies returned by bookings are reserved for the waitlist right away.
+// It returns the number of reserved entries.
+func (s Service) ReserveWaitlisted(ctx context.Context) (int, error) {
+	reserved, err := s.db.ReserveWaitlisted(ctx, time.Now().Add(s.cfg.ReservationHold))
+	if err != nil {
+		return 0, fmt.Errorf("reserve waitlisted: %w", err)
+	}
+
+	return reserved, nil
+}
+
+// UnnotifiedWaitlistEntries returns reserved waitlist entries whose webhook was not notified yet.
+func (s Service) UnnotifiedWaitlistEntries(ctx context.Context) ([]internal.WaitlistEntry, error) {
+	entries, err := s.db.UnnotifiedWaitlistEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get unnotified waitlist entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (s Service) SetWaitlistEntryNotified(ctx context.Context, id int) error {
+	if err := s.db.SetWaitlistEntryNotified(ctx, id); err != nil {
+		return fmt.Errorf("set waitlist entry notified: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dmksnnk/octo/internal"
+)
+
+// Waitlister periodically reserves waitlist entries for which vacancies returned outside of bookings,
+// e.g. a top-up by the operator, and notifies webhooks of the reserved entries.
+// Undelivered notifications are retried on the next run.
+type Waitlister struct {
+	service  Service
+	client   *http.Client
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewWaitlister(service Service, client *http.Client, interval time.Duration, logger *slog.Logger) Waitlister {
+	return Waitlister{
+		service:  service,
+		client:   client,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run reserves waitlist entries and notifies webhooks every interval until the context is cancelled.
+func (w Waitlister) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.reserve(ctx)
+			w.notify(ctx)
+		}
+	}
+}
+
+func (w Waitlister) reserve(ctx context.Context) {
+	reserved, err := w.service.ReserveWaitlisted(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Error("failed to reserve waitlisted", "error", err)
+		}
+		return
+	}
+
+	if reserved > 0 {
+		w.logger.Info("reserved waitlisted", "count", reserved)
+	}
+}
+
+func (w Waitlister) notify(ctx context.Context) {
+	entries, err := w.service.UnnotifiedWaitlistEntries(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Error("failed to get unnotified waitlist entries", "error", err)
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		if err := w.post(ctx, entry); err != nil {
+			if ctx.Err() == nil {
+				w.logger.Error("failed to notify waitlist webhook", "entry_id", entry.ID, "error", err)
+			}
+			continue
+		}
+
+		id, _ := strconv.Atoi(entry.ID)
+		if err := w.service.SetWaitlistEntryNotified(ctx, id); err != nil {
+			if ctx.Err() == nil {
+				w.logger.Error("failed to set waitlist entry notified", "entry_id", entry.ID, "error", err)
+			}
+		}
+	}
+}
+
+// post sends the entry to its webhook.
+func (w Waitlister) post(ctx context.Context, entry internal.WaitlistEntry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal entry: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, *entry.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -5,18 +5,29 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"golang.org/x/text/currency"
+
 	"github.com/dmksnnk/octo/internal"
 	"github.com/dmksnnk/octo/internal/auth"
+	"github.com/dmksnnk/octo/internal/idempotency"
 	"github.com/dmksnnk/octo/internal/platform"
 	"github.com/dmksnnk/octo/internal/service"
 	"github.com/dmksnnk/octo/internal/storage/queries"
+	"github.com/dmksnnk/octo/internal/ticket"
 )
 
+// timeFormat is the format of local times, e.g. of opening hours.
+const timeFormat = "15:04"
+
 type Postgres struct {
-	db *sql.DB
+	db     *sql.DB
+	signer ticket.Signer
 }
 
 var (
@@ -24,9 +35,11 @@ var (
 	_ auth.DB    = (*Postgres)(nil)
 )
 
-func NewPostgres(db *sql.DB) Postgres {
+// NewPostgres creates storage which signs tickets of confirmed bookings with the signer.
+func NewPostgres(db *sql.DB, signer ticket.Signer) Postgres {
 	return Postgres{
-		db: db,
+		db:     db,
+		signer: signer,
 	}
 }
 
@@ -45,10 +58,107 @@ func (p Postgres) UserByAPIKey(ctx context.Context, key string) (internal.User,
 	}, nil
 }
 
-func (p Postgres) Products(ctx context.Context, capability internal.CapabilityRequest) ([]internal.Product, error) {
+func (p Postgres) StartIdempotentRequest(ctx context.Context, userID int, key string, hash []byte) (idempotency.Request, error) {
+	qrs := queries.New(p.db)
+	inserted, err := qrs.InsertIdempotencyKey(ctx, queries.InsertIdempotencyKeyParams{
+		UserID:      int32(userID),
+		Key:         key,
+		RequestHash: hash,
+	})
+	if err != nil {
+		return idempotency.Request{}, fmt.Errorf("insert idempotency key: %w", err)
+	}
+	if inserted == 1 {
+		return idempotency.Request{Hash: hash}, nil
+	}
+
+	stored, err := qrs.IdempotencyKey(ctx, queries.IdempotencyKeyParams{
+		UserID: int32(userID),
+		Key:    key,
+	})
+	if err != nil {
+		return idempotency.Request{}, fmt.Errorf("get idempotency key: %w", err)
+	}
+
+	req := idempotency.Request{Hash: stored.RequestHash}
+	if stored.ResponseStatus.Valid {
+		req.Response = &idempotency.Response{
+			StatusCode:  int(stored.ResponseStatus.Int32),
+			ContentType: stored.ResponseContentType.String,
+			Body:        stored.ResponseBody,
+		}
+	}
+
+	return req, idempotency.ErrKeyExists
+}
+
+func (p Postgres) SaveIdempotentResponse(ctx context.Context, userID int, key string, resp idempotency.Response) error {
+	err := queries.New(p.db).SetIdempotencyKeyResponse(ctx, queries.SetIdempotencyKeyResponseParams{
+		ResponseStatus:      int32(resp.StatusCode),
+		ResponseContentType: resp.ContentType,
+		ResponseBody:        resp.Body,
+		UserID:              int32(userID),
+		Key:                 key,
+	})
+	if err != nil {
+		return fmt.Errorf("set idempotency key response: %w", err)
+	}
+
+	return nil
+}
+
+func (p Postgres) DeleteIdempotentRequest(ctx context.Context, userID int, key string) error {
+	err := queries.New(p.db).DeleteIdempotencyKey(ctx, queries.DeleteIdempotencyKeyParams{
+		UserID: int32(userID),
+		Key:    key,
+	})
+	if err != nil {
+		return fmt.Errorf("delete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+func (p Postgres) DeleteExpiredIdempotentRequests(ctx context.Context, inProgressBefore, completedBefore time.Time) (int, error) {
+	deleted, err := queries.New(p.db).DeleteExpiredIdempotencyKeys(ctx, queries.DeleteExpiredIdempotencyKeysParams{
+		InProgressBefore: inProgressBefore,
+		CompletedBefore:  completedBefore,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+
+	return int(deleted), nil
+}
+
+func (p Postgres) Products(ctx context.Context, capability internal.CapabilityRequest, currency string) ([]internal.Product, error) {
+	qrs := queries.New(p.db)
+	products, err := qrs.Products(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get products: %w", err)
+	}
+	ids := mapp(products, func(p queries.Product) int32 { return p.ID })
+	currencies, err := p.currencies(ctx, qrs, ids)
+	if err != nil {
+		return nil, err
+	}
+	if currency != "" {
+		if err := anyPricedIn(currency, currencies); err != nil {
+			return nil, err
+		}
+	}
+	options, err := p.options(ctx, ids, capability, currency)
+	if err != nil {
+		return nil, err
+	}
+	unitTypes, err := p.unitTypes(ctx, ids, capability, currency)
+	if err != nil {
+		return nil, err
+	}
+
 	switch capability {
 	case internal.CapabilityRequestPrice:
-		productsWithPrices, err := queries.New(p.db).ProductsWithPrices(ctx)
+		productsWithPrices, err := qrs.ProductsWithPrices(ctx, stringToNullString(currency))
 		if err != nil {
 			return nil, fmt.Errorf("get products with prices: %w", err)
 		}
@@ -56,360 +166,1745 @@ func (p Postgres) Products(ctx context.Context, capability internal.CapabilityRe
 		return mapp(
 			productsWithPrices,
 			func(pp queries.ProductsWithPricesRow) internal.Product {
-				return toProductWithPrice(pp.Product, pp.Price)
+				return toProductWithPrice(pp.Product, pp.Price, options[pp.Product.ID], unitTypes[pp.Product.ID], currencies[pp.Product.ID])
 			},
 		), nil
 	default:
-		products, err := queries.New(p.db).Products(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("get products: %w", err)
-		}
 		return mapp(
 			products,
 			func(p queries.Product) internal.Product {
-				return toProduct(p)
+				return toProduct(p, options[p.ID], unitTypes[p.ID], currencies[p.ID])
 			},
 		), nil
 	}
 }
 
-func (p Postgres) Product(ctx context.Context, id int, capability internal.CapabilityRequest) (internal.Product, error) {
+// anyPricedIn returns internal.UnsupportedCurrencyError if none of the products is priced in the currency.
+func anyPricedIn(currency string, currencies map[int32][]string) error {
+	var available []string
+	for _, productCurrencies := range currencies {
+		available = append(available, productCurrencies...)
+	}
+	slices.Sort(available)
+	available = slices.Compact(available)
+	if !slices.Contains(available, currency) {
+		return internal.UnsupportedCurrencyError{
+			Currency:  currency,
+			Available: available,
+		}
+	}
+
+	return nil
+}
+
+func (p Postgres) Product(ctx context.Context, id int, capability internal.CapabilityRequest, currency string) (internal.Product, error) {
+	qrs := queries.New(p.db)
+	currencies, err := p.currencies(ctx, qrs, []int32{int32(id)})
+	if err != nil {
+		return nil, err
+	}
+	// validated regardless of capability, so all endpoints reject unsupported currencies the same way
+	currency, err = p.currency(ctx, qrs, int32(id), currency)
+	if err != nil {
+		return nil, err
+	}
+
 	switch capability {
 	case internal.CapabilityRequestPrice:
-		productWithPrice, err := queries.New(p.db).ProductWithPrice(ctx, int32(id))
+		productWithPrice, err := qrs.ProductWithPrice(ctx, queries.ProductWithPriceParams{
+			ID:       int32(id),
+			Currency: currency,
+		})
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return nil, service.ErrNotFound
 			}
 			return nil, fmt.Errorf("get product with price: %w", err)
 		}
+		options, err := p.options(ctx, []int32{int32(id)}, capability, currency)
+		if err != nil {
+			return nil, err
+		}
+		unitTypes, err := p.unitTypes(ctx, []int32{int32(id)}, capability, currency)
+		if err != nil {
+			return nil, err
+		}
 
-		return toProductWithPrice(productWithPrice.Product, productWithPrice.Price), nil
+		return toProductWithPrice(productWithPrice.Product, productWithPrice.Price, options[int32(id)], unitTypes[int32(id)], currencies[int32(id)]), nil
 	default:
-		product, err := queries.New(p.db).Product(ctx, int32(id))
+		product, err := qrs.Product(ctx, int32(id))
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				return nil, service.ErrNotFound
 			}
 			return internal.ProductBase{}, fmt.Errorf("get product: %w", err)
 		}
+		options, err := p.options(ctx, []int32{int32(id)}, capability, currency)
+		if err != nil {
+			return nil, err
+		}
+		unitTypes, err := p.unitTypes(ctx, []int32{int32(id)}, capability, currency)
+		if err != nil {
+			return nil, err
+		}
+
+		return toProduct(product, options[int32(id)], unitTypes[int32(id)], currencies[int32(id)]), nil
+	}
+}
+
+// currencies returns currencies the products are priced in by product ID.
+func (p Postgres) currencies(ctx context.Context, qrs *queries.Queries, productIDs []int32) (map[int32][]string, error) {
+	rows, err := qrs.Currencies(ctx, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get currencies: %w", err)
+	}
+
+	currencies := make(map[int32][]string)
+	for _, row := range rows {
+		currencies[row.ProductID] = append(currencies[row.ProductID], row.Currency)
+	}
+
+	return currencies, nil
+}
+
+// currency resolves the currency to price the product in, the product's default currency if requested is empty.
+// It returns service.ErrNotFound if the product is not found
+// and internal.UnsupportedCurrencyError if the product is not priced in the requested currency.
+func (p Postgres) currency(ctx context.Context, qrs *queries.Queries, productID int32, requested string) (string, error) {
+	product, err := qrs.Product(ctx, productID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", service.ErrNotFound
+		}
+		return "", fmt.Errorf("get product: %w", err)
+	}
+	if requested == "" {
+		return product.DefaultCurrency, nil
+	}
 
-		return toProduct(product), nil
+	currencies, err := p.currencies(ctx, qrs, []int32{productID})
+	if err != nil {
+		return "", err
+	}
+	if !slices.Contains(currencies[productID], requested) {
+		return "", internal.UnsupportedCurrencyError{
+			Currency:  requested,
+			Available: currencies[productID],
+		}
 	}
+
+	return requested, nil
 }
 
-func (p Postgres) Availability(ctx context.Context, productID int, localDate time.Time, capability internal.CapabilityRequest) (internal.Availability, error) {
+// options returns options of the products by product ID.
+func (p Postgres) options(ctx context.Context, productIDs []int32, capability internal.CapabilityRequest, currency string) (map[int32][]internal.Option, error) {
+	options := make(map[int32][]internal.Option)
 	switch capability {
 	case internal.CapabilityRequestPrice:
-		params := queries.AvalabilityWithPriceParams{
-			ProductID: int32(productID),
-			LocalDate: localDate,
-		}
-		availability, err := queries.New(p.db).AvalabilityWithPrice(ctx, params)
+		rows, err := queries.New(p.db).OptionsWithPrices(ctx, queries.OptionsWithPricesParams{
+			ProductIds: productIDs,
+			Currency:   stringToNullString(currency),
+		})
 		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				return nil, service.ErrNotFound
+			return nil, fmt.Errorf("get options with prices: %w", err)
+		}
+		for _, row := range rows {
+			option := internal.OptionWithPrice{
+				OptionBase:      toOption(row.Option),
+				CapabilityPrice: toPrice(row.Price),
 			}
-			return nil, err
+			options[row.Option.ProductID] = append(options[row.Option.ProductID], option)
 		}
-
-		return toAvailabilityWithPrice(availability.Availability, availability.Price), nil
 	default:
-		params := queries.AvalabilityParams{
-			ProductID: int32(productID),
-			LocalDate: localDate,
+		rows, err := queries.New(p.db).Options(ctx, productIDs)
+		if err != nil {
+			return nil, fmt.Errorf("get options: %w", err)
 		}
-		availability, err := queries.New(p.db).Avalability(ctx, params)
+		for _, row := range rows {
+			options[row.ProductID] = append(options[row.ProductID], toOption(row))
+		}
+	}
+
+	return options, nil
+}
+
+// unitTypes returns unit types of the products by product ID.
+func (p Postgres) unitTypes(ctx context.Context, productIDs []int32, capability internal.CapabilityRequest, currency string) (map[int32][]internal.UnitType, error) {
+	unitTypes := make(map[int32][]internal.UnitType)
+	switch capability {
+	case internal.CapabilityRequestPrice:
+		rows, err := queries.New(p.db).UnitTypesWithPrices(ctx, queries.UnitTypesWithPricesParams{
+			ProductIds: productIDs,
+			Currency:   stringToNullString(currency),
+		})
 		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				return nil, service.ErrNotFound
+			return nil, fmt.Errorf("get unit types with prices: %w", err)
+		}
+		for _, row := range rows {
+			unitType := internal.UnitTypeWithPrice{
+				UnitTypeBase:    toUnitType(row.UnitType),
+				CapabilityPrice: toPrice(row.Price),
 			}
-			return nil, err
+			unitTypes[row.UnitType.ProductID] = append(unitTypes[row.UnitType.ProductID], unitType)
+		}
+	default:
+		rows, err := queries.New(p.db).UnitTypes(ctx, productIDs)
+		if err != nil {
+			return nil, fmt.Errorf("get unit types: %w", err)
+		}
+		for _, row := range rows {
+			unitTypes[row.ProductID] = append(unitTypes[row.ProductID], toUnitType(row))
 		}
-
-		return toAvailability(availability), nil
 	}
+
+	return unitTypes, nil
 }
 
-func (p Postgres) Availabilities(ctx context.Context, productID int, localDateStart, localDateEnd time.Time, capability internal.CapabilityRequest) ([]internal.Availability, error) {
+func (p Postgres) Availabilities(ctx context.Context, productID int, optionID string, localDateStart, localDateEnd time.Time, capability internal.CapabilityRequest, currency string) ([]internal.Availability, error) {
+	currency, err := p.currency(ctx, queries.New(p.db), int32(productID), currency)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) { // no availabilities for unknown products
+			return []internal.Availability{}, nil
+		}
+		return nil, err
+	}
+
 	switch capability {
 	case internal.CapabilityRequestPrice:
 		params := queries.AvalabilityWithPriceRangeParams{
 			ProductID:      int32(productID),
+			OptionCode:     optionID,
 			LocalDateStart: localDateStart,
 			LocalDateEnd:   localDateEnd,
+			Currency:       currency,
+		}
+		rows, err := queries.New(p.db).AvalabilityWithPriceRange(ctx, params)
+		if err != nil {
+			return nil, err
 		}
-		availabilities, err := queries.New(p.db).AvalabilityWithPriceRange(ctx, params)
+		openingHours, err := p.openingHours(ctx, mapp(rows, func(row queries.AvalabilityWithPriceRangeRow) int32 { return row.Availability.ID }))
 		if err != nil {
 			return nil, err
 		}
 
-		return mapp(
-			availabilities,
-			func(a queries.AvalabilityWithPriceRangeRow) internal.Availability {
-				return toAvailabilityWithPrice(a.Availability, a.Price)
-			},
-		), nil
+		availabilities := make([]internal.Availability, len(rows))
+		for i, row := range rows {
+			availability, err := toAvailability(row.Availability, row.Timezone, row.LimitedThreshold, openingHours[row.Availability.ID])
+			if err != nil {
+				return nil, err
+			}
+			availabilities[i] = internal.AvailabilityWithPrice{
+				AvailabilityBase: availability,
+				CapabilityPrice:  toPrice(row.Price),
+			}
+		}
+
+		return availabilities, nil
 	default:
 		params := queries.AvalabilityRangeParams{
 			ProductID:      int32(productID),
+			OptionCode:     optionID,
 			LocalDateStart: localDateStart,
 			LocalDateEnd:   localDateEnd,
 		}
-		availabilities, err := queries.New(p.db).AvalabilityRange(ctx, params)
+		rows, err := queries.New(p.db).AvalabilityRange(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		openingHours, err := p.openingHours(ctx, mapp(rows, func(row queries.AvalabilityRangeRow) int32 { return row.Availability.ID }))
 		if err != nil {
 			return nil, err
 		}
 
-		return mapp(
-			availabilities,
-			func(a queries.Availability) internal.Availability {
-				return toAvailability(a)
-			},
-		), nil
+		availabilities := make([]internal.Availability, len(rows))
+		for i, row := range rows {
+			availabilities[i], err = toAvailability(row.Availability, row.Timezone, row.LimitedThreshold, openingHours[row.Availability.ID])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return availabilities, nil
 	}
 }
 
-func (p Postgres) CreateBooking(ctx context.Context, params service.CreateBookingParams) (int, error) {
-	q := queries.CreateBookingParams{
-		ProductID:      int32(params.ProductID),
-		AvailabilityID: int32(params.AvailabilityID),
-		Units:          int32(params.Units),
-		UserID:         int32(params.UserID),
-	}
-	id, err := queries.New(p.db).CreateBooking(ctx, q)
+func (p Postgres) AvailabilityCalendar(ctx context.Context, productID int, localDateStart, localDateEnd time.Time, capability internal.CapabilityRequest, currency string) ([]internal.AvailabilityCalendar, error) {
+	currency, err := p.currency(ctx, queries.New(p.db), int32(productID), currency)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return 0, service.ErrNotAvailable
+		if errors.Is(err, service.ErrNotFound) { // no dates for unknown products
+			return []internal.AvailabilityCalendar{}, nil
 		}
-		return 0, fmt.Errorf("create booking: %w", err)
+		return nil, err
 	}
 
-	return int(id), nil
-}
-
-func (p Postgres) ConfirmBooking(ctx context.Context, id int, userID int) error {
-	params := queries.BookingForUpdateParams{
-		ID:     int64(id),
-		UserID: int32(userID),
-	}
-	return p.withTx(ctx, func(tx *sql.Tx) error {
-		qrs := queries.New(tx)
-		bookingWithUnits, err := qrs.BookingForUpdate(ctx, params)
+	switch capability {
+	case internal.CapabilityRequestPrice:
+		rows, err := queries.New(p.db).AvailabilityCalendarWithPrice(ctx, queries.AvailabilityCalendarWithPriceParams{
+			ProductID:      int32(productID),
+			LocalDateStart: localDateStart,
+			LocalDateEnd:   localDateEnd,
+			Currency:       currency,
+		})
 		if err != nil {
-			return fmt.Errorf("get booking for update: %w", err)
-		}
-		if len(bookingWithUnits) == 0 {
-			return service.ErrNotFound
-		}
-
-		if bookingWithUnits[0].Status == internal.BookingStatusConfirmed { // already confirmed
-			return nil
-		}
-
-		if err := qrs.ConfirmBooking(ctx, int64(id)); err != nil {
-			return fmt.Errorf("confirm booking: %w", err)
+			return nil, fmt.Errorf("get availability calendar with price: %w", err)
 		}
 
-		for _, row := range bookingWithUnits {
-			if err := p.createTicket(ctx, tx, row.UnitID); err != nil {
-				return fmt.Errorf("create ticket: %w", err)
+		return mapp(rows, func(row queries.AvailabilityCalendarWithPriceRow) internal.AvailabilityCalendar {
+			return internal.AvailabilityCalendarWithPrice{
+				AvailabilityCalendarBase: toAvailabilityCalendar(row.LocalDate, row.Status, row.Vacancies),
+				CapabilityPrice:          toPrice(row.Price),
 			}
+		}), nil
+	default:
+		rows, err := queries.New(p.db).AvailabilityCalendar(ctx, queries.AvailabilityCalendarParams{
+			ProductID:      int32(productID),
+			LocalDateStart: localDateStart,
+			LocalDateEnd:   localDateEnd,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get availability calendar: %w", err)
 		}
 
-		return nil
-	})
+		return mapp(rows, func(row queries.AvailabilityCalendarRow) internal.AvailabilityCalendar {
+			return toAvailabilityCalendar(row.LocalDate, row.Status, row.Vacancies)
+		}), nil
+	}
 }
 
-func (p Postgres) createTicket(ctx context.Context, tx *sql.Tx, unitID int64) error {
-	ticket := platform.RandString()
-	params := queries.SetUnitTicketParams{
-		ID: unitID,
-		Ticket: sql.NullString{
-			String: ticket,
-			Valid:  true,
-		},
+// openingHours returns opening hours of the availabilities by availability ID.
+func (p Postgres) openingHours(ctx context.Context, availabilityIDs []int32) (map[int32][]internal.OpeningHours, error) {
+	rows, err := queries.New(p.db).OpeningHours(ctx, availabilityIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get opening hours: %w", err)
 	}
-	if err := queries.New(tx).SetUnitTicket(ctx, params); err != nil {
-		return fmt.Errorf("set unit ticket: %w", err)
+
+	openingHours := make(map[int32][]internal.OpeningHours)
+	for _, row := range rows {
+		openingHours[row.AvailabilityID] = append(openingHours[row.AvailabilityID], toOpeningHours(row))
 	}
 
-	return nil
+	return openingHours, nil
 }
 
-func (p Postgres) Booking(ctx context.Context, id int, userID int, capability internal.CapabilityRequest) (internal.Booking, error) {
-	switch capability {
-	case internal.CapabilityRequestPrice:
-		params := queries.BookingWithPriceParams{
-			ID:     int64(id),
-			UserID: int32(userID),
+func (p Postgres) CreateBooking(ctx context.Context, params service.CreateBookingParams) (int, error) {
+	q := queries.CreateBookingParams{
+		ProductID:      int32(params.ProductID),
+		OptionCode:     stringToNullString(params.OptionID),
+		AvailabilityID: int32(params.AvailabilityID),
+		UserID:         int32(params.UserID),
+		ExpiresAt:      params.ExpiresAt,
+		Uuid: uuid.NullUUID{
+			UUID:  params.UUID,
+			Valid: params.UUID != uuid.Nil,
+		},
+		ResellerReference: stringToNullString(params.ResellerReference),
+	}
+	var id int64
+	err := p.withTx(ctx, func(tx *sql.Tx) error {
+		qrs := queries.New(tx)
+		var err error
+		q.UnitTypeIds, err = p.unitTypeIDs(ctx, qrs, int32(params.ProductID), params.Units, params.UnitIDs)
+		if err != nil {
+			return err
 		}
-		bookingsWithPrice, err := queries.New(p.db).BookingWithPrice(ctx, params)
+		q.Units = int32(len(q.UnitTypeIds))
+		q.Currency, err = p.currency(ctx, qrs, int32(params.ProductID), params.Currency)
 		if err != nil {
-			return nil, fmt.Errorf("get booking with price: %w", err)
+			if errors.Is(err, service.ErrNotFound) {
+				return service.ErrNotAvailable
+			}
+			return err
 		}
-
-		if len(bookingsWithPrice) == 0 {
-			return nil, service.ErrNotFound
+		q.PriceIds, err = p.unitPriceIDs(ctx, qrs, int32(params.ProductID), int32(params.AvailabilityID), q.Currency, q.UnitTypeIds)
+		if err != nil {
+			return err
 		}
-
-		return toBookingsWithPrice(bookingsWithPrice)[0], nil
-	default:
-		params := queries.BookingParams{
-			ID:     int64(id),
-			UserID: int32(userID),
+		if params.PromoCode != "" {
+			promoCode, err := p.promoCode(ctx, qrs, params.PromoCode, int32(params.ProductID), q.Currency, time.Now())
+			if err != nil {
+				return err
+			}
+			// the booking keeps the discount, the promo code can change later
+			q.PromoCodeID = sql.NullInt32{Int32: promoCode.ID, Valid: true}
+			q.DiscountType = queries.NullDiscountType{DiscountType: promoCode.DiscountType, Valid: true}
+			q.DiscountAmount = sql.NullInt32{Int32: promoCode.Amount, Valid: true}
 		}
-		bookings, err := queries.New(p.db).Booking(ctx, params)
+
+		id, err = qrs.CreateBooking(ctx, q)
 		if err != nil {
-			return nil, fmt.Errorf("get booking: %w", err)
+			if errors.Is(err, sql.ErrNoRows) {
+				return service.ErrNotAvailable
+			}
+			if isUniqueViolation(err) {
+				return service.ErrAlreadyExists
+			}
+			return fmt.Errorf("create booking: %w", err)
 		}
 
-		if len(bookings) == 0 {
-			return nil, service.ErrNotFound
+		if q.PromoCodeID.Valid {
+			if err := qrs.UsePromoCode(ctx, q.PromoCodeID.Int32); err != nil {
+				return fmt.Errorf("use promo code: %w", err)
+			}
 		}
 
-		return toBookings(bookings)[0], nil
-	}
-}
+		if params.Contact != nil {
+			if err := qrs.UpsertContact(ctx, toUpsertContactParams(id, *params.Contact)); err != nil {
+				return fmt.Errorf("upsert contact: %w", err)
+			}
+		}
 
-func (p Postgres) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
-	tx, err := p.db.BeginTx(ctx, nil)
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("begin database transaction: %w", err)
+		return 0, err
 	}
 
-	if err := fn(tx); err != nil {
-		if rollbackErr := tx.Rollback(); rollbackErr != nil {
-			return fmt.Errorf("rollback database transaction after %w: %w", err, rollbackErr)
-		}
+	return int(id), nil
+}
 
-		return err
+// promoCode returns the promo code to apply to a booking of the product in the currency, locked until the transaction ends.
+// It returns internal.InvalidPromoCodeError if the promo code does not exist or cannot be applied at the time.
+func (p Postgres) promoCode(ctx context.Context, qrs *queries.Queries, code string, productID int32, currency string, now time.Time) (queries.PromoCode, error) {
+	promoCode, err := qrs.PromoCodeForUpdate(ctx, code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return queries.PromoCode{}, internal.InvalidPromoCodeError{Code: code, Reason: "unknown"}
+		}
+		return queries.PromoCode{}, fmt.Errorf("get promo code for update: %w", err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit database transaction: %w", err)
+	var reason string
+	switch {
+	case promoCode.ValidFrom.Valid && now.Before(promoCode.ValidFrom.Time):
+		reason = "not valid yet"
+	case promoCode.ValidUntil.Valid && !now.Before(promoCode.ValidUntil.Time):
+		reason = "expired"
+	case promoCode.MaxUses.Valid && promoCode.Uses >= promoCode.MaxUses.Int32:
+		reason = "used up"
+	case promoCode.ProductIds != nil && !slices.Contains(promoCode.ProductIds, productID):
+		reason = "not valid for the product"
+	case promoCode.Currency.Valid && promoCode.Currency.String != currency:
+		reason = "not valid for " + currency
+	default:
+		return promoCode, nil
 	}
 
-	return nil
+	return queries.PromoCode{}, internal.InvalidPromoCodeError{Code: code, Reason: reason}
 }
 
-func toProductWithPrice(product queries.Product, price queries.Price) internal.ProductWithPrice {
-	return internal.ProductWithPrice{
-		ProductBase:     toProduct(product),
-		CapabilityPrice: toPrice(price),
+// unitPriceIDs returns the ID of the price of each unit on the availability by unit type, zero for units without type.
+// Units without a price in the currency, e.g. of products without prices, get zero and are free.
+func (p Postgres) unitPriceIDs(ctx context.Context, qrs *queries.Queries, productID, availabilityID int32, currency string, unitTypeIDs []int32) ([]int64, error) {
+	params := queries.UnitPriceIDsParams{
+		UnitTypeIds:    unitTypeIDs,
+		AvailabilityID: availabilityID,
+		ProductID:      productID,
+		Currency:       currency,
+	}
+	rows, err := qrs.UnitPriceIDs(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("get unit prices: %w", err)
 	}
-}
 
-func toProduct(p queries.Product) internal.ProductBase {
-	return internal.ProductBase{
-		ID:       strconv.Itoa(int(p.ID)),
-		Name:     p.Name,
-		Capacity: int(p.Capacity),
+	byUnitType := make(map[int32]int64, len(rows))
+	for _, row := range rows {
+		byUnitType[row.UnitTypeID] = row.PriceID
 	}
-}
 
-func toAvailability(a queries.Availability) internal.AvailabilityBase {
-	return internal.AvailabilityBase{
-		ID:        strconv.Itoa(int(a.ID)),
-		LocalDate: internal.Date(a.LocalDate),
-		Status:    toAvailabilityStatus(int(a.Vacancies)),
-		Vacancies: int(a.Vacancies),
-		Available: a.Vacancies > 0,
+	priceIDs := make([]int64, len(unitTypeIDs))
+	for i, unitTypeID := range unitTypeIDs {
+		priceIDs[i] = byUnitType[unitTypeID]
 	}
+
+	return priceIDs, nil
 }
 
-func toAvailabilityStatus(vacancies int) internal.AvailabilityStatus {
-	if vacancies > 0 {
-		return internal.AvailabilityStatusAvailable
+// unitTypeIDs resolves unit type of each unit, zero for units without type.
+// It returns service.ErrUnknownUnit if a unit type does not exist for the product.
+func (p Postgres) unitTypeIDs(ctx context.Context, qrs *queries.Queries, productID int32, units int, codes []string) ([]int32, error) {
+	if len(codes) == 0 {
+		return make([]int32, units), nil
 	}
-	return internal.AvailabilityStatusSoldOut
-}
 
-func toAvailabilityWithPrice(a queries.Availability, p queries.Price) internal.AvailabilityWithPrice {
-	return internal.AvailabilityWithPrice{
-		AvailabilityBase: toAvailability(a),
-		CapabilityPrice:  toPrice(p),
+	params := queries.UnitTypeIDsParams{
+		ProductID: productID,
+		Codes:     codes,
+	}
+	rows, err := qrs.UnitTypeIDs(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("get unit type IDs: %w", err)
+	}
+
+	byCode := make(map[string]int32, len(rows))
+	for _, row := range rows {
+		byCode[row.Code] = row.ID
+	}
+
+	ids := make([]int32, len(codes))
+	for i, code := range codes {
+		id, ok := byCode[code]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", service.ErrUnknownUnit, code)
+		}
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+func (p Postgres) ConfirmBooking(ctx context.Context, params service.ConfirmBookingParams) error {
+	bookingParams := queries.BookingForUpdateParams{
+		ID:     int64(params.ID),
+		UserID: int32(params.UserID),
+	}
+	return p.withTx(ctx, func(tx *sql.Tx) error {
+		qrs := queries.New(tx)
+		bookingWithUnits, err := qrs.BookingForUpdate(ctx, bookingParams)
+		if err != nil {
+			return fmt.Errorf("get booking for update: %w", err)
+		}
+		if len(bookingWithUnits) == 0 {
+			return service.ErrNotFound
+		}
+
+		booking := bookingWithUnits[0]
+		if booking.Status == internal.BookingStatusConfirmed { // already confirmed
+			return nil
+		}
+		if booking.Status != internal.BookingStatusReserved {
+			return internal.BookingStatusError{Status: booking.Status}
+		}
+		if isExpired(booking.ExpiresAt, time.Now()) { // not yet picked up by the reaper
+			return internal.BookingStatusError{Status: internal.BookingStatusExpired}
+		}
+
+		if params.Contact != nil {
+			if err := qrs.UpsertContact(ctx, toUpsertContactParams(int64(params.ID), *params.Contact)); err != nil {
+				return fmt.Errorf("upsert contact: %w", err)
+			}
+		}
+
+		contactParams := queries.ConfirmationContactParams{
+			BookingID: int64(params.ID),
+			ProductID: booking.ProductID,
+		}
+		contact, err := qrs.ConfirmationContact(ctx, contactParams)
+		if err != nil {
+			return fmt.Errorf("get confirmation contact: %w", err)
+		}
+		stored := internal.Contact{
+			FullName:     nullStringToPtr(contact.FullName),
+			EmailAddress: nullStringToPtr(contact.EmailAddress),
+		}
+		if contact.ContactRequiredToConfirm && !stored.Complete() {
+			return service.ErrContactRequired
+		}
+
+		if err := qrs.ConfirmBooking(ctx, int64(params.ID)); err != nil {
+			return fmt.Errorf("confirm booking: %w", err)
+		}
+
+		if slices.Contains(booking.DeliveryMethods, internal.DeliveryMethodTicket) {
+			for _, row := range bookingWithUnits {
+				claims := ticket.Claims{
+					BookingID: int64(params.ID),
+					UnitID:    row.UnitID,
+					LocalDate: row.LocalDate,
+				}
+				if err := p.createTicket(ctx, tx, claims); err != nil {
+					return fmt.Errorf("create ticket: %w", err)
+				}
+			}
+		}
+		if slices.Contains(booking.DeliveryMethods, internal.DeliveryMethodVoucher) {
+			voucherParams := queries.SetBookingVoucherParams{
+				ID: int64(params.ID),
+				Voucher: sql.NullString{
+					String: p.signer.Sign(ticket.Claims{
+						BookingID: int64(params.ID),
+						LocalDate: booking.LocalDate,
+					}),
+					Valid: true,
+				},
+			}
+			if err := qrs.SetBookingVoucher(ctx, voucherParams); err != nil {
+				return fmt.Errorf("set booking voucher: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (p Postgres) UpdateBooking(ctx context.Context, params service.UpdateBookingParams) error {
+	bookingParams := queries.BookingForUpdateParams{
+		ID:     int64(params.ID),
+		UserID: int32(params.UserID),
+	}
+	return p.withTx(ctx, func(tx *sql.Tx) error {
+		qrs := queries.New(tx)
+		bookingWithUnits, err := qrs.BookingForUpdate(ctx, bookingParams)
+		if err != nil {
+			return fmt.Errorf("get booking for update: %w", err)
+		}
+		if len(bookingWithUnits) == 0 {
+			return service.ErrNotFound
+		}
+
+		booking := bookingWithUnits[0]
+		if booking.Status != internal.BookingStatusReserved {
+			return internal.BookingStatusError{Status: booking.Status}
+		}
+		if isExpired(booking.ExpiresAt, time.Now()) { // not yet picked up by the reaper
+			return internal.BookingStatusError{Status: internal.BookingStatusExpired}
+		}
+
+		availabilityID := booking.AvailabilityID
+		if params.AvailabilityID != 0 {
+			availabilityID = int32(params.AvailabilityID)
+		}
+		unitTypeIDs := mapp(bookingWithUnits, func(row queries.BookingForUpdateRow) int32 { return row.UnitTypeID.Int32 })
+		switch {
+		case len(params.UnitIDs) > 0:
+			unitTypeIDs, err = p.unitTypeIDs(ctx, qrs, booking.ProductID, len(params.UnitIDs), params.UnitIDs)
+			if err != nil {
+				return err
+			}
+		case params.Units != 0:
+			// a number of units does not tell which types to add or remove
+			if slices.ContainsFunc(unitTypeIDs, func(id int32) bool { return id != 0 }) {
+				return service.ErrUnitItemsRequired
+			}
+			unitTypeIDs = make([]int32, params.Units)
+		}
+		units := len(unitTypeIDs)
+
+		// the same lock CreateBooking takes when reserving vacancies, before reading or changing them
+		if err := qrs.LockAvailabilities(ctx, []int32{booking.AvailabilityID, availabilityID}); err != nil {
+			return fmt.Errorf("lock availabilities: %w", err)
+		}
+		if availabilityID == booking.AvailabilityID {
+			// same availability, only reserve or release the difference
+			if err := p.adjustVacancies(ctx, qrs, booking.ProductID, booking.OptionID, availabilityID, units-len(bookingWithUnits)); err != nil {
+				return err
+			}
+		} else {
+			if err := p.adjustVacancies(ctx, qrs, booking.ProductID, booking.OptionID, booking.AvailabilityID, -len(bookingWithUnits)); err != nil {
+				return err
+			}
+			if err := p.adjustVacancies(ctx, qrs, booking.ProductID, booking.OptionID, availabilityID, units); err != nil {
+				return err
+			}
+
+			setParams := queries.SetBookingAvailabilityParams{
+				ID:             int64(params.ID),
+				AvailabilityID: availabilityID,
+			}
+			if err := qrs.SetBookingAvailability(ctx, setParams); err != nil {
+				return fmt.Errorf("set booking availability: %w", err)
+			}
+		}
+		if availabilityID != booking.AvailabilityID || units < len(bookingWithUnits) {
+			// released vacancies go to the waitlist before any other booking can take them
+			if _, err := p.reserveWaitlisted(ctx, qrs, booking.AvailabilityID, params.WaitlistExpiresAt); err != nil {
+				return err
+			}
+		}
+
+		kept, removed, added := diffUnits(bookingWithUnits, unitTypeIDs)
+		if len(removed) > 0 {
+			if err := qrs.RemoveUnits(ctx, removed); err != nil {
+				return fmt.Errorf("remove units: %w", err)
+			}
+		}
+		if availabilityID != booking.AvailabilityID && len(kept) > 0 {
+			// units are reserved anew, for the price of the new availability
+			keptTypeIDs := mapp(kept, func(row queries.BookingForUpdateRow) int32 { return row.UnitTypeID.Int32 })
+			priceIDs, err := p.unitPriceIDs(ctx, qrs, booking.ProductID, availabilityID, booking.Currency, keptTypeIDs)
+			if err != nil {
+				return err
+			}
+			pricesParams := queries.SetUnitPricesParams{
+				Ids:      mapp(kept, func(row queries.BookingForUpdateRow) int64 { return row.UnitID }),
+				PriceIds: priceIDs,
+			}
+			if err := qrs.SetUnitPrices(ctx, pricesParams); err != nil {
+				return fmt.Errorf("set unit prices: %w", err)
+			}
+		}
+		if len(added) > 0 {
+			priceIDs, err := p.unitPriceIDs(ctx, qrs, booking.ProductID, availabilityID, booking.Currency, added)
+			if err != nil {
+				return err
+			}
+			addParams := queries.AddUnitsParams{
+				BookingID:   int64(params.ID),
+				UnitTypeIds: added,
+				PriceIds:    priceIDs,
+			}
+			if err := qrs.AddUnits(ctx, addParams); err != nil {
+				return fmt.Errorf("add units: %w", err)
+			}
+		}
+
+		if params.Contact != nil {
+			if err := qrs.UpsertContact(ctx, toUpsertContactParams(int64(params.ID), *params.Contact)); err != nil {
+				return fmt.Errorf("upsert contact: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// diffUnits matches units of the booking against the wanted unit type of each unit, zero for units without type.
+// It keeps the first units of each type, returns IDs of the units to remove and unit types of the units to add.
+func diffUnits(bookingWithUnits []queries.BookingForUpdateRow, unitTypeIDs []int32) (kept []queries.BookingForUpdateRow, removed []int64, added []int32) {
+	wanted := make(map[int32]int)
+	for _, id := range unitTypeIDs {
+		wanted[id]++
+	}
+
+	for _, row := range bookingWithUnits {
+		if wanted[row.UnitTypeID.Int32] > 0 {
+			wanted[row.UnitTypeID.Int32]--
+			kept = append(kept, row)
+			continue
+		}
+		removed = append(removed, row.UnitID)
+	}
+	for _, id := range unitTypeIDs {
+		if wanted[id] > 0 {
+			wanted[id]--
+			added = append(added, id)
+		}
+	}
+
+	return kept, removed, added
+}
+
+// adjustVacancies reserves units on the availability if units is positive or releases them if negative.
+// It returns service.ErrNotAvailable if there are not enough vacancies to reserve
+// or the availability does not belong to the product option.
+func (p Postgres) adjustVacancies(ctx context.Context, qrs *queries.Queries, productID, optionID, availabilityID int32, units int) error {
+	switch {
+	case units > 0:
+		reserveParams := queries.ReserveVacanciesParams{
+			ID:        availabilityID,
+			ProductID: productID,
+			OptionID:  optionID,
+			Units:     int32(units),
+		}
+		reserved, err := qrs.ReserveVacancies(ctx, reserveParams)
+		if err != nil {
+			return fmt.Errorf("reserve vacancies: %w", err)
+		}
+		if reserved == 0 {
+			return service.ErrNotAvailable
+		}
+	case units < 0:
+		releaseParams := queries.ReleaseVacanciesParams{
+			ID:    availabilityID,
+			Units: int32(-units),
+		}
+		if err := qrs.ReleaseVacancies(ctx, releaseParams); err != nil {
+			return fmt.Errorf("release vacancies: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (p Postgres) ExtendBooking(ctx context.Context, params service.ExtendBookingParams) error {
+	holdParams := queries.BookingHoldForUpdateParams{
+		ID:     int64(params.ID),
+		UserID: int32(params.UserID),
+	}
+	return p.withTx(ctx, func(tx *sql.Tx) error {
+		qrs := queries.New(tx)
+		booking, err := qrs.BookingHoldForUpdate(ctx, holdParams)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return service.ErrNotFound
+			}
+			return fmt.Errorf("get booking hold for update: %w", err)
+		}
+
+		if booking.Status != internal.BookingStatusReserved {
+			return internal.BookingStatusError{Status: booking.Status}
+		}
+		if isExpired(booking.ExpiresAt, time.Now()) { // not yet picked up by the reaper
+			return internal.BookingStatusError{Status: internal.BookingStatusExpired}
+		}
+
+		maxHold := time.Duration(booking.MaxReservationHoldMinutes) * time.Minute
+		expiresAt := params.ExpiresAt
+		if maxExpiresAt := booking.CreatedAt.Time.Add(maxHold); expiresAt.After(maxExpiresAt) {
+			expiresAt = maxExpiresAt
+		}
+		if !expiresAt.After(booking.ExpiresAt.Time) { // only move expiry forward
+			return nil
+		}
+
+		extendParams := queries.ExtendBookingParams{
+			ID:        int64(params.ID),
+			ExpiresAt: expiresAt,
+		}
+		if err := qrs.ExtendBooking(ctx, extendParams); err != nil {
+			return fmt.Errorf("extend booking: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (p Postgres) CancelBooking(ctx context.Context, id int, userID int, reason string, waitlistExpiresAt time.Time) error {
+	params := queries.BookingForUpdateParams{
+		ID:     int64(id),
+		UserID: int32(userID),
+	}
+	return p.withTx(ctx, func(tx *sql.Tx) error {
+		qrs := queries.New(tx)
+		bookingWithUnits, err := qrs.BookingForUpdate(ctx, params)
+		if err != nil {
+			return fmt.Errorf("get booking for update: %w", err)
+		}
+		if len(bookingWithUnits) == 0 {
+			return service.ErrNotFound
+		}
+
+		booking := bookingWithUnits[0]
+		if booking.Status == internal.BookingStatusCancelled { // already cancelled
+			return nil
+		}
+		if booking.Status == internal.BookingStatusExpired { // vacancies are already released
+			return internal.BookingStatusError{Status: booking.Status}
+		}
+
+		cancelParams := queries.CancelBookingParams{
+			ID:     int64(id),
+			Reason: reason,
+		}
+		if err := qrs.CancelBooking(ctx, cancelParams); err != nil {
+			return fmt.Errorf("cancel booking: %w", err)
+		}
+
+		releaseParams := queries.ReleaseVacanciesParams{
+			ID:    booking.AvailabilityID,
+			Units: int32(len(bookingWithUnits)),
+		}
+		if err := qrs.ReleaseVacancies(ctx, releaseParams); err != nil {
+			return fmt.Errorf("release vacancies: %w", err)
+		}
+		// released vacancies go to the waitlist before any other booking can take them
+		if _, err := p.reserveWaitlisted(ctx, qrs, booking.AvailabilityID, waitlistExpiresAt); err != nil {
+			return err
+		}
+
+		if err := qrs.InvalidateTickets(ctx, int64(id)); err != nil {
+			return fmt.Errorf("invalidate tickets: %w", err)
+		}
+		if err := releasePromoCode(ctx, qrs, booking.PromoCodeID); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (p Postgres) ExpireBookings(ctx context.Context, now, waitlistExpiresAt time.Time) (int, error) {
+	var expired int
+	err := p.withTx(ctx, func(tx *sql.Tx) error {
+		qrs := queries.New(tx)
+		bookings, err := qrs.ExpiredBookingsForUpdate(ctx, now)
+		if err != nil {
+			return fmt.Errorf("get expired bookings for update: %w", err)
+		}
+
+		for _, booking := range bookings {
+			if err := qrs.ExpireBooking(ctx, booking.ID); err != nil {
+				return fmt.Errorf("expire booking: %w", err)
+			}
+
+			releaseParams := queries.ReleaseVacanciesParams{
+				ID:    booking.AvailabilityID,
+				Units: int32(booking.Units),
+			}
+			if err := qrs.ReleaseVacancies(ctx, releaseParams); err != nil {
+				return fmt.Errorf("release vacancies: %w", err)
+			}
+			// released vacancies go to the waitlist before any other booking can take them
+			if _, err := p.reserveWaitlisted(ctx, qrs, booking.AvailabilityID, waitlistExpiresAt); err != nil {
+				return err
+			}
+			if err := releasePromoCode(ctx, qrs, booking.PromoCodeID); err != nil {
+				return err
+			}
+		}
+
+		expired = len(bookings)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return expired, nil
+}
+
+// releasePromoCode gives back the use of the promo code the booking was reserved with, if any.
+func releasePromoCode(ctx context.Context, qrs *queries.Queries, promoCodeID sql.NullInt32) error {
+	if !promoCodeID.Valid {
+		return nil
+	}
+	if err := qrs.ReleasePromoCode(ctx, promoCodeID.Int32); err != nil {
+		return fmt.Errorf("release promo code: %w", err)
+	}
+
+	return nil
+}
+
+func isExpired(expiresAt sql.NullTime, now time.Time) bool {
+	return expiresAt.Valid && !expiresAt.Time.After(now)
+}
+
+func (p Postgres) createTicket(ctx context.Context, tx *sql.Tx, claims ticket.Claims) error {
+	params := queries.SetUnitTicketParams{
+		ID: claims.UnitID,
+		Ticket: sql.NullString{
+			String: p.signer.Sign(claims),
+			Valid:  true,
+		},
+		TicketReference: sql.NullString{
+			String: ticket.Reference(),
+			Valid:  true,
+		},
+	}
+	if err := queries.New(tx).SetUnitTicket(ctx, params); err != nil {
+		return fmt.Errorf("set unit ticket: %w", err)
+	}
+
+	return nil
+}
+
+func (p Postgres) Booking(ctx context.Context, id int, userID int, capability internal.CapabilityRequest) (internal.Booking, error) {
+	bookings, err := p.bookings(ctx, []int64{int64(id)}, userID, capability)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bookings) == 0 {
+		return nil, service.ErrNotFound
+	}
+
+	return bookings[0], nil
+}
+
+func (p Postgres) Bookings(ctx context.Context, filter internal.BookingsFilter, capability internal.CapabilityRequest) (internal.BookingsPage, error) {
+	params := queries.BookingIDsParams{
+		UserID: int32(filter.UserID),
+		After:  int64(filter.Cursor),
+		Status: queries.NullBookingStatus{
+			BookingStatus: queries.BookingStatus(filter.Status),
+			Valid:         filter.Status != "",
+		},
+		ProductID: sql.NullInt32{
+			Int32: int32(filter.ProductID),
+			Valid: filter.ProductID != 0,
+		},
+		LocalDateStart: timeToNullTime(filter.LocalDateStart),
+		LocalDateEnd:   timeToNullTime(filter.LocalDateEnd),
+		CreatedAtStart: timeToNullTime(filter.CreatedAtStart),
+		CreatedAtEnd:   timeToNullTime(filter.CreatedAtEnd),
+		Uuid: uuid.NullUUID{
+			UUID:  filter.UUID,
+			Valid: filter.UUID != uuid.Nil,
+		},
+		ResellerReference: stringToNullString(filter.ResellerReference),
+		MaxResults:        int32(filter.Limit + 1), // one more to know if there is a next page
+	}
+	ids, err := queries.New(p.db).BookingIDs(ctx, params)
+	if err != nil {
+		return internal.BookingsPage{}, fmt.Errorf("get booking IDs: %w", err)
+	}
+
+	var page internal.BookingsPage
+	if len(ids) > filter.Limit {
+		ids = ids[:filter.Limit]
+		page.NextCursor = int(ids[len(ids)-1])
+	}
+
+	page.Bookings, err = p.bookings(ctx, ids, filter.UserID, capability)
+	if err != nil {
+		return internal.BookingsPage{}, err
+	}
+
+	return page, nil
+}
+
+// bookings returns user's bookings by IDs, ordered by ID.
+func (p Postgres) bookings(ctx context.Context, ids []int64, userID int, capability internal.CapabilityRequest) ([]internal.Booking, error) {
+	contacts, err := p.contacts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	params := queries.BookingsParams{
+		Ids:    ids,
+		UserID: int32(userID),
+	}
+	bookings, err := queries.New(p.db).Bookings(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("get bookings: %w", err)
+	}
+
+	switch capability {
+	case internal.CapabilityRequestPrice:
+		return mapp(
+			toBookingsWithPrice(bookings, contacts),
+			func(b internal.BookingWithPrice) internal.Booking {
+				return b
+			},
+		), nil
+	default:
+		return mapp(
+			toBookings(bookings, contacts),
+			func(b internal.BookingBase) internal.Booking {
+				return b
+			},
+		), nil
+	}
+}
+
+// contacts returns contacts of the bookings by booking ID.
+func (p Postgres) contacts(ctx context.Context, bookingIDs []int64) (map[int64]queries.Contact, error) {
+	rows, err := queries.New(p.db).Contacts(ctx, bookingIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get contacts: %w", err)
+	}
+
+	contacts := make(map[int64]queries.Contact, len(rows))
+	for _, row := range rows {
+		contacts[row.BookingID] = row
+	}
+
+	return contacts, nil
+}
+
+func (p Postgres) UnitTicket(ctx context.Context, bookingID, unitID, userID int) (internal.UnitTicket, error) {
+	params := queries.UnitTicketParams{
+		ID:        int64(unitID),
+		BookingID: int64(bookingID),
+		UserID:    int32(userID),
+	}
+	row, err := queries.New(p.db).UnitTicket(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return internal.UnitTicket{}, service.ErrNotFound
+		}
+		return internal.UnitTicket{}, fmt.Errorf("get unit ticket: %w", err)
+	}
+
+	return internal.UnitTicket{
+		Ticket:         row.Ticket.String,
+		Reference:      row.TicketReference.String,
+		DeliveryFormat: row.DeliveryFormat,
+	}, nil
+}
+
+func (p Postgres) RedeemTicket(ctx context.Context, code string, userID int, now time.Time) (internal.Redemption, error) {
+	var redemption internal.Redemption
+	err := p.withTx(ctx, func(tx *sql.Tx) error {
+		qrs := queries.New(tx)
+		unit, err := qrs.TicketForUpdate(ctx, queries.TicketForUpdateParams{
+			Ticket: code,
+			UserID: int32(userID),
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				redemption, err = p.redeemVoucher(ctx, qrs, code, userID, now)
+				return err
+			}
+			return fmt.Errorf("get ticket for update: %w", err)
+		}
+
+		if unit.Status != internal.BookingStatusConfirmed {
+			return internal.BookingStatusError{Status: unit.Status}
+		}
+		if unit.RedeemedAt.Valid {
+			return internal.TicketRedeemedError{UTCRedeemedAt: unit.RedeemedAt.Time.UTC()}
+		}
+		if err := checkLocalDate(unit.LocalDate, unit.Timezone, now); err != nil {
+			return err
+		}
+
+		redeemParams := queries.RedeemUnitParams{
+			ID:         unit.UnitID,
+			RedeemedBy: int32(userID),
+		}
+		redeemedAt, err := qrs.RedeemUnit(ctx, redeemParams)
+		if err != nil {
+			return fmt.Errorf("redeem unit: %w", err)
+		}
+
+		redeemed := internal.UnitBase{
+			ID:            strconv.Itoa(int(unit.UnitID)),
+			UnitID:        nullStringToPtr(unit.UnitTypeCode),
+			Ticket:        nullStringToPtr(unit.Ticket), // the code may be the reference
+			UTCRedeemedAt: nullTimeToUTCPtr(redeemedAt),
+		}
+		redemption = internal.Redemption{
+			BookingID:      strconv.Itoa(int(unit.BookingID)),
+			ProductID:      strconv.Itoa(int(unit.ProductID)),
+			AvailabilityID: strconv.Itoa(int(unit.AvailabilityID)),
+			Unit:           &redeemed,
+			Units:          []internal.UnitBase{redeemed},
+		}
+		return nil
+	})
+	if err != nil {
+		return internal.Redemption{}, err
+	}
+
+	return redemption, nil
+}
+
+// redeemVoucher redeems the units of the booking with the voucher which are not redeemed yet,
+// e.g. with their tickets.
+func (p Postgres) redeemVoucher(ctx context.Context, qrs *queries.Queries, code string, userID int, now time.Time) (internal.Redemption, error) {
+	units, err := qrs.VoucherForUpdate(ctx, queries.VoucherForUpdateParams{
+		Voucher: code,
+		UserID:  int32(userID),
+	})
+	if err != nil {
+		return internal.Redemption{}, fmt.Errorf("get voucher for update: %w", err)
+	}
+	if len(units) == 0 {
+		return internal.Redemption{}, service.ErrNotFound
+	}
+
+	booking := units[0]
+	if booking.Status != internal.BookingStatusConfirmed {
+		return internal.Redemption{}, internal.BookingStatusError{Status: booking.Status}
+	}
+	unredeemed := slices.DeleteFunc(slices.Clone(units), func(unit queries.VoucherForUpdateRow) bool { return unit.RedeemedAt.Valid })
+	if len(unredeemed) == 0 {
+		lastRedeemed := slices.MaxFunc(units, func(a, b queries.VoucherForUpdateRow) int { return a.RedeemedAt.Time.Compare(b.RedeemedAt.Time) })
+		return internal.Redemption{}, internal.TicketRedeemedError{UTCRedeemedAt: lastRedeemed.RedeemedAt.Time.UTC()}
+	}
+	if err := checkLocalDate(booking.LocalDate, booking.Timezone, now); err != nil {
+		return internal.Redemption{}, err
+	}
+
+	redemption := internal.Redemption{
+		BookingID:      strconv.Itoa(int(booking.BookingID)),
+		ProductID:      strconv.Itoa(int(booking.ProductID)),
+		AvailabilityID: strconv.Itoa(int(booking.AvailabilityID)),
+	}
+	for _, unit := range unredeemed {
+		redeemParams := queries.RedeemUnitParams{
+			ID:         unit.UnitID,
+			RedeemedBy: int32(userID),
+		}
+		redeemedAt, err := qrs.RedeemUnit(ctx, redeemParams)
+		if err != nil {
+			return internal.Redemption{}, fmt.Errorf("redeem unit: %w", err)
+		}
+		redemption.Units = append(redemption.Units, internal.UnitBase{
+			ID:            strconv.Itoa(int(unit.UnitID)),
+			UnitID:        nullStringToPtr(unit.UnitTypeCode),
+			Ticket:        nullStringToPtr(unit.Ticket),
+			UTCRedeemedAt: nullTimeToUTCPtr(redeemedAt),
+		})
+	}
+
+	return redemption, nil
+}
+
+// checkLocalDate returns service.ErrWrongDate if now is not on the local date in the time zone.
+func checkLocalDate(localDate time.Time, timezone string, now time.Time) error {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return fmt.Errorf("load product time zone: %w", err)
+	}
+	if now.In(loc).Format(time.DateOnly) != localDate.Format(time.DateOnly) {
+		return service.ErrWrongDate
+	}
+
+	return nil
+}
+
+func (p Postgres) CreateWaitlistEntry(ctx context.Context, params service.CreateWaitlistEntryParams) (int, error) {
+	q := queries.CreateWaitlistEntryParams{
+		UserID:         int32(params.UserID),
+		AvailabilityID: int32(params.AvailabilityID),
+		WebhookUrl:     stringToNullString(params.WebhookURL),
+		OptionCode:     stringToNullString(params.OptionID),
+	}
+	var id int64
+	err := p.withTx(ctx, func(tx *sql.Tx) error {
+		qrs := queries.New(tx)
+		productID, err := qrs.AvailabilityProductID(ctx, int32(params.AvailabilityID))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return service.ErrNotFound
+			}
+			return fmt.Errorf("get availability product ID: %w", err)
+		}
+		q.Currency, err = p.currency(ctx, qrs, productID, params.Currency)
+		if err != nil {
+			return err
+		}
+		// unit types are resolved now, the reservation is made with them later
+		q.UnitTypeIds, err = p.unitTypeIDs(ctx, qrs, productID, params.Units, params.UnitIDs)
+		if err != nil {
+			return err
+		}
+
+		id, err = qrs.CreateWaitlistEntry(ctx, q)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return service.ErrNotFound
+			}
+			return fmt.Errorf("create waitlist entry: %w", err)
+		}
+
+		// the availability may have enough vacancies already
+		if _, err := p.reserveWaitlisted(ctx, qrs, int32(params.AvailabilityID), params.ExpiresAt); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
+}
+
+func (p Postgres) WaitlistEntry(ctx context.Context, id int, userID int) (internal.WaitlistEntry, error) {
+	params := queries.WaitlistEntryParams{
+		ID:     int64(id),
+		UserID: int32(userID),
+	}
+	entry, err := queries.New(p.db).WaitlistEntry(ctx, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return internal.WaitlistEntry{}, service.ErrNotFound
+		}
+		return internal.WaitlistEntry{}, fmt.Errorf("get waitlist entry: %w", err)
+	}
+
+	return toWaitlistEntry(entry.WaitlistEntry, entry.OptionCode), nil
+}
+
+func (p Postgres) ReserveWaitlisted(ctx context.Context, expiresAt time.Time) (int, error) {
+	var reserved int
+	err := p.withTx(ctx, func(tx *sql.Tx) error {
+		qrs := queries.New(tx)
+		availabilityIDs, err := qrs.WaitlistedAvailabilities(ctx)
+		if err != nil {
+			return fmt.Errorf("get waitlisted availabilities: %w", err)
+		}
+
+		for _, availabilityID := range availabilityIDs {
+			n, err := p.reserveWaitlisted(ctx, qrs, availabilityID, expiresAt)
+			if err != nil {
+				return err
+			}
+			reserved += n
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return reserved, nil
+}
+
+// reserveWaitlisted creates reservations for waiting entries of the availability, oldest first.
+// Units are reserved with the unit types of the entry, priced like in CreateBooking.
+// Entries which do not fit into the vacancies left keep waiting.
+// It returns the number of reserved entries.
+func (p Postgres) reserveWaitlisted(ctx context.Context, qrs *queries.Queries, availabilityID int32, expiresAt time.Time) (int, error) {
+	entries, err := qrs.WaitingEntriesForUpdate(ctx, availabilityID)
+	if err != nil {
+		return 0, fmt.Errorf("get waiting entries for update: %w", err)
+	}
+
+	var reserved int
+	for _, entry := range entries {
+		bookingParams := queries.CreateBookingParams{
+			Units:          int32(len(entry.UnitTypeIds)),
+			AvailabilityID: availabilityID,
+			ProductID:      entry.ProductID,
+			UserID:         entry.UserID,
+			ExpiresAt:      expiresAt,
+			UnitTypeIds:    entry.UnitTypeIds,
+			OptionCode:     sql.NullString{String: entry.OptionCode, Valid: true},
+			Currency:       entry.Currency, // as requested when joining
+		}
+		bookingParams.PriceIds, err = p.unitPriceIDs(ctx, qrs, entry.ProductID, availabilityID, entry.Currency, bookingParams.UnitTypeIds)
+		if err != nil {
+			return 0, err
+		}
+		bookingID, err := qrs.CreateBooking(ctx, bookingParams)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) { // not enough vacancies
+				continue
+			}
+			return 0, fmt.Errorf("create booking: %w", err)
+		}
+
+		reserveParams := queries.ReserveWaitlistEntryParams{
+			ID:        entry.ID,
+			BookingID: sql.NullInt64{Int64: bookingID, Valid: true},
+		}
+		if err := qrs.ReserveWaitlistEntry(ctx, reserveParams); err != nil {
+			return 0, fmt.Errorf("reserve waitlist entry: %w", err)
+		}
+		reserved++
+	}
+
+	return reserved, nil
+}
+
+func (p Postgres) UnnotifiedWaitlistEntries(ctx context.Context) ([]internal.WaitlistEntry, error) {
+	entries, err := queries.New(p.db).UnnotifiedWaitlistEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get unnotified waitlist entries: %w", err)
+	}
+
+	return mapp(entries, func(row queries.UnnotifiedWaitlistEntriesRow) internal.WaitlistEntry {
+		return toWaitlistEntry(row.WaitlistEntry, row.OptionCode)
+	}), nil
+}
+
+func (p Postgres) SetWaitlistEntryNotified(ctx context.Context, id int) error {
+	if err := queries.New(p.db).SetWaitlistEntryNotified(ctx, int64(id)); err != nil {
+		return fmt.Errorf("set waitlist entry notified: %w", err)
+	}
+
+	return nil
+}
+
+func (p Postgres) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin database transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("rollback database transaction after %w: %w", err, rollbackErr)
+		}
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit database transaction: %w", err)
+	}
+
+	return nil
+}
+
+func toProductWithPrice(product queries.Product, price queries.Price, options []internal.Option, unitTypes []internal.UnitType, currencies []string) internal.ProductWithPrice {
+	return internal.ProductWithPrice{
+		ProductBase:     toProduct(product, options, unitTypes, currencies),
+		CapabilityPrice: toPrice(price),
+	}
+}
+
+func toProduct(p queries.Product, options []internal.Option, unitTypes []internal.UnitType, currencies []string) internal.ProductBase {
+	if options == nil {
+		options = []internal.Option{}
+	}
+	if unitTypes == nil {
+		unitTypes = []internal.UnitType{}
+	}
+	if currencies == nil {
+		currencies = []string{}
+	}
+
+	return internal.ProductBase{
+		ID:                       strconv.Itoa(int(p.ID)),
+		Name:                     p.Name,
+		Capacity:                 int(p.Capacity),
+		ContactRequiredToConfirm: p.ContactRequiredToConfirm,
+		AvailabilityType:         p.AvailabilityType,
+		Timezone:                 p.Timezone,
+		DeliveryFormat:           p.DeliveryFormat,
+		DeliveryMethods:          p.DeliveryMethods,
+		DefaultCurrency:          p.DefaultCurrency,
+		AvailableCurrencies:      currencies,
+		Options:                  options,
+		Units:                    unitTypes,
+	}
+}
+
+func toOption(o queries.Option) internal.OptionBase {
+	return internal.OptionBase{
+		ID:       o.Code,
+		Name:     o.Name,
+		Capacity: int(o.Capacity),
+	}
+}
+
+func toUnitType(u queries.UnitType) internal.UnitTypeBase {
+	return internal.UnitTypeBase{
+		ID:   u.Code,
+		Name: u.Name,
+	}
+}
+
+// toAvailability converts an availability, placing its local date-times in the product's time zone.
+func toAvailability(a queries.Availability, timezone string, limitedThreshold int32, openingHours []internal.OpeningHours) (internal.AvailabilityBase, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return internal.AvailabilityBase{}, fmt.Errorf("load product time zone: %w", err)
+	}
+	if openingHours == nil {
+		openingHours = []internal.OpeningHours{}
+	}
+
+	status := toAvailabilityStatus(a, limitedThreshold)
+	return internal.AvailabilityBase{
+		ID:                 strconv.Itoa(int(a.ID)),
+		LocalDate:          internal.Date(a.LocalDate),
+		LocalDateTimeStart: inLocation(a.LocalDateTimeStart, loc),
+		LocalDateTimeEnd:   inLocation(a.LocalDateTimeEnd, loc),
+		AllDay:             a.AllDay,
+		Status:             status,
+		Capacity:           int(a.Capacity),
+		Vacancies:          int(a.Vacancies),
+		Utilization:        utilization(a.Capacity, a.Vacancies),
+		Available:          status.Available(),
+		OpeningHours:       openingHours,
+	}, nil
+}
+
+func toAvailabilityCalendar(localDate time.Time, status string, vacancies int32) internal.AvailabilityCalendarBase {
+	return internal.AvailabilityCalendarBase{
+		LocalDate: internal.Date(localDate),
+		Status:    internal.AvailabilityStatus(status),
+		Vacancies: int(vacancies),
+		Available: internal.AvailabilityStatus(status).Available(),
 	}
 }
 
-func toBookings(rows []queries.BookingRow) []internal.BookingBase {
+func toOpeningHours(o queries.OpeningHour) internal.OpeningHours {
+	return internal.OpeningHours{
+		From: o.TimeFrom.Format(timeFormat),
+		To:   o.TimeTo.Format(timeFormat),
+	}
+}
+
+// inLocation interprets wall clock time of t in loc.
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// utilization returns the share of capacity sold.
+func utilization(capacity, vacancies int32) float64 {
+	if capacity == 0 {
+		return 0
+	}
+	return float64(capacity-vacancies) / float64(capacity)
+}
+
+func toAvailabilityStatus(a queries.Availability, limitedThreshold int32) internal.AvailabilityStatus {
+	switch {
+	case a.Closed:
+		return internal.AvailabilityStatusClosed
+	case a.Freesale:
+		return internal.AvailabilityStatusFreesale
+	case a.Vacancies == 0:
+		return internal.AvailabilityStatusSoldOut
+	case a.Vacancies < limitedThreshold:
+		return internal.AvailabilityStatusLimited
+	default:
+		return internal.AvailabilityStatusAvailable
+	}
+}
+
+func toBookings(rows []queries.BookingsRow, contacts map[int64]queries.Contact) []internal.BookingBase {
+	var ids []int64 // keep bookings in the order of rows
 	bookings := make(map[int64]*internal.BookingBase)
 	for _, row := range rows {
 		if _, ok := bookings[row.Booking.ID]; !ok {
-			b := toBooking(row.Booking)
+			b := toBooking(row.Booking, row.OptionCode, contacts[row.Booking.ID])
 			bookings[row.Booking.ID] = &b
+			ids = append(ids, row.Booking.ID)
 		}
 
-		unit := toUnit(row.Unit)
+		unit := toUnit(row.Unit, row.UnitTypeCode)
 		units := bookings[row.Booking.ID].Units
 		bookings[row.Booking.ID].Units = append(units, unit)
 	}
 
 	result := make([]internal.BookingBase, 0, len(bookings))
-	for _, booking := range bookings {
-		result = append(result, *booking)
+	for _, id := range ids {
+		result = append(result, *bookings[id])
 	}
 
 	return result
 }
 
-func toBookingsWithPrice(rows []queries.BookingWithPriceRow) []internal.BookingWithPrice {
+// toBookingsWithPrice converts bookings with the prices their units were reserved for.
+func toBookingsWithPrice(rows []queries.BookingsRow, contacts map[int64]queries.Contact) []internal.BookingWithPrice {
+	var ids []int64 // keep bookings in the order of rows
 	bookings := make(map[int64]*internal.BookingWithPrice)
 	for _, row := range rows {
 		if _, ok := bookings[row.Booking.ID]; !ok {
-			b := toBooking(row.Booking)
+			b := toBooking(row.Booking, row.OptionCode, contacts[row.Booking.ID])
 			bookings[row.Booking.ID] = &internal.BookingWithPrice{
 				BookingBase: b,
+				Discount:    toDiscount(row.Booking, row.PromoCode),
+				CapabilityPrice: internal.CapabilityPrice{
+					Currency: row.Booking.Currency,
+					Pricing: internal.Pricing{
+						Net:               platform.ToPtr(0),
+						Currency:          row.Booking.Currency,
+						CurrencyPrecision: currencyPrecision(row.Booking.Currency),
+						IncludedTaxes:     internal.IncludedTaxes{},
+					},
+				},
 			}
+			ids = append(ids, row.Booking.ID)
 		}
 
-		price := bookings[row.Booking.ID].CapabilityPrice
-		price.Price += int(row.Price.Price)
-		price.Currency = row.Price.Currency
-		bookings[row.Booking.ID].CapabilityPrice = price
-
-		unit := toUnitWithPrice(row.Unit, row.Price)
-		units := bookings[row.Booking.ID].Units
-		bookings[row.Booking.ID].Units = append(units, unit)
+		unit := toUnitWithPrice(row.Unit, row.UnitTypeCode, row.Booking.Currency)
+		booking := bookings[row.Booking.ID]
+		booking.Pricing = addPricing(booking.Pricing, unit.Pricing)
+		booking.Price = booking.Pricing.Retail
+		booking.Units = append(booking.Units, unit)
 	}
 
 	result := make([]internal.BookingWithPrice, 0, len(bookings))
-	for _, booking := range bookings {
-		result = append(result, *booking)
+	for _, id := range ids {
+		applyDiscount(bookings[id])
+		result = append(result, *bookings[id])
 	}
 
 	return result
 }
 
-func toBooking(b queries.Booking) internal.BookingBase {
+func toDiscount(b queries.Booking, promoCode sql.NullString) *internal.Discount {
+	if !b.DiscountType.Valid {
+		return nil
+	}
+
+	return &internal.Discount{
+		PromoCode: promoCode.String,
+		Type:      internal.DiscountType(b.DiscountType.DiscountType),
+		Value:     int(b.DiscountAmount.Int32),
+		Currency:  b.Currency,
+	}
+}
+
+// applyDiscount subtracts the discount from the retail total of the booking, unit prices are not discounted.
+// The net total and included taxes are reduced proportionally, the original total stays.
+func applyDiscount(b *internal.BookingWithPrice) {
+	if b.Discount == nil {
+		return
+	}
+
+	total := b.Pricing.Retail
+	switch b.Discount.Type {
+	case internal.DiscountTypePercentage:
+		b.Discount.Amount = total * b.Discount.Value / 100
+	default:
+		b.Discount.Amount = min(b.Discount.Value, total)
+	}
+	if total == 0 {
+		return
+	}
+
+	discounted := total - b.Discount.Amount
+	scale := func(amount int) int { return amount * discounted / total }
+	if b.Pricing.Net != nil {
+		b.Pricing.Net = platform.ToPtr(scale(*b.Pricing.Net))
+	}
+	for i, tax := range b.Pricing.IncludedTaxes {
+		b.Pricing.IncludedTaxes[i].Retail = scale(tax.Retail)
+		if tax.Net != nil {
+			b.Pricing.IncludedTaxes[i].Net = platform.ToPtr(scale(*tax.Net))
+		}
+	}
+	b.Pricing.Retail = discounted
+	b.Price = discounted
+}
+
+// toBooking converts a booking with its option and contact, zero contact if the booking has none.
+func toBooking(b queries.Booking, optionCode string, c queries.Contact) internal.BookingBase {
 	return internal.BookingBase{
-		ID:             strconv.Itoa(int(b.ID)),
-		ProductID:      strconv.Itoa(int(b.ProductID)),
-		AvailabilityID: strconv.Itoa(int(b.AvailabilityID)),
-		Status:         b.Status,
-		Units:          []internal.Unit{},
+		ID:                strconv.Itoa(int(b.ID)),
+		UUID:              b.Uuid.String(),
+		ResellerReference: nullStringToPtr(b.ResellerReference),
+		ProductID:         strconv.Itoa(int(b.ProductID)),
+		OptionID:          optionCode,
+		AvailabilityID:    strconv.Itoa(int(b.AvailabilityID)),
+		Status:            b.Status,
+		Units:             []internal.Unit{},
+		Voucher:           nullStringToPtr(b.Voucher),
+		UTCExpiresAt:      nullTimeToUTCPtr(b.ExpiresAt),
+		Cancellation:      toCancellation(b),
+		Contact:           toContact(c),
+	}
+}
+
+func toWaitlistEntry(e queries.WaitlistEntry, optionCode string) internal.WaitlistEntry {
+	entry := internal.WaitlistEntry{
+		ID:             strconv.Itoa(int(e.ID)),
+		Status:         e.Status,
+		OptionID:       optionCode,
+		AvailabilityID: strconv.Itoa(int(e.AvailabilityID)),
+		Currency:       e.Currency,
+		Units:          int(e.Units),
+		WebhookURL:     nullStringToPtr(e.WebhookUrl),
+		UTCCreatedAt:   e.CreatedAt.Time.UTC(),
+	}
+	if e.BookingID.Valid {
+		entry.BookingID = platform.ToPtr(strconv.Itoa(int(e.BookingID.Int64)))
+	}
+
+	return entry
+}
+
+func toCancellation(b queries.Booking) *internal.Cancellation {
+	if b.Status != internal.BookingStatusCancelled {
+		return nil
+	}
+
+	return &internal.Cancellation{
+		Reason:         b.CancellationReason.String,
+		UTCCancelledAt: b.CancelledAt.Time.UTC(),
+	}
+}
+
+func toContact(c queries.Contact) internal.Contact {
+	locales := c.Locales
+	if locales == nil {
+		locales = []string{}
+	}
+
+	return internal.Contact{
+		FullName:     nullStringToPtr(c.FullName),
+		EmailAddress: nullStringToPtr(c.EmailAddress),
+		PhoneNumber:  nullStringToPtr(c.PhoneNumber),
+		Locales:      locales,
+		Country:      nullStringToPtr(c.Country),
+		Notes:        nullStringToPtr(c.Notes),
+	}
+}
+
+func toUpsertContactParams(bookingID int64, c internal.Contact) queries.UpsertContactParams {
+	locales := c.Locales
+	if locales == nil {
+		locales = []string{}
+	}
+
+	return queries.UpsertContactParams{
+		BookingID:    bookingID,
+		FullName:     ptrToNullString(c.FullName),
+		EmailAddress: ptrToNullString(c.EmailAddress),
+		PhoneNumber:  ptrToNullString(c.PhoneNumber),
+		Locales:      locales,
+		Country:      ptrToNullString(c.Country),
+		Notes:        ptrToNullString(c.Notes),
 	}
 }
 
-func toUnit(u queries.Unit) internal.UnitBase {
+func toUnit(u queries.Unit, unitTypeCode sql.NullString) internal.UnitBase {
 	return internal.UnitBase{
-		ID:     strconv.Itoa(int(u.ID)),
-		Ticket: nullStringToPtr(u.Ticket),
+		ID:            strconv.Itoa(int(u.ID)),
+		UnitID:        nullStringToPtr(unitTypeCode),
+		Ticket:        nullStringToPtr(u.Ticket),
+		UTCRedeemedAt: nullTimeToUTCPtr(u.RedeemedAt),
 	}
 }
 
-func toUnitWithPrice(u queries.Unit, p queries.Price) internal.UnitWithPrice {
+func toUnitWithPrice(u queries.Unit, unitTypeCode sql.NullString, currency string) internal.UnitWithPrice {
 	return internal.UnitWithPrice{
-		UnitBase:        toUnit(u),
-		CapabilityPrice: toPrice(p),
+		UnitBase: toUnit(u, unitTypeCode),
+		CapabilityPrice: internal.CapabilityPrice{
+			Price:    int(u.Price),
+			Currency: currency,
+			Pricing:  toPricing(u.Price, u.Original, u.Net, u.IncludedTaxes, currency),
+		},
 	}
 }
 
 func toPrice(p queries.Price) internal.CapabilityPrice {
+	original := p.Price // price without discounts
+	if p.Original.Valid {
+		original = p.Original.Int32
+	}
+
 	return internal.CapabilityPrice{
 		Price:    int(p.Price),
 		Currency: p.Currency,
+		Pricing:  toPricing(p.Price, original, p.Net, p.IncludedTaxes, p.Currency),
+	}
+}
+
+func toPricing(retail, original int32, net sql.NullInt32, taxes internal.IncludedTaxes, currency string) internal.Pricing {
+	if taxes == nil {
+		taxes = internal.IncludedTaxes{}
+	}
+
+	return internal.Pricing{
+		Original:          int(original),
+		Retail:            int(retail),
+		Net:               nullInt32ToPtr(net),
+		Currency:          currency,
+		CurrencyPrecision: currencyPrecision(currency),
+		IncludedTaxes:     taxes,
+	}
+}
+
+// addPricing adds up price breakdowns, taxes are summed by name.
+// Net amounts are unknown if they are unknown in any of the breakdowns.
+func addPricing(total, p internal.Pricing) internal.Pricing {
+	total.Original += p.Original
+	total.Retail += p.Retail
+	total.Net = addNet(total.Net, p.Net)
+
+	taxes := make(internal.IncludedTaxes, len(total.IncludedTaxes), len(total.IncludedTaxes)+len(p.IncludedTaxes))
+	copy(taxes, total.IncludedTaxes)
+	for _, tax := range p.IncludedTaxes {
+		i := slices.IndexFunc(taxes, func(t internal.Tax) bool { return t.Name == tax.Name })
+		if i < 0 {
+			taxes = append(taxes, tax)
+			continue
+		}
+		taxes[i].Retail += tax.Retail
+		taxes[i].Net = addNet(taxes[i].Net, tax.Net)
+	}
+	total.IncludedTaxes = taxes
+
+	return total
+}
+
+func addNet(a, b *int) *int {
+	if a == nil || b == nil {
+		return nil
 	}
+	return platform.ToPtr(*a + *b)
+}
+
+// currencyPrecision returns the number of minor unit digits of the ISO 4217 currency, 2 if unknown.
+func currencyPrecision(code string) int {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return 2
+	}
+	scale, _ := currency.Standard.Rounding(unit)
+	return scale
 }
 
 func nullStringToPtr(s sql.NullString) *string {
@@ -419,6 +1914,51 @@ func nullStringToPtr(s sql.NullString) *string {
 	return nil
 }
 
+func nullInt32ToPtr(i sql.NullInt32) *int {
+	if i.Valid {
+		return platform.ToPtr(int(i.Int32))
+	}
+	return nil
+}
+
+func ptrToNullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{
+		String: *s,
+		Valid:  true,
+	}
+}
+
+func stringToNullString(s string) sql.NullString {
+	return sql.NullString{
+		String: s,
+		Valid:  s != "",
+	}
+}
+
+// isUniqueViolation reports whether err is caused by a unique constraint.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func timeToNullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{
+		Time:  t,
+		Valid: !t.IsZero(),
+	}
+}
+
+func nullTimeToUTCPtr(t sql.NullTime) *time.Time {
+	if t.Valid {
+		utc := t.Time.UTC()
+		return &utc
+	}
+	return nil
+}
+
 func mapp[T any, V any](slice []T, f func(T) V) []V {
 	mapped := make([]V, len(slice))
 	for i, v := range slice {
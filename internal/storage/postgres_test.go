@@ -1,21 +1,33 @@
 package storage_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/ed25519"
 	"database/sql"
 	"errors"
+	"net/http"
 	"reflect"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/dmksnnk/octo/internal"
+	"github.com/dmksnnk/octo/internal/idempotency"
+	"github.com/dmksnnk/octo/internal/platform"
 	"github.com/dmksnnk/octo/internal/service"
 	"github.com/dmksnnk/octo/internal/storage"
 	"github.com/dmksnnk/octo/internal/storage/queries"
 	"github.com/dmksnnk/octo/internal/storage/storagetesting"
+	"github.com/dmksnnk/octo/internal/ticket"
 )
 
+// signer signs tickets with a fixed test key.
+var signer = ticket.NewSigner(1, ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+
 func TestProduct(t *testing.T) {
 	db := storagetesting.Open(t)
 	products := []queries.Product{
@@ -36,7 +48,7 @@ func TestProduct(t *testing.T) {
 	}
 
 	t.Run("get all products", func(t *testing.T) {
-		gotProducts, err := storage.NewPostgres(db).Products(context.TODO(), internal.CapabilityRequestNone)
+		gotProducts, err := storage.NewPostgres(db, signer).Products(context.TODO(), internal.CapabilityRequestNone, "")
 		if err != nil {
 			t.Fatalf("get products: %v", err)
 		}
@@ -50,7 +62,7 @@ func TestProduct(t *testing.T) {
 	})
 
 	t.Run("get all products with prices", func(t *testing.T) {
-		gotProducts, err := storage.NewPostgres(db).Products(context.TODO(), internal.CapabilityRequestPrice)
+		gotProducts, err := storage.NewPostgres(db, signer).Products(context.TODO(), internal.CapabilityRequestPrice, "")
 		if err != nil {
 			t.Fatalf("get products: %v", err)
 		}
@@ -69,7 +81,7 @@ func TestProduct(t *testing.T) {
 
 	t.Run("get product by ID", func(t *testing.T) {
 		product := products[0]
-		gotProduct, err := storage.NewPostgres(db).Product(context.TODO(), int(product.ID), internal.CapabilityRequestNone)
+		gotProduct, err := storage.NewPostgres(db, signer).Product(context.TODO(), int(product.ID), internal.CapabilityRequestNone, "")
 		if err != nil {
 			t.Fatalf("get product: %v", err)
 		}
@@ -77,7 +89,7 @@ func TestProduct(t *testing.T) {
 	})
 
 	t.Run("get product by ID not found", func(t *testing.T) {
-		_, err := storage.NewPostgres(db).Product(context.TODO(), -1, internal.CapabilityRequestNone)
+		_, err := storage.NewPostgres(db, signer).Product(context.TODO(), -1, internal.CapabilityRequestNone, "")
 		if !errors.Is(err, service.ErrNotFound) {
 			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
 		}
@@ -108,28 +120,40 @@ func TestAvailability(t *testing.T) {
 		// in range
 		storagetesting.NewAvailability(t, db, products[1].ID, func(iap *queries.InsertAvailabilityParams) {
 			iap.LocalDate = dateStart
+			iap.Capacity = sql.NullInt32{Int32: 10, Valid: true}
+			iap.Vacancies = 10
 		}),
 		storagetesting.NewAvailability(t, db, products[1].ID, func(iap *queries.InsertAvailabilityParams) {
 			iap.LocalDate = dateEnd
+			iap.Capacity = sql.NullInt32{Int32: 10, Valid: true}
+			iap.Vacancies = 4
 		}),
 	}
 
 	t.Run("get single availability", func(t *testing.T) {
 		product := products[0]
 		availability := availabilities[0]
-		wantAvailability := internal.AvailabilityBase{
-			ID:        strconv.Itoa(int(availability.ID)),
-			LocalDate: internal.Date(availability.LocalDate),
-			Status:    internal.AvailabilityStatusSoldOut,
-			Vacancies: int(availability.Vacancies),
-			Available: false,
+		wantAvailabilities := []internal.Availability{
+			internal.AvailabilityBase{
+				ID:                 strconv.Itoa(int(availability.ID)),
+				LocalDate:          internal.Date(availability.LocalDate),
+				LocalDateTimeStart: availability.LocalDateTimeStart.UTC(), // products are in UTC by default
+				LocalDateTimeEnd:   availability.LocalDateTimeEnd.UTC(),
+				AllDay:             true,
+				Status:             internal.AvailabilityStatusSoldOut,
+				Capacity:           int(products[0].Capacity), // option's capacity by default
+				Vacancies:          int(availability.Vacancies),
+				Utilization:        1,
+				Available:          false,
+				OpeningHours:       []internal.OpeningHours{},
+			},
 		}
-		gotAvailability, err := storage.NewPostgres(db).Availability(context.TODO(), int(product.ID), availability.LocalDate, internal.CapabilityRequestNone)
+		gotAvailabilities, err := storage.NewPostgres(db, signer).Availabilities(context.TODO(), int(product.ID), internal.DefaultOptionID, availability.LocalDate, availability.LocalDate, internal.CapabilityRequestNone, "")
 		if err != nil {
 			t.Fatalf("get availability: %v", err)
 		}
-		if !reflect.DeepEqual(wantAvailability, gotAvailability) {
-			t.Errorf("want availability %v, got %v", wantAvailability, gotAvailability)
+		if !reflect.DeepEqual(wantAvailabilities, gotAvailabilities) {
+			t.Errorf("want availabilities %v, got %v", wantAvailabilities, gotAvailabilities)
 		}
 	})
 
@@ -137,32 +161,40 @@ func TestAvailability(t *testing.T) {
 		product := products[0]
 		availability := availabilities[0]
 		price := prices[0]
-		wantAvailability := internal.AvailabilityWithPrice{
-			AvailabilityBase: internal.AvailabilityBase{
-				ID:        strconv.Itoa(int(availability.ID)),
-				LocalDate: internal.Date(availability.LocalDate),
-				Status:    internal.AvailabilityStatusSoldOut,
-				Vacancies: int(availability.Vacancies),
-				Available: false,
-			},
-			CapabilityPrice: internal.CapabilityPrice{
-				Price:    int(price.Price),
-				Currency: price.Currency,
+		wantAvailabilities := []internal.Availability{
+			internal.AvailabilityWithPrice{
+				AvailabilityBase: internal.AvailabilityBase{
+					ID:                 strconv.Itoa(int(availability.ID)),
+					LocalDate:          internal.Date(availability.LocalDate),
+					LocalDateTimeStart: availability.LocalDateTimeStart.UTC(), // products are in UTC by default
+					LocalDateTimeEnd:   availability.LocalDateTimeEnd.UTC(),
+					AllDay:             true,
+					Status:             internal.AvailabilityStatusSoldOut,
+					Capacity:           int(products[0].Capacity),
+					Vacancies:          int(availability.Vacancies),
+					Utilization:        1,
+					Available:          false,
+					OpeningHours:       []internal.OpeningHours{},
+				},
+				CapabilityPrice: capabilityPrice(int(price.Price), price.Currency),
 			},
 		}
-		gotAvailability, err := storage.NewPostgres(db).Availability(context.TODO(), int(product.ID), availability.LocalDate, internal.CapabilityRequestPrice)
+		gotAvailabilities, err := storage.NewPostgres(db, signer).Availabilities(context.TODO(), int(product.ID), internal.DefaultOptionID, availability.LocalDate, availability.LocalDate, internal.CapabilityRequestPrice, "")
 		if err != nil {
 			t.Fatalf("get availability: %v", err)
 		}
-		if !reflect.DeepEqual(wantAvailability, gotAvailability) {
-			t.Errorf("want availability %v, got %v", wantAvailability, gotAvailability)
+		if !reflect.DeepEqual(wantAvailabilities, gotAvailabilities) {
+			t.Errorf("want availabilities %v, got %v", wantAvailabilities, gotAvailabilities)
 		}
 	})
 
 	t.Run("get availability not found", func(t *testing.T) {
-		_, err := storage.NewPostgres(db).Availability(context.TODO(), -1, time.Now(), internal.CapabilityRequestNone)
-		if !errors.Is(err, service.ErrNotFound) {
-			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		got, err := storage.NewPostgres(db, signer).Availabilities(context.TODO(), -1, internal.DefaultOptionID, time.Now(), time.Now(), internal.CapabilityRequestNone, "")
+		if err != nil {
+			t.Fatalf("get availability: %v", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("want no availabilities, got %v", got)
 		}
 	})
 
@@ -170,22 +202,34 @@ func TestAvailability(t *testing.T) {
 		product := products[1]
 		wantAvailabilities := []internal.AvailabilityBase{
 			{
-				ID:        strconv.Itoa(int(availabilities[1].ID)),
-				LocalDate: internal.Date(availabilities[1].LocalDate),
-				Status:    internal.AvailabilityStatusAvailable,
-				Vacancies: int(availabilities[1].Vacancies),
-				Available: true,
+				ID:                 strconv.Itoa(int(availabilities[1].ID)),
+				LocalDate:          internal.Date(availabilities[1].LocalDate),
+				LocalDateTimeStart: availabilities[1].LocalDateTimeStart.UTC(),
+				LocalDateTimeEnd:   availabilities[1].LocalDateTimeEnd.UTC(),
+				AllDay:             true,
+				Status:             internal.AvailabilityStatusAvailable,
+				Capacity:           10,
+				Vacancies:          10,
+				Utilization:        0,
+				Available:          true,
+				OpeningHours:       []internal.OpeningHours{},
 			},
 			{
-				ID:        strconv.Itoa(int(availabilities[2].ID)),
-				LocalDate: internal.Date(availabilities[2].LocalDate),
-				Status:    internal.AvailabilityStatusAvailable,
-				Vacancies: int(availabilities[2].Vacancies),
-				Available: true,
+				ID:                 strconv.Itoa(int(availabilities[2].ID)),
+				LocalDate:          internal.Date(availabilities[2].LocalDate),
+				LocalDateTimeStart: availabilities[2].LocalDateTimeStart.UTC(),
+				LocalDateTimeEnd:   availabilities[2].LocalDateTimeEnd.UTC(),
+				AllDay:             true,
+				Status:             internal.AvailabilityStatusAvailable,
+				Capacity:           10,
+				Vacancies:          4,
+				Utilization:        0.6,
+				Available:          true,
+				OpeningHours:       []internal.OpeningHours{},
 			},
 		}
 
-		gotAvailabilities, err := storage.NewPostgres(db).Availabilities(context.TODO(), int(product.ID), dateStart, dateEnd, internal.CapabilityRequestNone)
+		gotAvailabilities, err := storage.NewPostgres(db, signer).Availabilities(context.TODO(), int(product.ID), internal.DefaultOptionID, dateStart, dateEnd, internal.CapabilityRequestNone, "")
 		if err != nil {
 			t.Fatalf("get availabilities: %v", err)
 		}
@@ -194,160 +238,3099 @@ func TestAvailability(t *testing.T) {
 		}
 
 		for i, gotAvailability := range gotAvailabilities {
-			if gotAvailability != wantAvailabilities[i] {
+			if !reflect.DeepEqual(gotAvailability, wantAvailabilities[i]) {
 				t.Errorf("want availability %v, got %v", wantAvailabilities[i], gotAvailability)
 			}
 		}
 	})
+
+	t.Run("start time slots in product time zone", func(t *testing.T) {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		if err != nil {
+			t.Fatalf("load location: %v", err)
+		}
+		product := storagetesting.NewProduct(t, db, func(p *queries.InsertProductParams) {
+			p.Timezone = berlin.String()
+		})
+		localDate := time.Date(2025, time.January, 20, 0, 0, 0, 0, time.UTC)
+		slot := func(hour int) func(*queries.InsertAvailabilityParams) {
+			return func(p *queries.InsertAvailabilityParams) {
+				p.LocalDate = localDate
+				p.LocalDateTimeStart = sql.NullTime{Time: localDate.Add(time.Duration(hour) * time.Hour), Valid: true}
+				p.LocalDateTimeEnd = sql.NullTime{Time: localDate.Add(time.Duration(hour+2) * time.Hour), Valid: true}
+			}
+		}
+		// inserted out of order
+		afternoon := storagetesting.NewAvailability(t, db, product.ID, slot(13))
+		morning := storagetesting.NewAvailability(t, db, product.ID, slot(9))
+
+		got, err := storage.NewPostgres(db, signer).Availabilities(context.TODO(), int(product.ID), internal.DefaultOptionID, localDate, localDate, internal.CapabilityRequestNone, "")
+		if err != nil {
+			t.Fatalf("get availabilities: %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("want 2 availabilities, got %d", len(got))
+		}
+
+		for i, want := range []struct {
+			id         int32
+			start, end time.Time
+		}{
+			{morning.ID, time.Date(2025, time.January, 20, 9, 0, 0, 0, berlin), time.Date(2025, time.January, 20, 11, 0, 0, 0, berlin)},
+			{afternoon.ID, time.Date(2025, time.January, 20, 13, 0, 0, 0, berlin), time.Date(2025, time.January, 20, 15, 0, 0, 0, berlin)},
+		} {
+			a := got[i].(internal.AvailabilityBase)
+			if wantID := strconv.Itoa(int(want.id)); a.ID != wantID {
+				t.Errorf("want availability %d to be %s, got %s", i, wantID, a.ID)
+			}
+			if !a.LocalDateTimeStart.Equal(want.start) || !a.LocalDateTimeEnd.Equal(want.end) {
+				t.Errorf("want slot %s - %s, got %s - %s", want.start, want.end, a.LocalDateTimeStart, a.LocalDateTimeEnd)
+			}
+			if loc := a.LocalDateTimeStart.Location().String(); loc != berlin.String() {
+				t.Errorf("want location %s, got %s", berlin, loc)
+			}
+			if a.AllDay {
+				t.Errorf("want availability %s not to be all day", a.ID)
+			}
+		}
+	})
+
+	t.Run("availability statuses", func(t *testing.T) {
+		product := storagetesting.NewProduct(t, db, func(p *queries.InsertProductParams) {
+			p.LimitedThreshold = 5
+		})
+		localDate := time.Date(2025, time.January, 20, 0, 0, 0, 0, time.UTC)
+		newAvailability := func(vacancies int32, closed, freesale bool) queries.Availability {
+			return storagetesting.NewAvailability(t, db, product.ID, func(p *queries.InsertAvailabilityParams) {
+				p.LocalDate = localDate
+				p.Vacancies = vacancies
+				p.Closed = closed
+				p.Freesale = freesale
+			})
+		}
+		want := map[int32]struct {
+			status    internal.AvailabilityStatus
+			available bool
+		}{
+			newAvailability(10, false, false).ID: {internal.AvailabilityStatusAvailable, true},
+			newAvailability(3, false, false).ID:  {internal.AvailabilityStatusLimited, true},
+			newAvailability(0, false, false).ID:  {internal.AvailabilityStatusSoldOut, false},
+			newAvailability(10, true, false).ID:  {internal.AvailabilityStatusClosed, false},
+			newAvailability(0, false, true).ID:   {internal.AvailabilityStatusFreesale, true},
+		}
+
+		got, err := storage.NewPostgres(db, signer).Availabilities(context.TODO(), int(product.ID), internal.DefaultOptionID, localDate, localDate, internal.CapabilityRequestNone, "")
+		if err != nil {
+			t.Fatalf("get availabilities: %v", err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("want %d availabilities, got %d", len(want), len(got))
+		}
+		for _, a := range got {
+			a := a.(internal.AvailabilityBase)
+			id, _ := strconv.Atoi(a.ID)
+			if w := want[int32(id)]; a.Status != w.status || a.Available != w.available {
+				t.Errorf("want availability %s to be %s (available %t), got %s (available %t)", a.ID, w.status, w.available, a.Status, a.Available)
+			}
+		}
+	})
+
+	t.Run("capacity validation", func(t *testing.T) {
+		product := storagetesting.NewProduct(t, db, func(p *queries.InsertProductParams) {
+			p.Capacity = 10
+		})
+		for name, params := range map[string]queries.InsertAvailabilityParams{
+			"capacity above product capacity": {
+				ProductID: product.ID,
+				LocalDate: dateStart,
+				Capacity:  sql.NullInt32{Int32: 11, Valid: true},
+				Vacancies: 5,
+			},
+			"vacancies above capacity": {
+				ProductID: product.ID,
+				LocalDate: dateStart,
+				Capacity:  sql.NullInt32{Int32: 5, Valid: true},
+				Vacancies: 6,
+			},
+			"vacancies above product capacity": {
+				ProductID: product.ID,
+				LocalDate: dateStart,
+				Vacancies: 11,
+			},
+		} {
+			if _, err := queries.New(db).InsertAvailability(context.TODO(), params); err == nil {
+				t.Errorf("%s: want error, got none", name)
+			}
+		}
+	})
+
+	t.Run("released vacancies within lowered capacity", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		user := storagetesting.NewUser(t, db)
+		product := storagetesting.NewProduct(t, db, func(p *queries.InsertProductParams) {
+			p.Capacity = 10
+		})
+		storagetesting.NewPrice(t, db, product.ID)
+		availability := storagetesting.NewAvailability(t, db, product.ID, func(p *queries.InsertAvailabilityParams) {
+			p.Vacancies = 10
+		})
+		id, err := pg.CreateBooking(context.TODO(), service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          4,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		// 6 vacancies left, the operator sells no more than 7 units in total
+		capacityParams := queries.SetCapacityParams{
+			ID:       availability.ID,
+			Capacity: 7,
+		}
+		if err := queries.New(db).SetCapacity(context.TODO(), capacityParams); err != nil {
+			t.Fatalf("set capacity: %v", err)
+		}
+
+		if err := pg.CancelBooking(context.TODO(), id, int(user.ID), "changed plans", time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("cancel booking: %v", err)
+		}
+
+		got, err := pg.Availabilities(context.TODO(), int(product.ID), internal.DefaultOptionID, availability.LocalDate, availability.LocalDate, internal.CapabilityRequestNone, "")
+		if err != nil {
+			t.Fatalf("get availabilities: %v", err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("want 1 availability, got %d", len(got))
+		}
+		if vacancies := got[0].(internal.AvailabilityBase).Vacancies; vacancies != 7 {
+			t.Errorf("want 7 vacancies, got %d", vacancies)
+		}
+	})
+
+	t.Run("opening hours", func(t *testing.T) {
+		product := storagetesting.NewProduct(t, db, func(p *queries.InsertProductParams) {
+			p.AvailabilityType = internal.AvailabilityTypeOpeningHours
+		})
+		storagetesting.NewPrice(t, db, product.ID)
+		availability := storagetesting.NewAvailability(t, db, product.ID)
+		// closed for lunch, inserted out of order
+		storagetesting.NewOpeningHours(t, db, availability.ID, func(p *queries.InsertOpeningHoursParams) {
+			p.TimeFrom = time.Date(2000, time.January, 1, 13, 0, 0, 0, time.UTC)
+			p.TimeTo = time.Date(2000, time.January, 1, 18, 30, 0, 0, time.UTC)
+		})
+		storagetesting.NewOpeningHours(t, db, availability.ID, func(p *queries.InsertOpeningHoursParams) {
+			p.TimeTo = time.Date(2000, time.January, 1, 12, 0, 0, 0, time.UTC)
+		})
+		want := []internal.OpeningHours{
+			{From: "09:00", To: "12:00"},
+			{From: "13:00", To: "18:30"},
+		}
+
+		for _, capability := range []internal.CapabilityRequest{internal.CapabilityRequestNone, internal.CapabilityRequestPrice} {
+			got, err := storage.NewPostgres(db, signer).Availabilities(context.TODO(), int(product.ID), internal.DefaultOptionID, availability.LocalDate, availability.LocalDate, capability, "")
+			if err != nil {
+				t.Fatalf("get availabilities: %v", err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("want 1 availability, got %d", len(got))
+			}
+
+			var gotOpeningHours []internal.OpeningHours
+			switch a := got[0].(type) {
+			case internal.AvailabilityBase:
+				gotOpeningHours = a.OpeningHours
+			case internal.AvailabilityWithPrice:
+				gotOpeningHours = a.OpeningHours
+			}
+			if !reflect.DeepEqual(want, gotOpeningHours) {
+				t.Errorf("want opening hours %v, got %v", want, gotOpeningHours)
+			}
+		}
+	})
 }
 
-func TestBooking(t *testing.T) {
+func TestAvailabilityCalendar(t *testing.T) {
+	db := storagetesting.Open(t)
+	product := storagetesting.NewProduct(t, db, func(p *queries.InsertProductParams) {
+		p.LimitedThreshold = 15
+	})
+	basePrice := storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+		p.Price = 100
+	})
+	evening := storagetesting.NewOption(t, db, product.ID)
+	storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+		p.OptionID = sql.NullInt32{Int32: evening.ID, Valid: true}
+		p.Price = 50
+		p.Currency = basePrice.Currency
+	})
+	dateStart := time.Date(2025, time.January, 20, 0, 0, 0, 0, time.UTC)
+	slot := func(day, vacancies int, ops ...func(*queries.InsertAvailabilityParams)) {
+		storagetesting.NewAvailability(t, db, product.ID, append(ops, func(p *queries.InsertAvailabilityParams) {
+			p.LocalDate = dateStart.AddDate(0, 0, day)
+			p.Vacancies = int32(vacancies)
+		})...)
+	}
+	inEvening := func(p *queries.InsertAvailabilityParams) { p.OptionID = sql.NullInt32{Int32: evening.ID, Valid: true} }
+	closed := func(p *queries.InsertAvailabilityParams) { p.Closed = true }
+	freesale := func(p *queries.InsertAvailabilityParams) { p.Freesale = true }
+	// first date: fewer vacancies than the product's limited threshold
+	slot(0, 10)
+	slot(0, 0)
+	// second date: no slots
+	// third date: all slots are sold out
+	slot(2, 0)
+	slot(2, 0, inEvening)
+	// fourth date: available in both options, evening is cheaper
+	slot(3, 10)
+	slot(3, 5, inEvening)
+	// fifth date: shut by the operator
+	slot(4, 10, closed)
+	// sixth date: unlimited vacancies
+	slot(5, 0, freesale)
+	slot(5, 10, closed)
+	dateEnd := dateStart.AddDate(0, 0, 5)
+
+	wantCalendar := []internal.AvailabilityCalendarBase{
+		{LocalDate: internal.Date(dateStart), Status: internal.AvailabilityStatusLimited, Vacancies: 10, Available: true},
+		{LocalDate: internal.Date(dateStart.AddDate(0, 0, 1)), Status: internal.AvailabilityStatusClosed},
+		{LocalDate: internal.Date(dateStart.AddDate(0, 0, 2)), Status: internal.AvailabilityStatusSoldOut},
+		{LocalDate: internal.Date(dateStart.AddDate(0, 0, 3)), Status: internal.AvailabilityStatusAvailable, Vacancies: 15, Available: true},
+		{LocalDate: internal.Date(dateStart.AddDate(0, 0, 4)), Status: internal.AvailabilityStatusClosed},
+		{LocalDate: internal.Date(dateStart.AddDate(0, 0, 5)), Status: internal.AvailabilityStatusFreesale, Available: true},
+	}
+
+	t.Run("calendar", func(t *testing.T) {
+		got, err := storage.NewPostgres(db, signer).AvailabilityCalendar(context.TODO(), int(product.ID), dateStart, dateEnd, internal.CapabilityRequestNone, "")
+		if err != nil {
+			t.Fatalf("get availability calendar: %v", err)
+		}
+		if len(got) != len(wantCalendar) {
+			t.Fatalf("want %d dates, got %d", len(wantCalendar), len(got))
+		}
+		for i, want := range wantCalendar {
+			gotDate := got[i].(internal.AvailabilityCalendarBase)
+			gotDate.LocalDate = internal.Date(time.Time(gotDate.LocalDate).UTC()) // dates are scanned with a fixed zone
+			if gotDate != want {
+				t.Errorf("want date %+v, got %+v", want, gotDate)
+			}
+		}
+	})
+
+	t.Run("calendar with price", func(t *testing.T) {
+		got, err := storage.NewPostgres(db, signer).AvailabilityCalendar(context.TODO(), int(product.ID), dateStart, dateEnd, internal.CapabilityRequestPrice, "")
+		if err != nil {
+			t.Fatalf("get availability calendar: %v", err)
+		}
+		if len(got) != len(wantCalendar) {
+			t.Fatalf("want %d dates, got %d", len(wantCalendar), len(got))
+		}
+		// lowest price of the date, base price for closed dates
+		for i, wantPrice := range []int{100, 100, 50, 50, 100, 100} {
+			want := internal.AvailabilityCalendarWithPrice{
+				AvailabilityCalendarBase: wantCalendar[i],
+				CapabilityPrice:          capabilityPrice(wantPrice, basePrice.Currency),
+			}
+			gotDate := got[i].(internal.AvailabilityCalendarWithPrice)
+			gotDate.LocalDate = internal.Date(time.Time(gotDate.LocalDate).UTC())
+			if !reflect.DeepEqual(want, gotDate) {
+				t.Errorf("want date %+v, got %+v", want, gotDate)
+			}
+		}
+	})
+
+	t.Run("calendar product not found", func(t *testing.T) {
+		got, err := storage.NewPostgres(db, signer).AvailabilityCalendar(context.TODO(), -1, dateStart, dateEnd, internal.CapabilityRequestNone, "")
+		if err != nil {
+			t.Fatalf("get availability calendar: %v", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("want no dates, got %+v", got)
+		}
+	})
+}
+
+func TestUnitTypes(t *testing.T) {
 	db := storagetesting.Open(t)
 	user := storagetesting.NewUser(t, db)
 	product := storagetesting.NewProduct(t, db)
-	price := storagetesting.NewPrice(t, db, product.ID)
+	basePrice := storagetesting.NewPrice(t, db, product.ID)
+	adult := storagetesting.NewUnitType(t, db, product.ID, func(p *queries.InsertUnitTypeParams) {
+		p.Code = "adult"
+	})
+	child := storagetesting.NewUnitType(t, db, product.ID, func(p *queries.InsertUnitTypeParams) {
+		p.Code = "child"
+	})
+	childPrice := storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+		p.Currency = basePrice.Currency
+		p.UnitTypeID = sql.NullInt32{Int32: child.ID, Valid: true}
+	})
 
-	t.Run("booking not found", func(t *testing.T) {
-		_, err := storage.NewPostgres(db).Booking(context.TODO(), -1, int(user.ID), internal.CapabilityRequestNone)
-		if !errors.Is(err, service.ErrNotFound) {
-			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+	t.Run("product units", func(t *testing.T) {
+		got, err := storage.NewPostgres(db, signer).Product(context.TODO(), int(product.ID), internal.CapabilityRequestPrice, "")
+		if err != nil {
+			t.Fatalf("get product: %v", err)
+		}
+		want := []internal.UnitType{
+			internal.UnitTypeWithPrice{
+				UnitTypeBase:    internal.UnitTypeBase{ID: adult.Code, Name: adult.Name},
+				CapabilityPrice: capabilityPrice(int(basePrice.Price), basePrice.Currency), // falls back to product price
+			},
+			internal.UnitTypeWithPrice{
+				UnitTypeBase:    internal.UnitTypeBase{ID: child.Code, Name: child.Name},
+				CapabilityPrice: capabilityPrice(int(childPrice.Price), childPrice.Currency),
+			},
+		}
+		if gotUnits := got.(internal.ProductWithPrice).Units; !reflect.DeepEqual(want, gotUnits) {
+			t.Errorf("want units %+v, got %+v", want, gotUnits)
 		}
 	})
 
-	t.Run("create booking", func(t *testing.T) {
-		pg := storage.NewPostgres(db)
+	t.Run("create booking with unit types", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
 		availability := storagetesting.NewAvailability(t, db, product.ID)
 
 		params := service.CreateBookingParams{
 			ProductID:      int(product.ID),
 			AvailabilityID: int(availability.ID),
 			Units:          3,
+			UnitIDs:        []string{"adult", "child", "child"},
 			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
 		}
 		id, err := pg.CreateBooking(context.TODO(), params)
 		if err != nil {
 			t.Fatalf("create booking: %v", err)
 		}
 
-		wantBookingWithPrice := internal.BookingWithPrice{
-			BookingBase: internal.BookingBase{
-				ProductID:      strconv.Itoa(int(product.ID)),
-				AvailabilityID: strconv.Itoa(int(availability.ID)),
-				Status:         internal.BookingStatusReserved,
-				Units: []internal.Unit{
-					internal.UnitWithPrice{
-						CapabilityPrice: internal.CapabilityPrice{
-							Price:    int(price.Price),
-							Currency: price.Currency,
-						},
-					},
-					internal.UnitWithPrice{
-						CapabilityPrice: internal.CapabilityPrice{
-							Price:    int(price.Price),
-							Currency: price.Currency,
-						},
-					},
-					internal.UnitWithPrice{
-						CapabilityPrice: internal.CapabilityPrice{
-							Price:    int(price.Price),
-							Currency: price.Currency,
-						},
-					},
-				},
-			},
-			CapabilityPrice: internal.CapabilityPrice{
-				Price:    int(price.Price) * 3,
-				Currency: price.Currency,
-			},
-		}
-		gotBookingWithPrice, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestPrice)
+		got, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestPrice)
 		if err != nil {
 			t.Fatalf("get booking: %v", err)
 		}
-		assertBookingWithPrice(t, wantBookingWithPrice, gotBookingWithPrice.(internal.BookingWithPrice))
-
-		// confirm booking
-		wantConfirmedBooking := internal.BookingBase{
-			ProductID:      strconv.Itoa(int(product.ID)),
-			AvailabilityID: strconv.Itoa(int(availability.ID)),
-			Status:         internal.BookingStatusConfirmed,
-			Units:          make([]internal.Unit, 3),
+		booking := got.(internal.BookingWithPrice)
+		wantPrices := []int{int(basePrice.Price), int(childPrice.Price), int(childPrice.Price)}
+		if len(booking.Units) != len(wantPrices) {
+			t.Fatalf("want %d units, got %d", len(wantPrices), len(booking.Units))
 		}
-		if err := pg.ConfirmBooking(context.TODO(), id, int(user.ID)); err != nil {
-			t.Fatalf("confirm booking: %v", err)
-		}
-		confirmedBooking, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestNone)
-		if err != nil {
-			t.Fatalf("get booking: %v", err)
-		}
-		for _, unit := range confirmedBooking.(internal.BookingBase).Units {
-			if unit.(internal.UnitBase).Ticket == nil {
-				t.Errorf("want ticket, got empty")
+		var wantTotal int
+		for i, unit := range booking.Units {
+			unit := unit.(internal.UnitWithPrice)
+			if unit.UnitID == nil || *unit.UnitID != params.UnitIDs[i] {
+				t.Errorf("want unit %d type %s, got %v", i, params.UnitIDs[i], unit.UnitID)
+			}
+			if unit.Price != wantPrices[i] {
+				t.Errorf("want unit %d price %d, got %d", i, wantPrices[i], unit.Price)
 			}
+			wantTotal += wantPrices[i]
 		}
-		assertBooking(t, wantConfirmedBooking, confirmedBooking.(internal.BookingBase))
+		if booking.Price != wantTotal {
+			t.Errorf("want total price %d, got %d", wantTotal, booking.Price)
+		}
+		assertVacancies(t, pg, availability, int(availability.Vacancies)-3)
+	})
 
-		// confirm booking again, should be no error
-		err = pg.ConfirmBooking(context.TODO(), id, int(user.ID))
-		if err != nil {
-			t.Fatalf("confirm booking again: %v", err)
+	t.Run("create booking unknown unit", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		availability := storagetesting.NewAvailability(t, db, product.ID)
+
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          1,
+			UnitIDs:        []string{"senior"},
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		_, err := pg.CreateBooking(context.TODO(), params)
+		if !errors.Is(err, service.ErrUnknownUnit) {
+			t.Errorf("want error %v, got %v", service.ErrUnknownUnit, err)
 		}
+		assertVacancies(t, pg, availability, int(availability.Vacancies))
 	})
 
-	t.Run("create booking out of vacancies", func(t *testing.T) {
-		availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
-			iap.Vacancies = 2
-		})
-		pg := storage.NewPostgres(db)
+	t.Run("update booking unit types", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		availability := storagetesting.NewAvailability(t, db, product.ID)
+
 		params := service.CreateBookingParams{
 			ProductID:      int(product.ID),
 			AvailabilityID: int(availability.ID),
 			Units:          3,
+			UnitIDs:        []string{"adult", "child", "child"},
 			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		id, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
 		}
 
-		_, err := pg.CreateBooking(context.TODO(), params)
-		if !errors.Is(err, service.ErrNotAvailable) {
-			t.Errorf("want error %v, got %v", service.ErrNotAvailable, err)
+		// the number alone does not tell which units to remove
+		err = pg.UpdateBooking(context.TODO(), service.UpdateBookingParams{
+			ID:     id,
+			UserID: int(user.ID),
+			Units:  2,
+		})
+		if !errors.Is(err, service.ErrUnitItemsRequired) {
+			t.Errorf("want error %v, got %v", service.ErrUnitItemsRequired, err)
 		}
-	})
 
-	t.Run("confirm booking not found", func(t *testing.T) {
-		err := storage.NewPostgres(db).ConfirmBooking(context.TODO(), -1, int(user.ID))
-		if !errors.Is(err, service.ErrNotFound) {
-			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		err = pg.UpdateBooking(context.TODO(), service.UpdateBookingParams{
+			ID:      id,
+			UserID:  int(user.ID),
+			Units:   4,
+			UnitIDs: []string{"child", "adult", "adult", "adult"},
+		})
+		if err != nil {
+			t.Fatalf("update booking: %v", err)
+		}
+
+		got, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestPrice)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
 		}
+		booking := got.(internal.BookingWithPrice)
+		// existing units of wanted types are kept, the second child is removed
+		wantUnitIDs := []string{"adult", "child", "adult", "adult"}
+		wantPrices := []int{int(basePrice.Price), int(childPrice.Price), int(basePrice.Price), int(basePrice.Price)}
+		if len(booking.Units) != len(wantUnitIDs) {
+			t.Fatalf("want %d units, got %d", len(wantUnitIDs), len(booking.Units))
+		}
+		for i, unit := range booking.Units {
+			unit := unit.(internal.UnitWithPrice)
+			if unit.UnitID == nil || *unit.UnitID != wantUnitIDs[i] {
+				t.Errorf("want unit %d type %s, got %v", i, wantUnitIDs[i], unit.UnitID)
+			}
+			if unit.Price != wantPrices[i] {
+				t.Errorf("want unit %d price %d, got %d", i, wantPrices[i], unit.Price)
+			}
+		}
+		assertVacancies(t, pg, availability, int(availability.Vacancies)-4)
 	})
 }
 
-func assertProductEqual(t *testing.T, want queries.Product, got internal.ProductBase) {
-	t.Helper()
+func TestOptions(t *testing.T) {
+	db := storagetesting.Open(t)
+	user := storagetesting.NewUser(t, db)
+	product := storagetesting.NewProduct(t, db)
+	basePrice := storagetesting.NewPrice(t, db, product.ID)
+	evening := storagetesting.NewOption(t, db, product.ID, func(p *queries.InsertOptionParams) {
+		p.Code = "EVENING"
+	})
+	eveningPrice := storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+		p.Currency = basePrice.Currency
+		p.OptionID = sql.NullInt32{Int32: evening.ID, Valid: true}
+	})
+	localDate := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	defaultAvailability := storagetesting.NewAvailability(t, db, product.ID, func(p *queries.InsertAvailabilityParams) {
+		p.LocalDate = localDate
+	})
+	eveningAvailability := storagetesting.NewAvailability(t, db, product.ID, func(p *queries.InsertAvailabilityParams) {
+		p.OptionID = sql.NullInt32{Int32: evening.ID, Valid: true}
+		p.LocalDate = localDate
+	})
 
-	wantID := strconv.Itoa(int(want.ID))
-	if wantID != got.ID {
-		t.Errorf("want product ID %s, got %s", wantID, got.ID)
-	}
-	if want.Name != got.Name {
-		t.Errorf("want product name %s, got %s", want.Name, got.Name)
-	}
-}
+	t.Run("product options", func(t *testing.T) {
+		got, err := storage.NewPostgres(db, signer).Product(context.TODO(), int(product.ID), internal.CapabilityRequestPrice, "")
+		if err != nil {
+			t.Fatalf("get product: %v", err)
+		}
+		want := []internal.Option{
+			internal.OptionWithPrice{
+				OptionBase: internal.OptionBase{
+					ID:       internal.DefaultOptionID,
+					Name:     product.Name,
+					Capacity: int(product.Capacity),
+				},
+				CapabilityPrice: capabilityPrice(int(basePrice.Price), basePrice.Currency), // falls back to product price
+			},
+			internal.OptionWithPrice{
+				OptionBase: internal.OptionBase{
+					ID:       evening.Code,
+					Name:     evening.Name,
+					Capacity: int(evening.Capacity),
+				},
+				CapabilityPrice: capabilityPrice(int(eveningPrice.Price), eveningPrice.Currency),
+			},
+		}
+		if gotOptions := got.(internal.ProductWithPrice).Options; !reflect.DeepEqual(want, gotOptions) {
+			t.Errorf("want options %+v, got %+v", want, gotOptions)
+		}
+	})
 
-func assertProductWithPriceEqual(t *testing.T, want queries.ProductWithPriceRow, got internal.ProductWithPrice) {
-	t.Helper()
+	t.Run("availability by option", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		for option, want := range map[string]queries.Availability{
+			internal.DefaultOptionID: defaultAvailability,
+			evening.Code:             eveningAvailability,
+		} {
+			got, err := pg.Availabilities(context.TODO(), int(product.ID), option, localDate, localDate, internal.CapabilityRequestNone, "")
+			if err != nil {
+				t.Fatalf("get availabilities: %v", err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("want 1 availability for option %s, got %d", option, len(got))
+			}
+			if wantID := strconv.Itoa(int(want.ID)); got[0].(internal.AvailabilityBase).ID != wantID {
+				t.Errorf("want availability %s for option %s, got %s", wantID, option, got[0].(internal.AvailabilityBase).ID)
+			}
+		}
 
-	assertProductEqual(t, want.Product, got.ProductBase)
-	assertPriceEqual(t, want.Price, got.CapabilityPrice)
-}
+		got, err := pg.Availabilities(context.TODO(), int(product.ID), evening.Code, localDate, localDate, internal.CapabilityRequestPrice, "")
+		if err != nil {
+			t.Fatalf("get availabilities with price: %v", err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("want 1 availability with price, got %d", len(got))
+		}
+		assertPriceEqual(t, eveningPrice, got[0].(internal.AvailabilityWithPrice).CapabilityPrice)
 
-func assertPriceEqual(t *testing.T, want queries.Price, got internal.CapabilityPrice) {
-	t.Helper()
+		got, err = pg.Availabilities(context.TODO(), int(product.ID), "UNKNOWN", localDate, localDate, internal.CapabilityRequestNone, "")
+		if err != nil {
+			t.Fatalf("get availabilities: %v", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("want no availabilities for unknown option, got %+v", got)
+		}
+	})
 
-	if want.Price != int32(got.Price) {
-		t.Errorf("want price %d, got %d", want.Price, got.Price)
-	}
-	if want.Currency != got.Currency {
-		t.Errorf("want currency %s, got %s", want.Currency, got.Currency)
-	}
+	t.Run("create booking for option", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(eveningAvailability.ID),
+			Units:          2,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		id, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		got, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestPrice)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		booking := got.(internal.BookingWithPrice)
+		if booking.OptionID != evening.Code {
+			t.Errorf("want option %s, got %s", evening.Code, booking.OptionID)
+		}
+		if wantPrice := int(eveningPrice.Price) * 2; booking.Price != wantPrice {
+			t.Errorf("want price %d, got %d", wantPrice, booking.Price)
+		}
+	})
+
+	t.Run("create booking availability of other option", func(t *testing.T) {
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			OptionID:       internal.DefaultOptionID,
+			AvailabilityID: int(eveningAvailability.ID),
+			Units:          1,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		_, err := storage.NewPostgres(db, signer).CreateBooking(context.TODO(), params)
+		if !errors.Is(err, service.ErrNotAvailable) {
+			t.Errorf("want error %v, got %v", service.ErrNotAvailable, err)
+		}
+	})
+
+	t.Run("update booking to availability of other option", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			OptionID:       internal.DefaultOptionID,
+			AvailabilityID: int(defaultAvailability.ID),
+			Units:          1,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		id, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		err = pg.UpdateBooking(context.TODO(), service.UpdateBookingParams{
+			ID:             id,
+			UserID:         int(user.ID),
+			AvailabilityID: int(eveningAvailability.ID),
+		})
+		if !errors.Is(err, service.ErrNotAvailable) {
+			t.Errorf("want error %v, got %v", service.ErrNotAvailable, err)
+		}
+	})
+}
+
+func TestBooking(t *testing.T) {
+	db := storagetesting.Open(t)
+	user := storagetesting.NewUser(t, db)
+	product := storagetesting.NewProduct(t, db)
+	price := storagetesting.NewPrice(t, db, product.ID)
+
+	t.Run("booking not found", func(t *testing.T) {
+		_, err := storage.NewPostgres(db, signer).Booking(context.TODO(), -1, int(user.ID), internal.CapabilityRequestNone)
+		if !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		}
+	})
+
+	t.Run("create booking", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		availability := storagetesting.NewAvailability(t, db, product.ID)
+
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          3,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		id, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		wantBookingWithPrice := internal.BookingWithPrice{
+			BookingBase: internal.BookingBase{
+				ProductID:      strconv.Itoa(int(product.ID)),
+				AvailabilityID: strconv.Itoa(int(availability.ID)),
+				Status:         internal.BookingStatusReserved,
+				Units: []internal.Unit{
+					internal.UnitWithPrice{
+						CapabilityPrice: capabilityPrice(int(price.Price), price.Currency),
+					},
+					internal.UnitWithPrice{
+						CapabilityPrice: capabilityPrice(int(price.Price), price.Currency),
+					},
+					internal.UnitWithPrice{
+						CapabilityPrice: capabilityPrice(int(price.Price), price.Currency),
+					},
+				},
+			},
+			CapabilityPrice: capabilityPrice(int(price.Price)*3, price.Currency),
+		}
+		gotBookingWithPrice, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestPrice)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		assertBookingWithPrice(t, wantBookingWithPrice, gotBookingWithPrice.(internal.BookingWithPrice))
+
+		// confirm booking
+		wantConfirmedBooking := internal.BookingBase{
+			ProductID:      strconv.Itoa(int(product.ID)),
+			AvailabilityID: strconv.Itoa(int(availability.ID)),
+			Status:         internal.BookingStatusConfirmed,
+			Units:          make([]internal.Unit, 3),
+		}
+		if err := pg.ConfirmBooking(context.TODO(), service.ConfirmBookingParams{ID: id, UserID: int(user.ID)}); err != nil {
+			t.Fatalf("confirm booking: %v", err)
+		}
+		confirmedBooking, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestNone)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		keyID, publicKey := signer.PublicKey()
+		verifier := ticket.NewVerifier(map[byte]ed25519.PublicKey{keyID: publicKey})
+		for _, unit := range confirmedBooking.(internal.BookingBase).Units {
+			if unit.(internal.UnitBase).Ticket == nil {
+				t.Errorf("want ticket, got empty")
+				continue
+			}
+			claims, err := verifier.Verify(*unit.(internal.UnitBase).Ticket)
+			if err != nil {
+				t.Fatalf("verify ticket: %v", err)
+			}
+			wantUnitID := int64(platform.Must(strconv.Atoi(unit.(internal.UnitBase).ID)))
+			if claims.BookingID != int64(id) || claims.UnitID != wantUnitID || !claims.LocalDate.Equal(availability.LocalDate) {
+				t.Errorf("want claims of booking %d, unit %d on %s, got %+v", id, wantUnitID, availability.LocalDate.Format(time.DateOnly), claims)
+			}
+		}
+		assertBooking(t, wantConfirmedBooking, confirmedBooking.(internal.BookingBase))
+
+		// confirm booking again, should be no error
+		err = pg.ConfirmBooking(context.TODO(), service.ConfirmBookingParams{ID: id, UserID: int(user.ID)})
+		if err != nil {
+			t.Fatalf("confirm booking again: %v", err)
+		}
+	})
+
+	t.Run("create booking out of vacancies", func(t *testing.T) {
+		availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.Vacancies = 2
+		})
+		pg := storage.NewPostgres(db, signer)
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          3,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+
+		_, err := pg.CreateBooking(context.TODO(), params)
+		if !errors.Is(err, service.ErrNotAvailable) {
+			t.Errorf("want error %v, got %v", service.ErrNotAvailable, err)
+		}
+	})
+
+	t.Run("create booking closed availability", func(t *testing.T) {
+		availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.Closed = true
+		})
+		pg := storage.NewPostgres(db, signer)
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          1,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+
+		_, err := pg.CreateBooking(context.TODO(), params)
+		if !errors.Is(err, service.ErrNotAvailable) {
+			t.Errorf("want error %v, got %v", service.ErrNotAvailable, err)
+		}
+	})
+
+	t.Run("closing availability keeps bookings", func(t *testing.T) {
+		availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.Vacancies = 10
+		})
+		pg := storage.NewPostgres(db, signer)
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          2,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		confirmedID, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		cancelledID, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		if err := queries.New(db).CloseAvailability(context.TODO(), availability.ID); err != nil {
+			t.Fatalf("close availability: %v", err)
+		}
+
+		err = pg.ConfirmBooking(context.TODO(), service.ConfirmBookingParams{ID: confirmedID, UserID: int(user.ID)})
+		if err != nil {
+			t.Fatalf("confirm booking: %v", err)
+		}
+		if err := pg.CancelBooking(context.TODO(), cancelledID, int(user.ID), "", time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("cancel booking: %v", err)
+		}
+		assertVacancies(t, pg, availability, 8)
+
+		got, err := pg.Booking(context.TODO(), confirmedID, int(user.ID), internal.CapabilityRequestNone)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		if status := got.(internal.BookingBase).Status; status != internal.BookingStatusConfirmed {
+			t.Errorf("want status %s, got %s", internal.BookingStatusConfirmed, status)
+		}
+	})
+
+	t.Run("create booking freesale", func(t *testing.T) {
+		availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.Vacancies = 0
+			iap.Freesale = true
+		})
+		pg := storage.NewPostgres(db, signer)
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          3,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		id, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		assertVacancies(t, pg, availability, 0)
+
+		if err := pg.CancelBooking(context.TODO(), id, int(user.ID), "", time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("cancel booking: %v", err)
+		}
+		assertVacancies(t, pg, availability, 0)
+	})
+
+	t.Run("cancel booking", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		availability := storagetesting.NewAvailability(t, db, product.ID)
+
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          3,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		id, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		if err := pg.ConfirmBooking(context.TODO(), service.ConfirmBookingParams{ID: id, UserID: int(user.ID)}); err != nil {
+			t.Fatalf("confirm booking: %v", err)
+		}
+
+		if err := pg.CancelBooking(context.TODO(), id, int(user.ID), "changed plans", time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("cancel booking: %v", err)
+		}
+		cancelledBooking, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestNone)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		wantCancelledBooking := internal.BookingBase{
+			AvailabilityID: strconv.Itoa(int(availability.ID)),
+			Status:         internal.BookingStatusCancelled,
+			Units:          make([]internal.Unit, 3),
+		}
+		assertBooking(t, wantCancelledBooking, cancelledBooking.(internal.BookingBase))
+		if cancellation := cancelledBooking.(internal.BookingBase).Cancellation; cancellation == nil || cancellation.Reason != "changed plans" {
+			t.Errorf("want cancellation reason %q, got %+v", "changed plans", cancellation)
+		}
+		for _, unit := range cancelledBooking.(internal.BookingBase).Units {
+			if unit.(internal.UnitBase).Ticket != nil {
+				t.Errorf("want ticket to be invalidated, got %s", *unit.(internal.UnitBase).Ticket)
+			}
+		}
+		assertVacancies(t, pg, availability, int(availability.Vacancies))
+
+		// cancel booking again, should be no error and no vacancies returned twice
+		if err := pg.CancelBooking(context.TODO(), id, int(user.ID), "changed plans", time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("cancel booking again: %v", err)
+		}
+		assertVacancies(t, pg, availability, int(availability.Vacancies))
+
+		// confirming a cancelled booking is not allowed
+		err = pg.ConfirmBooking(context.TODO(), service.ConfirmBookingParams{ID: id, UserID: int(user.ID)})
+		var statusErr internal.BookingStatusError
+		if !errors.As(err, &statusErr) || statusErr.Status != internal.BookingStatusCancelled {
+			t.Errorf("want error %v, got %v", internal.BookingStatusError{Status: internal.BookingStatusCancelled}, err)
+		}
+	})
+
+	t.Run("cancel booking not found", func(t *testing.T) {
+		err := storage.NewPostgres(db, signer).CancelBooking(context.TODO(), -1, int(user.ID), "changed plans", time.Now().Add(time.Hour))
+		if !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		}
+	})
+
+	t.Run("expire booking", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		availability := storagetesting.NewAvailability(t, db, product.ID)
+
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          3,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(-time.Minute),
+		}
+		id, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		assertVacancies(t, pg, availability, int(availability.Vacancies)-3)
+
+		// confirming an overdue reservation is not allowed, even before it is expired
+		err = pg.ConfirmBooking(context.TODO(), service.ConfirmBookingParams{ID: id, UserID: int(user.ID)})
+		var statusErr internal.BookingStatusError
+		if !errors.As(err, &statusErr) || statusErr.Status != internal.BookingStatusExpired {
+			t.Errorf("want error %v, got %v", internal.BookingStatusError{Status: internal.BookingStatusExpired}, err)
+		}
+
+		expired, err := pg.ExpireBookings(context.TODO(), time.Now(), time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("expire bookings: %v", err)
+		}
+		if expired != 1 {
+			t.Errorf("want 1 expired booking, got %d", expired)
+		}
+		expiredBooking, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestNone)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		wantExpiredBooking := internal.BookingBase{
+			AvailabilityID: strconv.Itoa(int(availability.ID)),
+			Status:         internal.BookingStatusExpired,
+			Units:          make([]internal.Unit, 3),
+		}
+		assertBooking(t, wantExpiredBooking, expiredBooking.(internal.BookingBase))
+		assertVacancies(t, pg, availability, int(availability.Vacancies))
+
+		// cancelling an expired booking is not allowed
+		err = pg.CancelBooking(context.TODO(), id, int(user.ID), "changed plans", time.Now().Add(time.Hour))
+		if !errors.As(err, &statusErr) || statusErr.Status != internal.BookingStatusExpired {
+			t.Errorf("want error %v, got %v", internal.BookingStatusError{Status: internal.BookingStatusExpired}, err)
+		}
+
+		// already expired bookings are not expired again
+		expired, err = pg.ExpireBookings(context.TODO(), time.Now(), time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("expire bookings again: %v", err)
+		}
+		if expired != 0 {
+			t.Errorf("want 0 expired bookings, got %d", expired)
+		}
+		assertVacancies(t, pg, availability, int(availability.Vacancies))
+	})
+
+	t.Run("update booking", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.Vacancies = 5
+		})
+		otherAvailability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.Vacancies = 3
+		})
+
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          2,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		id, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		// more units on the same availability
+		updateParams := service.UpdateBookingParams{
+			ID:     id,
+			UserID: int(user.ID),
+			Units:  4,
+		}
+		if err := pg.UpdateBooking(context.TODO(), updateParams); err != nil {
+			t.Fatalf("update booking units: %v", err)
+		}
+		assertBookingUnits(t, pg, id, int(user.ID), availability.ID, 4)
+		assertVacancies(t, pg, availability, 1)
+
+		// not enough vacancies on the other availability, nothing changes
+		updateParams = service.UpdateBookingParams{
+			ID:             id,
+			UserID:         int(user.ID),
+			AvailabilityID: int(otherAvailability.ID),
+		}
+		err = pg.UpdateBooking(context.TODO(), updateParams)
+		if !errors.Is(err, service.ErrNotAvailable) {
+			t.Errorf("want error %v, got %v", service.ErrNotAvailable, err)
+		}
+		assertBookingUnits(t, pg, id, int(user.ID), availability.ID, 4)
+		assertVacancies(t, pg, availability, 1)
+		assertVacancies(t, pg, otherAvailability, 3)
+
+		// move to the other availability with fewer units
+		updateParams = service.UpdateBookingParams{
+			ID:             id,
+			UserID:         int(user.ID),
+			AvailabilityID: int(otherAvailability.ID),
+			Units:          3,
+		}
+		if err := pg.UpdateBooking(context.TODO(), updateParams); err != nil {
+			t.Fatalf("update booking availability: %v", err)
+		}
+		assertBookingUnits(t, pg, id, int(user.ID), otherAvailability.ID, 3)
+		assertVacancies(t, pg, availability, 5)
+		assertVacancies(t, pg, otherAvailability, 0)
+
+		// fewer units on the same availability
+		updateParams = service.UpdateBookingParams{
+			ID:     id,
+			UserID: int(user.ID),
+			Units:  1,
+		}
+		if err := pg.UpdateBooking(context.TODO(), updateParams); err != nil {
+			t.Fatalf("update booking units: %v", err)
+		}
+		assertBookingUnits(t, pg, id, int(user.ID), otherAvailability.ID, 1)
+		assertVacancies(t, pg, otherAvailability, 2)
+	})
+
+	t.Run("update booking not found", func(t *testing.T) {
+		params := service.UpdateBookingParams{
+			ID:     -1,
+			UserID: int(user.ID),
+			Units:  1,
+		}
+		err := storage.NewPostgres(db, signer).UpdateBooking(context.TODO(), params)
+		if !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		}
+	})
+
+	t.Run("concurrent updates", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.Vacancies = 4
+		})
+		otherAvailability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.Vacancies = 4
+		})
+		createBooking := func(availability queries.Availability) int {
+			t.Helper()
+
+			id, err := pg.CreateBooking(context.TODO(), service.CreateBookingParams{
+				ProductID:      int(product.ID),
+				AvailabilityID: int(availability.ID),
+				Units:          1,
+				UserID:         int(user.ID),
+				ExpiresAt:      time.Now().Add(time.Hour),
+			})
+			if err != nil {
+				t.Fatalf("create booking: %v", err)
+			}
+			return id
+		}
+		// bookings swap availabilities while growing
+		ids := []int{createBooking(availability), createBooking(otherAvailability)}
+		targets := []queries.Availability{otherAvailability, availability}
+
+		var wg sync.WaitGroup
+		errs := make([]error, len(ids))
+		for i, id := range ids {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = pg.UpdateBooking(context.TODO(), service.UpdateBookingParams{
+					ID:             id,
+					UserID:         int(user.ID),
+					AvailabilityID: int(targets[i].ID),
+					Units:          2,
+				})
+			}()
+		}
+		wg.Wait()
+
+		for i, err := range errs {
+			if err != nil {
+				t.Errorf("update booking %d: %v", ids[i], err)
+			}
+		}
+		assertVacancies(t, pg, availability, 2)
+		assertVacancies(t, pg, otherAvailability, 2)
+	})
+
+	t.Run("extend booking", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		availability := storagetesting.NewAvailability(t, db, product.ID)
+
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          1,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(10 * time.Minute),
+		}
+		id, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		extendParams := service.ExtendBookingParams{
+			ID:        id,
+			UserID:    int(user.ID),
+			ExpiresAt: time.Now().Add(30 * time.Minute),
+		}
+		if err := pg.ExtendBooking(context.TODO(), extendParams); err != nil {
+			t.Fatalf("extend booking: %v", err)
+		}
+		assertExpiresAt(t, pg, id, int(user.ID), extendParams.ExpiresAt)
+
+		// extension is capped by the product's maximum reservation hold
+		extendParams.ExpiresAt = time.Now().Add(time.Duration(product.MaxReservationHoldMinutes+60) * time.Minute)
+		if err := pg.ExtendBooking(context.TODO(), extendParams); err != nil {
+			t.Fatalf("extend booking over maximum: %v", err)
+		}
+		extended, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestNone)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		maxExpiresAt := time.Now().Add(time.Duration(product.MaxReservationHoldMinutes) * time.Minute)
+		if gotExpiresAt := extended.(internal.BookingBase).UTCExpiresAt; gotExpiresAt == nil || gotExpiresAt.After(maxExpiresAt) {
+			t.Errorf("want expiry before %s, got %v", maxExpiresAt, gotExpiresAt)
+		}
+
+		// confirmed bookings cannot be extended
+		if err := pg.ConfirmBooking(context.TODO(), service.ConfirmBookingParams{ID: id, UserID: int(user.ID)}); err != nil {
+			t.Fatalf("confirm booking: %v", err)
+		}
+		err = pg.ExtendBooking(context.TODO(), extendParams)
+		var statusErr internal.BookingStatusError
+		if !errors.As(err, &statusErr) || statusErr.Status != internal.BookingStatusConfirmed {
+			t.Errorf("want error %v, got %v", internal.BookingStatusError{Status: internal.BookingStatusConfirmed}, err)
+		}
+	})
+
+	t.Run("extend booking not found", func(t *testing.T) {
+		params := service.ExtendBookingParams{
+			ID:        -1,
+			UserID:    int(user.ID),
+			ExpiresAt: time.Now().Add(time.Minute),
+		}
+		err := storage.NewPostgres(db, signer).ExtendBooking(context.TODO(), params)
+		if !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		}
+	})
+
+	t.Run("booking contact", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		productRequiringContact := storagetesting.NewProduct(t, db, func(p *queries.InsertProductParams) {
+			p.ContactRequiredToConfirm = true
+		})
+		availability := storagetesting.NewAvailability(t, db, productRequiringContact.ID)
+
+		contact := internal.Contact{
+			FullName: platform.ToPtr("John Doe"),
+			Locales:  []string{"en-GB"},
+		}
+		params := service.CreateBookingParams{
+			ProductID:      int(productRequiringContact.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          1,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+			Contact:        &contact,
+		}
+		id, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		assertContact(t, pg, id, int(user.ID), contact)
+
+		// email is missing
+		err = pg.ConfirmBooking(context.TODO(), service.ConfirmBookingParams{ID: id, UserID: int(user.ID)})
+		if !errors.Is(err, service.ErrContactRequired) {
+			t.Errorf("want error %v, got %v", service.ErrContactRequired, err)
+		}
+
+		contact.EmailAddress = platform.ToPtr("john.doe@example.com")
+		confirmParams := service.ConfirmBookingParams{
+			ID:      id,
+			UserID:  int(user.ID),
+			Contact: &contact,
+		}
+		if err := pg.ConfirmBooking(context.TODO(), confirmParams); err != nil {
+			t.Fatalf("confirm booking: %v", err)
+		}
+		assertContact(t, pg, id, int(user.ID), contact)
+	})
+
+	t.Run("update booking contact", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		availability := storagetesting.NewAvailability(t, db, product.ID)
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          1,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		id, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		assertContact(t, pg, id, int(user.ID), internal.Contact{Locales: []string{}})
+
+		contact := internal.Contact{
+			FullName:    platform.ToPtr("Jane Doe"),
+			PhoneNumber: platform.ToPtr("+4915112345678"),
+			Locales:     []string{},
+			Country:     platform.ToPtr("DE"),
+			Notes:       platform.ToPtr("vegetarian"),
+		}
+		updateParams := service.UpdateBookingParams{
+			ID:      id,
+			UserID:  int(user.ID),
+			Contact: &contact,
+		}
+		if err := pg.UpdateBooking(context.TODO(), updateParams); err != nil {
+			t.Fatalf("update booking: %v", err)
+		}
+		assertContact(t, pg, id, int(user.ID), contact)
+		assertVacancies(t, pg, availability, int(availability.Vacancies)-1)
+	})
+
+	t.Run("confirm booking not found", func(t *testing.T) {
+		err := storage.NewPostgres(db, signer).ConfirmBooking(context.TODO(), service.ConfirmBookingParams{ID: -1, UserID: int(user.ID)})
+		if !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		}
+	})
+}
+
+func TestBookings(t *testing.T) {
+	db := storagetesting.Open(t)
+	user := storagetesting.NewUser(t, db)
+	otherUser := storagetesting.NewUser(t, db)
+	product := storagetesting.NewProduct(t, db)
+	otherProduct := storagetesting.NewProduct(t, db)
+	storagetesting.NewPrice(t, db, product.ID)
+	storagetesting.NewPrice(t, db, otherProduct.ID)
+	localDate := time.Now().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+	availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+		iap.LocalDate = localDate
+	})
+	otherAvailability := storagetesting.NewAvailability(t, db, otherProduct.ID, func(iap *queries.InsertAvailabilityParams) {
+		iap.LocalDate = localDate.AddDate(0, 0, 7)
+	})
+
+	pg := storage.NewPostgres(db, signer)
+	createBooking := func(userID int32, availability queries.Availability) int {
+		t.Helper()
+
+		params := service.CreateBookingParams{
+			ProductID:      int(availability.ProductID),
+			AvailabilityID: int(availability.ID),
+			Units:          2,
+			UserID:         int(userID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		id, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		return id
+	}
+	reserved := createBooking(user.ID, availability)
+	confirmed := createBooking(user.ID, availability)
+	if err := pg.ConfirmBooking(context.TODO(), service.ConfirmBookingParams{ID: confirmed, UserID: int(user.ID)}); err != nil {
+		t.Fatalf("confirm booking: %v", err)
+	}
+	otherProductBooking := createBooking(user.ID, otherAvailability)
+	createBooking(otherUser.ID, availability) // not visible to the user
+
+	tests := map[string]struct {
+		filter internal.BookingsFilter
+		want   []int
+	}{
+		"all": {
+			filter: internal.BookingsFilter{},
+			want:   []int{reserved, confirmed, otherProductBooking},
+		},
+		"by status": {
+			filter: internal.BookingsFilter{Status: internal.BookingStatusConfirmed},
+			want:   []int{confirmed},
+		},
+		"by product": {
+			filter: internal.BookingsFilter{ProductID: int(otherProduct.ID)},
+			want:   []int{otherProductBooking},
+		},
+		"by availability date": {
+			filter: internal.BookingsFilter{LocalDateStart: localDate, LocalDateEnd: localDate},
+			want:   []int{reserved, confirmed},
+		},
+		"by creation time": {
+			filter: internal.BookingsFilter{CreatedAtStart: time.Now().Add(time.Hour)},
+			want:   []int{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.filter.UserID = int(user.ID)
+			tt.filter.Limit = 10
+			page, err := pg.Bookings(context.TODO(), tt.filter, internal.CapabilityRequestNone)
+			if err != nil {
+				t.Fatalf("get bookings: %v", err)
+			}
+
+			assertBookingIDs(t, tt.want, page.Bookings)
+			if page.NextCursor != 0 {
+				t.Errorf("want no next cursor, got %d", page.NextCursor)
+			}
+		})
+	}
+
+	t.Run("paginate with price", func(t *testing.T) {
+		filter := internal.BookingsFilter{
+			UserID: int(user.ID),
+			Limit:  2,
+		}
+		page, err := pg.Bookings(context.TODO(), filter, internal.CapabilityRequestPrice)
+		if err != nil {
+			t.Fatalf("get bookings: %v", err)
+		}
+		assertBookingIDs(t, []int{reserved, confirmed}, page.Bookings)
+		if page.NextCursor != confirmed {
+			t.Errorf("want next cursor %d, got %d", confirmed, page.NextCursor)
+		}
+
+		filter.Cursor = page.NextCursor
+		page, err = pg.Bookings(context.TODO(), filter, internal.CapabilityRequestPrice)
+		if err != nil {
+			t.Fatalf("get bookings: %v", err)
+		}
+		assertBookingIDs(t, []int{otherProductBooking}, page.Bookings)
+		if page.NextCursor != 0 {
+			t.Errorf("want no next cursor, got %d", page.NextCursor)
+		}
+	})
+
+	t.Run("by uuid and reseller reference", func(t *testing.T) {
+		params := service.CreateBookingParams{
+			ProductID:         int(product.ID),
+			AvailabilityID:    int(availability.ID),
+			Units:             1,
+			UserID:            int(user.ID),
+			ExpiresAt:         time.Now().Add(time.Hour),
+			UUID:              uuid.New(),
+			ResellerReference: "REF-123",
+		}
+		id, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		for _, filter := range []internal.BookingsFilter{
+			{UserID: int(user.ID), UUID: params.UUID, Limit: 10},
+			{UserID: int(user.ID), ResellerReference: params.ResellerReference, Limit: 10},
+		} {
+			page, err := pg.Bookings(context.TODO(), filter, internal.CapabilityRequestNone)
+			if err != nil {
+				t.Fatalf("get bookings: %v", err)
+			}
+			assertBookingIDs(t, []int{id}, page.Bookings)
+
+			booking := page.Bookings[0].(internal.BookingBase)
+			if booking.UUID != params.UUID.String() {
+				t.Errorf("want uuid %s, got %s", params.UUID, booking.UUID)
+			}
+			if booking.ResellerReference == nil || *booking.ResellerReference != params.ResellerReference {
+				t.Errorf("want reseller reference %q, got %v", params.ResellerReference, booking.ResellerReference)
+			}
+		}
+	})
+
+	t.Run("duplicate uuid or reseller reference", func(t *testing.T) {
+		params := service.CreateBookingParams{
+			ProductID:         int(product.ID),
+			AvailabilityID:    int(availability.ID),
+			Units:             1,
+			UserID:            int(user.ID),
+			ExpiresAt:         time.Now().Add(time.Hour),
+			UUID:              uuid.New(),
+			ResellerReference: "REF-456",
+		}
+		if _, err := pg.CreateBooking(context.TODO(), params); err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		duplicateUUID := params
+		duplicateUUID.ResellerReference = ""
+		duplicateReference := params
+		duplicateReference.UUID = uuid.New()
+		for _, duplicate := range []service.CreateBookingParams{duplicateUUID, duplicateReference} {
+			_, err := pg.CreateBooking(context.TODO(), duplicate)
+			if !errors.Is(err, service.ErrAlreadyExists) {
+				t.Errorf("want error %v, got %v", service.ErrAlreadyExists, err)
+			}
+		}
+
+		// uniqueness is per user
+		params.UserID = int(otherUser.ID)
+		if _, err := pg.CreateBooking(context.TODO(), params); err != nil {
+			t.Fatalf("create booking for other user: %v", err)
+		}
+		// failed duplicates do not hold vacancies: 3 bookings of 2 units above, 3 of 1 unit here
+		assertVacancies(t, pg, availability, int(availability.Vacancies)-3*2-3*1)
+	})
+}
+
+func TestUnitTicket(t *testing.T) {
+	db := storagetesting.Open(t)
+	user := storagetesting.NewUser(t, db)
+	product := storagetesting.NewProduct(t, db, func(ipp *queries.InsertProductParams) {
+		ipp.DeliveryFormat = internal.DeliveryFormatCode128
+	})
+	availability := storagetesting.NewAvailability(t, db, product.ID)
+	pg := storage.NewPostgres(db, signer)
+
+	params := service.CreateBookingParams{
+		ProductID:      int(product.ID),
+		AvailabilityID: int(availability.ID),
+		Units:          1,
+		UserID:         int(user.ID),
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}
+	id, err := pg.CreateBooking(context.TODO(), params)
+	if err != nil {
+		t.Fatalf("create booking: %v", err)
+	}
+	booking, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestNone)
+	if err != nil {
+		t.Fatalf("get booking: %v", err)
+	}
+	unitID := platform.Must(strconv.Atoi(booking.(internal.BookingBase).Units[0].(internal.UnitBase).ID))
+
+	t.Run("no ticket before confirmation", func(t *testing.T) {
+		_, err := pg.UnitTicket(context.TODO(), id, unitID, int(user.ID))
+		if !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		}
+	})
+
+	if err := pg.ConfirmBooking(context.TODO(), service.ConfirmBookingParams{ID: id, UserID: int(user.ID)}); err != nil {
+		t.Fatalf("confirm booking: %v", err)
+	}
+
+	t.Run("unit ticket", func(t *testing.T) {
+		got, err := pg.UnitTicket(context.TODO(), id, unitID, int(user.ID))
+		if err != nil {
+			t.Fatalf("get unit ticket: %v", err)
+		}
+		if got.Ticket == "" || got.DeliveryFormat != internal.DeliveryFormatCode128 {
+			t.Errorf("want %s ticket, got %+v", internal.DeliveryFormatCode128, got)
+		}
+	})
+
+	t.Run("unit ticket of other user", func(t *testing.T) {
+		otherUser := storagetesting.NewUser(t, db)
+		_, err := pg.UnitTicket(context.TODO(), id, unitID, int(otherUser.ID))
+		if !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		}
+	})
+}
+
+func TestDeliveryMethods(t *testing.T) {
+	db := storagetesting.Open(t)
+	user := storagetesting.NewUser(t, db)
+	pg := storage.NewPostgres(db, signer)
+	keyID, publicKey := signer.PublicKey()
+	verifier := ticket.NewVerifier(map[byte]ed25519.PublicKey{keyID: publicKey})
+
+	tests := map[string]struct {
+		methods     []internal.DeliveryMethod
+		wantTickets bool
+		wantVoucher bool
+	}{
+		"tickets": {
+			methods:     []internal.DeliveryMethod{internal.DeliveryMethodTicket},
+			wantTickets: true,
+		},
+		"voucher": {
+			methods:     []internal.DeliveryMethod{internal.DeliveryMethodVoucher},
+			wantVoucher: true,
+		},
+		"tickets and voucher": {
+			methods:     []internal.DeliveryMethod{internal.DeliveryMethodTicket, internal.DeliveryMethodVoucher},
+			wantTickets: true,
+			wantVoucher: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			product := storagetesting.NewProduct(t, db, func(ipp *queries.InsertProductParams) {
+				ipp.DeliveryMethods = tt.methods
+			})
+			availability := storagetesting.NewAvailability(t, db, product.ID)
+
+			id, err := pg.CreateBooking(context.TODO(), service.CreateBookingParams{
+				ProductID:      int(product.ID),
+				AvailabilityID: int(availability.ID),
+				Units:          2,
+				UserID:         int(user.ID),
+				ExpiresAt:      time.Now().Add(time.Hour),
+			})
+			if err != nil {
+				t.Fatalf("create booking: %v", err)
+			}
+			if err := pg.ConfirmBooking(context.TODO(), service.ConfirmBookingParams{ID: id, UserID: int(user.ID)}); err != nil {
+				t.Fatalf("confirm booking: %v", err)
+			}
+
+			got, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestNone)
+			if err != nil {
+				t.Fatalf("get booking: %v", err)
+			}
+			booking := got.(internal.BookingBase)
+			for _, unit := range booking.Units {
+				if hasTicket := unit.(internal.UnitBase).Ticket != nil; hasTicket != tt.wantTickets {
+					t.Errorf("want unit ticket %t, got %t", tt.wantTickets, hasTicket)
+				}
+			}
+			if hasVoucher := booking.Voucher != nil; hasVoucher != tt.wantVoucher {
+				t.Fatalf("want voucher %t, got %t", tt.wantVoucher, hasVoucher)
+			}
+			if !tt.wantVoucher {
+				return
+			}
+
+			claims, err := verifier.Verify(*booking.Voucher)
+			if err != nil {
+				t.Fatalf("verify voucher: %v", err)
+			}
+			if claims.BookingID != int64(id) || claims.UnitID != 0 {
+				t.Errorf("want voucher of booking %d, got %+v", id, claims)
+			}
+
+			// cancellation invalidates the voucher
+			if err := pg.CancelBooking(context.TODO(), id, int(user.ID), "", time.Now().Add(time.Hour)); err != nil {
+				t.Fatalf("cancel booking: %v", err)
+			}
+			cancelled, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestNone)
+			if err != nil {
+				t.Fatalf("get booking: %v", err)
+			}
+			if voucher := cancelled.(internal.BookingBase).Voucher; voucher != nil {
+				t.Errorf("want voucher to be invalidated, got %s", *voucher)
+			}
+		})
+	}
+}
+
+func TestCurrencies(t *testing.T) {
+	db := storagetesting.Open(t)
+	user := storagetesting.NewUser(t, db)
+	pg := storage.NewPostgres(db, signer)
+	product := storagetesting.NewProduct(t, db)
+	eurPrice := storagetesting.NewPrice(t, db, product.ID)
+	usdPrice := storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+		p.Currency = "USD"
+	})
+	availability := storagetesting.NewAvailability(t, db, product.ID, func(p *queries.InsertAvailabilityParams) {
+		p.Vacancies = 10
+	})
+
+	t.Run("default currency", func(t *testing.T) {
+		got, err := pg.Product(context.TODO(), int(product.ID), internal.CapabilityRequestPrice, "")
+		if err != nil {
+			t.Fatalf("get product: %v", err)
+		}
+		gotProduct := got.(internal.ProductWithPrice)
+		assertPriceEqual(t, eurPrice, gotProduct.CapabilityPrice)
+		if gotProduct.DefaultCurrency != "EUR" {
+			t.Errorf("want default currency EUR, got %s", gotProduct.DefaultCurrency)
+		}
+		if want := []string{"EUR", "USD"}; !reflect.DeepEqual(want, gotProduct.AvailableCurrencies) {
+			t.Errorf("want available currencies %v, got %v", want, gotProduct.AvailableCurrencies)
+		}
+	})
+
+	t.Run("requested currency", func(t *testing.T) {
+		got, err := pg.Product(context.TODO(), int(product.ID), internal.CapabilityRequestPrice, "USD")
+		if err != nil {
+			t.Fatalf("get product: %v", err)
+		}
+		assertPriceEqual(t, usdPrice, got.(internal.ProductWithPrice).CapabilityPrice)
+
+		availabilities, err := pg.Availabilities(context.TODO(), int(product.ID), internal.DefaultOptionID, availability.LocalDate, availability.LocalDate, internal.CapabilityRequestPrice, "USD")
+		if err != nil {
+			t.Fatalf("get availabilities: %v", err)
+		}
+		if len(availabilities) != 1 {
+			t.Fatalf("want 1 availability, got %d", len(availabilities))
+		}
+		assertPriceEqual(t, usdPrice, availabilities[0].(internal.AvailabilityWithPrice).CapabilityPrice)
+	})
+
+	t.Run("single base price per currency", func(t *testing.T) {
+		_, err := queries.New(db).InsertPrice(context.TODO(), queries.InsertPriceParams{
+			ProductID: product.ID,
+			Price:     usdPrice.Price + 1,
+			Currency:  "USD",
+		})
+		if err == nil {
+			t.Fatal("want error inserting second base price in USD")
+		}
+
+		got, err := pg.Product(context.TODO(), int(product.ID), internal.CapabilityRequestPrice, "USD")
+		if err != nil {
+			t.Fatalf("get product: %v", err)
+		}
+		assertPriceEqual(t, usdPrice, got.(internal.ProductWithPrice).CapabilityPrice)
+	})
+
+	t.Run("default currency must be priced", func(t *testing.T) {
+		gbpProduct := storagetesting.NewProduct(t, db, func(p *queries.InsertProductParams) {
+			p.DefaultCurrency = "GBP"
+		})
+		_, err := queries.New(db).InsertPrice(context.TODO(), queries.InsertPriceParams{
+			ProductID: gbpProduct.ID,
+			Price:     100,
+			Currency:  "USD",
+		})
+		if err == nil {
+			t.Error("want error pricing product only in other than default currency")
+		}
+
+		_, err = db.ExecContext(context.TODO(), "UPDATE prices SET deleted_at = now() WHERE id = $1", eurPrice.ID)
+		if err == nil {
+			t.Error("want error deleting price in default currency")
+		}
+		_, err = db.ExecContext(context.TODO(), "UPDATE products SET default_currency = 'GBP' WHERE id = $1", product.ID)
+		if err == nil {
+			t.Error("want error changing default currency to not priced one")
+		}
+
+		got, err := pg.Product(context.TODO(), int(product.ID), internal.CapabilityRequestPrice, "")
+		if err != nil {
+			t.Fatalf("get product: %v", err)
+		}
+		assertPriceEqual(t, eurPrice, got.(internal.ProductWithPrice).CapabilityPrice)
+	})
+
+	t.Run("unsupported currency", func(t *testing.T) {
+		_, err := pg.Product(context.TODO(), int(product.ID), internal.CapabilityRequestPrice, "GBP")
+		var currencyErr internal.UnsupportedCurrencyError
+		if !errors.As(err, &currencyErr) {
+			t.Fatalf("want UnsupportedCurrencyError, got %v", err)
+		}
+		if want := []string{"EUR", "USD"}; !reflect.DeepEqual(want, currencyErr.Available) {
+			t.Errorf("want available currencies %v, got %v", want, currencyErr.Available)
+		}
+
+		_, err = pg.CreateBooking(context.TODO(), service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          1,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+			Currency:       "GBP",
+		})
+		if !errors.As(err, &currencyErr) {
+			t.Fatalf("want UnsupportedCurrencyError, got %v", err)
+		}
+	})
+
+	t.Run("unsupported currency without capability", func(t *testing.T) {
+		var currencyErr internal.UnsupportedCurrencyError
+		_, err := pg.Product(context.TODO(), int(product.ID), internal.CapabilityRequestNone, "GBP")
+		if !errors.As(err, &currencyErr) {
+			t.Errorf("get product: want UnsupportedCurrencyError, got %v", err)
+		}
+		_, err = pg.Availabilities(context.TODO(), int(product.ID), internal.DefaultOptionID, availability.LocalDate, availability.LocalDate, internal.CapabilityRequestNone, "GBP")
+		if !errors.As(err, &currencyErr) {
+			t.Errorf("get availabilities: want UnsupportedCurrencyError, got %v", err)
+		}
+		_, err = pg.AvailabilityCalendar(context.TODO(), int(product.ID), availability.LocalDate, availability.LocalDate, internal.CapabilityRequestNone, "GBP")
+		if !errors.As(err, &currencyErr) {
+			t.Errorf("get availability calendar: want UnsupportedCurrencyError, got %v", err)
+		}
+	})
+
+	t.Run("products unsupported currency", func(t *testing.T) {
+		for _, capability := range []internal.CapabilityRequest{internal.CapabilityRequestNone, internal.CapabilityRequestPrice} {
+			_, err := pg.Products(context.TODO(), capability, "JPY") // no product is priced in it
+			var currencyErr internal.UnsupportedCurrencyError
+			if !errors.As(err, &currencyErr) {
+				t.Errorf("want UnsupportedCurrencyError with capability %q, got %v", capability, err)
+			}
+		}
+	})
+
+	t.Run("products skip missing currency", func(t *testing.T) {
+		got, err := pg.Products(context.TODO(), internal.CapabilityRequestPrice, "USD")
+		if err != nil {
+			t.Fatalf("get products: %v", err)
+		}
+		for _, p := range got {
+			if currency := p.(internal.ProductWithPrice).Currency; currency != "USD" {
+				t.Errorf("want currency USD, got %s", currency)
+			}
+		}
+	})
+
+	t.Run("booking in currency", func(t *testing.T) {
+		id, err := pg.CreateBooking(context.TODO(), service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          2,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+			Currency:       "USD",
+		})
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		got, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestPrice)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		want := capabilityPrice(2*int(usdPrice.Price), "USD")
+		if gotPrice := got.(internal.BookingWithPrice).CapabilityPrice; !reflect.DeepEqual(want, gotPrice) {
+			t.Errorf("want price %+v, got %+v", want, gotPrice)
+		}
+	})
+}
+
+func TestPriceRules(t *testing.T) {
+	db := storagetesting.Open(t)
+	user := storagetesting.NewUser(t, db)
+	pg := storage.NewPostgres(db, signer)
+	product := storagetesting.NewProduct(t, db)
+	storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+		p.Price = 100
+	})
+	storagetesting.NewUnitType(t, db, product.ID, func(p *queries.InsertUnitTypeParams) {
+		p.Code = "adult"
+	})
+	child := storagetesting.NewUnitType(t, db, product.ID, func(p *queries.InsertUnitTypeParams) {
+		p.Code = "child"
+	})
+	storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+		p.UnitTypeID = sql.NullInt32{Int32: child.ID, Valid: true}
+		p.Price = 50
+	})
+	availability := func(year int, month time.Month, day int) queries.Availability {
+		return storagetesting.NewAvailability(t, db, product.ID, func(p *queries.InsertAvailabilityParams) {
+			p.LocalDate = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+			p.Vacancies = 10
+		})
+	}
+	winterWeekday := availability(2025, time.January, 8) // Wednesday
+	winterWeekend := availability(2025, time.January, 4) // Saturday
+	summerWeekday := availability(2025, time.June, 2)    // Monday
+	summerWeekend := availability(2025, time.June, 7)    // Saturday
+	summerSale := availability(2025, time.June, 3)       // Tuesday
+	summerOtherSlot := availability(2025, time.June, 3)  // Tuesday, another slot
+	storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+		p.LocalDateStart = sql.NullTime{Time: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+		p.LocalDateEnd = sql.NullTime{Time: time.Date(2025, time.August, 31, 0, 0, 0, 0, time.UTC), Valid: true}
+		p.Price = 150
+		p.Priority = 1
+	})
+	storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+		p.Weekdays = []int32{6, 7}
+		p.Price = 120
+		p.Priority = 2
+	})
+	storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+		p.AvailabilityID = sql.NullInt32{Int32: summerSale.ID, Valid: true}
+		p.Price = 80
+		p.Priority = 3
+	})
+
+	wantPrices := map[int32]int{
+		winterWeekday.ID:   100, // base price
+		winterWeekend.ID:   120,
+		summerWeekday.ID:   150,
+		summerWeekend.ID:   120, // weekend wins over season
+		summerSale.ID:      80,
+		summerOtherSlot.ID: 150,
+	}
+
+	t.Run("availabilities", func(t *testing.T) {
+		got, err := pg.Availabilities(context.TODO(), int(product.ID), internal.DefaultOptionID, winterWeekend.LocalDate, summerWeekend.LocalDate, internal.CapabilityRequestPrice, "")
+		if err != nil {
+			t.Fatalf("get availabilities: %v", err)
+		}
+		if len(got) != len(wantPrices) {
+			t.Fatalf("want %d availabilities, got %d", len(wantPrices), len(got))
+		}
+		for _, a := range got {
+			a := a.(internal.AvailabilityWithPrice)
+			id, _ := strconv.Atoi(a.ID)
+			if want := wantPrices[int32(id)]; a.Price != want {
+				t.Errorf("want availability %s on %s price %d, got %d", a.ID, time.Time(a.LocalDate).Format(time.DateOnly), want, a.Price)
+			}
+		}
+	})
+
+	t.Run("calendar", func(t *testing.T) {
+		got, err := pg.AvailabilityCalendar(context.TODO(), int(product.ID), summerWeekday.LocalDate, summerSale.LocalDate, internal.CapabilityRequestPrice, "")
+		if err != nil {
+			t.Fatalf("get availability calendar: %v", err)
+		}
+		want := []int{150, 80} // lowest price of the date
+		if len(got) != len(want) {
+			t.Fatalf("want %d dates, got %d", len(want), len(got))
+		}
+		for i, date := range got {
+			if price := date.(internal.AvailabilityCalendarWithPrice).Price; price != want[i] {
+				t.Errorf("want date %d price %d, got %d", i, want[i], price)
+			}
+		}
+	})
+
+	t.Run("booking", func(t *testing.T) {
+		id, err := pg.CreateBooking(context.TODO(), service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(summerWeekend.ID),
+			Units:          2,
+			UnitIDs:        []string{"adult", "child"},
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		got, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestPrice)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		// the weekend rule wins over the child's base price, units cost the advertised price of the availability
+		if want, price := 2*wantPrices[summerWeekend.ID], got.(internal.BookingWithPrice).Price; price != want {
+			t.Errorf("want total price %d, got %d", want, price)
+		}
+	})
+
+	t.Run("booking with unit type rule", func(t *testing.T) {
+		storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+			p.UnitTypeID = sql.NullInt32{Int32: child.ID, Valid: true}
+			p.Weekdays = []int32{6, 7}
+			p.Price = 60
+			p.Priority = 2
+		})
+
+		for _, tc := range []struct {
+			name         string
+			availability queries.Availability
+			wantPrice    int
+		}{
+			{
+				name:         "unit type rule wins over rule of the same priority",
+				availability: summerWeekend,
+				wantPrice:    120 + 60,
+			},
+			{
+				name:         "seasonal rule wins over unit type base price",
+				availability: summerWeekday,
+				wantPrice:    150 + 150,
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				id, err := pg.CreateBooking(context.TODO(), service.CreateBookingParams{
+					ProductID:      int(product.ID),
+					AvailabilityID: int(tc.availability.ID),
+					Units:          2,
+					UnitIDs:        []string{"adult", "child"},
+					UserID:         int(user.ID),
+					ExpiresAt:      time.Now().Add(time.Hour),
+				})
+				if err != nil {
+					t.Fatalf("create booking: %v", err)
+				}
+
+				got, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestPrice)
+				if err != nil {
+					t.Fatalf("get booking: %v", err)
+				}
+				if price := got.(internal.BookingWithPrice).Price; price != tc.wantPrice {
+					t.Errorf("want total price %d, got %d", tc.wantPrice, price)
+				}
+			})
+		}
+	})
+}
+
+func TestPriceSnapshot(t *testing.T) {
+	db := storagetesting.Open(t)
+	user := storagetesting.NewUser(t, db)
+	pg := storage.NewPostgres(db, signer)
+	product := storagetesting.NewProduct(t, db)
+	storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+		p.Price = 100
+	})
+	withVacancies := func(p *queries.InsertAvailabilityParams) { p.Vacancies = 10 }
+	availability := storagetesting.NewAvailability(t, db, product.ID, withVacancies)
+	otherAvailability := storagetesting.NewAvailability(t, db, product.ID, withVacancies)
+	// raises the price of the availability from now on
+	raisePrice := func(availabilityID int32, price int32) {
+		storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+			p.AvailabilityID = sql.NullInt32{Int32: availabilityID, Valid: true}
+			p.Price = price
+			p.Priority = price // later raises win
+		})
+	}
+	assertUnitPrices := func(t *testing.T, id int, want ...int) {
+		t.Helper()
+
+		got, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestPrice)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		booking := got.(internal.BookingWithPrice)
+		if len(booking.Units) != len(want) {
+			t.Fatalf("want %d units, got %d", len(want), len(booking.Units))
+		}
+		var wantTotal int
+		for i, unit := range booking.Units {
+			if price := unit.(internal.UnitWithPrice).Price; price != want[i] {
+				t.Errorf("want unit %d price %d, got %d", i, want[i], price)
+			}
+			wantTotal += want[i]
+		}
+		if booking.Price != wantTotal {
+			t.Errorf("want total price %d, got %d", wantTotal, booking.Price)
+		}
+	}
+
+	id, err := pg.CreateBooking(context.TODO(), service.CreateBookingParams{
+		ProductID:      int(product.ID),
+		AvailabilityID: int(availability.ID),
+		Units:          2,
+		UserID:         int(user.ID),
+		ExpiresAt:      time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("create booking: %v", err)
+	}
+
+	t.Run("price change keeps reserved prices", func(t *testing.T) {
+		raisePrice(availability.ID, 150)
+
+		assertUnitPrices(t, id, 100, 100)
+	})
+
+	t.Run("added units get the current price", func(t *testing.T) {
+		err := pg.UpdateBooking(context.TODO(), service.UpdateBookingParams{
+			ID:     id,
+			UserID: int(user.ID),
+			Units:  3,
+		})
+		if err != nil {
+			t.Fatalf("update booking: %v", err)
+		}
+
+		assertUnitPrices(t, id, 100, 100, 150)
+	})
+
+	t.Run("new availability reprices units", func(t *testing.T) {
+		raisePrice(otherAvailability.ID, 200)
+		err := pg.UpdateBooking(context.TODO(), service.UpdateBookingParams{
+			ID:             id,
+			UserID:         int(user.ID),
+			AvailabilityID: int(otherAvailability.ID),
+		})
+		if err != nil {
+			t.Fatalf("update booking: %v", err)
+		}
+
+		assertUnitPrices(t, id, 200, 200, 200)
+	})
+}
+
+func TestPricing(t *testing.T) {
+	db := storagetesting.Open(t)
+	user := storagetesting.NewUser(t, db)
+	pg := storage.NewPostgres(db, signer)
+	product := storagetesting.NewProduct(t, db)
+	storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+		p.Price = 100
+		p.Original = sql.NullInt32{Int32: 120, Valid: true}
+		p.Net = sql.NullInt32{Int32: 80, Valid: true}
+		p.IncludedTaxes = internal.IncludedTaxes{{Name: "VAT 19%", Retail: 15, Net: platform.ToPtr(12)}}
+	})
+	storagetesting.NewUnitType(t, db, product.ID, func(p *queries.InsertUnitTypeParams) {
+		p.Code = "adult"
+	})
+	child := storagetesting.NewUnitType(t, db, product.ID, func(p *queries.InsertUnitTypeParams) {
+		p.Code = "child"
+	})
+	storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+		p.UnitTypeID = sql.NullInt32{Int32: child.ID, Valid: true}
+		p.Price = 50 // net is unknown, original is the retail price
+		p.IncludedTaxes = internal.IncludedTaxes{{Name: "VAT 19%", Retail: 7}}
+	})
+	availability := storagetesting.NewAvailability(t, db, product.ID, func(p *queries.InsertAvailabilityParams) {
+		p.Vacancies = 10
+	})
+	bookingPricing := func(t *testing.T, unitIDs ...string) internal.Pricing {
+		t.Helper()
+
+		id, err := pg.CreateBooking(context.TODO(), service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          len(unitIDs),
+			UnitIDs:        unitIDs,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		got, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestPrice)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		return got.(internal.BookingWithPrice).Pricing
+	}
+
+	t.Run("product", func(t *testing.T) {
+		got, err := pg.Product(context.TODO(), int(product.ID), internal.CapabilityRequestPrice, "")
+		if err != nil {
+			t.Fatalf("get product: %v", err)
+		}
+		want := internal.Pricing{
+			Original:          120,
+			Retail:            100,
+			Net:               platform.ToPtr(80),
+			Currency:          "EUR",
+			CurrencyPrecision: 2,
+			IncludedTaxes:     internal.IncludedTaxes{{Name: "VAT 19%", Retail: 15, Net: platform.ToPtr(12)}},
+		}
+		if gotPricing := got.(internal.ProductWithPrice).Pricing; !reflect.DeepEqual(want, gotPricing) {
+			t.Errorf("want pricing %+v, got %+v", want, gotPricing)
+		}
+	})
+
+	t.Run("booking adds up units", func(t *testing.T) {
+		want := internal.Pricing{
+			Original:          240,
+			Retail:            200,
+			Net:               platform.ToPtr(160),
+			Currency:          "EUR",
+			CurrencyPrecision: 2,
+			IncludedTaxes:     internal.IncludedTaxes{{Name: "VAT 19%", Retail: 30, Net: platform.ToPtr(24)}},
+		}
+		if got := bookingPricing(t, "adult", "adult"); !reflect.DeepEqual(want, got) {
+			t.Errorf("want pricing %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("booking with unknown net", func(t *testing.T) {
+		want := internal.Pricing{
+			Original:          170,
+			Retail:            150,
+			Currency:          "EUR",
+			CurrencyPrecision: 2,
+			IncludedTaxes:     internal.IncludedTaxes{{Name: "VAT 19%", Retail: 22}},
+		}
+		if got := bookingPricing(t, "adult", "child"); !reflect.DeepEqual(want, got) {
+			t.Errorf("want pricing %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("currency precision", func(t *testing.T) {
+		yenProduct := storagetesting.NewProduct(t, db, func(p *queries.InsertProductParams) {
+			p.DefaultCurrency = "JPY"
+		})
+		storagetesting.NewPrice(t, db, yenProduct.ID, func(p *queries.InsertPriceParams) {
+			p.Currency = "JPY"
+		})
+
+		got, err := pg.Product(context.TODO(), int(yenProduct.ID), internal.CapabilityRequestPrice, "")
+		if err != nil {
+			t.Fatalf("get product: %v", err)
+		}
+		if precision := got.(internal.ProductWithPrice).Pricing.CurrencyPrecision; precision != 0 {
+			t.Errorf("want currency precision 0, got %d", precision)
+		}
+	})
+}
+
+func TestPromoCodes(t *testing.T) {
+	db := storagetesting.Open(t)
+	user := storagetesting.NewUser(t, db)
+	pg := storage.NewPostgres(db, signer)
+	product := storagetesting.NewProduct(t, db)
+	storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+		p.Price = 100
+	})
+	availability := storagetesting.NewAvailability(t, db, product.ID, func(p *queries.InsertAvailabilityParams) {
+		p.Vacancies = 100
+	})
+	book := func(promoCode string, units int) (int, error) {
+		return pg.CreateBooking(context.TODO(), service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          units,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+			PromoCode:      promoCode,
+		})
+	}
+	bookingWithPrice := func(t *testing.T, id int) internal.BookingWithPrice {
+		t.Helper()
+
+		got, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestPrice)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		return got.(internal.BookingWithPrice)
+	}
+	assertUses := func(t *testing.T, code string, want int32) {
+		t.Helper()
+
+		promoCode, err := queries.New(db).PromoCodeForUpdate(context.TODO(), code)
+		if err != nil {
+			t.Fatalf("get promo code: %v", err)
+		}
+		if promoCode.Uses != want {
+			t.Errorf("want %d uses, got %d", want, promoCode.Uses)
+		}
+	}
+
+	t.Run("percentage", func(t *testing.T) {
+		promoCode := storagetesting.NewPromoCode(t, db, func(p *queries.InsertPromoCodeParams) {
+			p.Code = "SUMMER10"
+			p.Amount = 10
+		})
+
+		id, err := book("summer10", 2) // case insensitive
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		got := bookingWithPrice(t, id)
+		want := &internal.Discount{
+			PromoCode: promoCode.Code,
+			Type:      internal.DiscountTypePercentage,
+			Value:     10,
+			Amount:    20,
+			Currency:  "EUR",
+		}
+		if !reflect.DeepEqual(want, got.Discount) {
+			t.Errorf("want discount %+v, got %+v", want, got.Discount)
+		}
+		if got.Price != 180 || got.Pricing.Retail != 180 {
+			t.Errorf("want discounted price 180, got %d and retail %d", got.Price, got.Pricing.Retail)
+		}
+		if got.Pricing.Original != 200 {
+			t.Errorf("want original price 200, got %d", got.Pricing.Original)
+		}
+		for i, unit := range got.Units {
+			if price := unit.(internal.UnitWithPrice).Price; price != 100 {
+				t.Errorf("want unit %d price 100, got %d", i, price)
+			}
+		}
+		assertUses(t, promoCode.Code, 1)
+	})
+
+	t.Run("fixed up to the total", func(t *testing.T) {
+		promoCode := storagetesting.NewPromoCode(t, db, func(p *queries.InsertPromoCodeParams) {
+			p.DiscountType = queries.DiscountTypeFIXED
+			p.Amount = 500
+			p.Currency = sql.NullString{String: "EUR", Valid: true}
+		})
+
+		id, err := book(promoCode.Code, 2)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		got := bookingWithPrice(t, id)
+		if got.Discount == nil || got.Discount.Amount != 200 {
+			t.Errorf("want discount amount 200, got %+v", got.Discount)
+		}
+		if got.Price != 0 {
+			t.Errorf("want price 0, got %d", got.Price)
+		}
+	})
+
+	t.Run("without promo code", func(t *testing.T) {
+		id, err := book("", 1)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		if got := bookingWithPrice(t, id); got.Discount != nil || got.Price != 100 {
+			t.Errorf("want price 100 without discount, got %d with %+v", got.Price, got.Discount)
+		}
+	})
+
+	t.Run("not available keeps the promo code", func(t *testing.T) {
+		promoCode := storagetesting.NewPromoCode(t, db)
+
+		_, err := book(promoCode.Code, 1000)
+		if !errors.Is(err, service.ErrNotAvailable) {
+			t.Fatalf("want error %v, got %v", service.ErrNotAvailable, err)
+		}
+
+		assertUses(t, promoCode.Code, 0)
+	})
+
+	t.Run("net and taxes discounted", func(t *testing.T) {
+		netProduct := storagetesting.NewProduct(t, db)
+		storagetesting.NewPrice(t, db, netProduct.ID, func(p *queries.InsertPriceParams) {
+			p.Price = 100
+			p.Net = sql.NullInt32{Int32: 80, Valid: true}
+			p.IncludedTaxes = internal.IncludedTaxes{{Name: "VAT 19%", Retail: 15, Net: platform.ToPtr(12)}}
+		})
+		netAvailability := storagetesting.NewAvailability(t, db, netProduct.ID, func(p *queries.InsertAvailabilityParams) {
+			p.Vacancies = 10
+		})
+		promoCode := storagetesting.NewPromoCode(t, db) // 10%
+
+		id, err := pg.CreateBooking(context.TODO(), service.CreateBookingParams{
+			ProductID:      int(netProduct.ID),
+			AvailabilityID: int(netAvailability.ID),
+			Units:          2,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+			PromoCode:      promoCode.Code,
+		})
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		got := bookingWithPrice(t, id)
+		if got.Pricing.Retail != 180 {
+			t.Errorf("want retail 180, got %d", got.Pricing.Retail)
+		}
+		if got.Pricing.Net == nil || *got.Pricing.Net != 144 {
+			t.Errorf("want net 144, got %v", got.Pricing.Net)
+		}
+		want := internal.IncludedTaxes{{Name: "VAT 19%", Retail: 27, Net: platform.ToPtr(21)}}
+		if !reflect.DeepEqual(want, got.Pricing.IncludedTaxes) {
+			t.Errorf("want included taxes %+v, got %+v", want, got.Pricing.IncludedTaxes)
+		}
+	})
+
+	t.Run("cancel gives the use back", func(t *testing.T) {
+		promoCode := storagetesting.NewPromoCode(t, db, func(p *queries.InsertPromoCodeParams) {
+			p.MaxUses = sql.NullInt32{Int32: 1, Valid: true}
+		})
+
+		id, err := book(promoCode.Code, 1)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		assertUses(t, promoCode.Code, 1)
+
+		if err := pg.CancelBooking(context.TODO(), id, int(user.ID), "changed plans", time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("cancel booking: %v", err)
+		}
+		assertUses(t, promoCode.Code, 0)
+
+		if _, err := book(promoCode.Code, 1); err != nil {
+			t.Errorf("want promo code usable again, got %v", err)
+		}
+	})
+
+	t.Run("expiration gives the use back", func(t *testing.T) {
+		promoCode := storagetesting.NewPromoCode(t, db)
+
+		if _, err := book(promoCode.Code, 1); err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		assertUses(t, promoCode.Code, 1)
+
+		if _, err := pg.ExpireBookings(context.TODO(), time.Now().Add(2*time.Hour), time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("expire bookings: %v", err)
+		}
+		assertUses(t, promoCode.Code, 0)
+	})
+
+	otherProduct := storagetesting.NewProduct(t, db)
+	for _, tc := range []struct {
+		name       string
+		params     func(*queries.InsertPromoCodeParams)
+		wantReason string
+	}{
+		{
+			name:       "unknown",
+			params:     func(p *queries.InsertPromoCodeParams) { p.DeletedAt = sql.NullTime{Time: time.Now(), Valid: true} },
+			wantReason: "unknown",
+		},
+		{
+			name: "not valid yet",
+			params: func(p *queries.InsertPromoCodeParams) {
+				p.ValidFrom = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+			},
+			wantReason: "not valid yet",
+		},
+		{
+			name:       "expired",
+			params:     func(p *queries.InsertPromoCodeParams) { p.ValidUntil = sql.NullTime{Time: time.Now(), Valid: true} },
+			wantReason: "expired",
+		},
+		{
+			name: "used up",
+			params: func(p *queries.InsertPromoCodeParams) {
+				p.MaxUses = sql.NullInt32{Int32: 1, Valid: true}
+				p.Uses = 1
+			},
+			wantReason: "used up",
+		},
+		{
+			name:       "other product",
+			params:     func(p *queries.InsertPromoCodeParams) { p.ProductIds = []int32{otherProduct.ID} },
+			wantReason: "not valid for the product",
+		},
+		{
+			name: "other currency",
+			params: func(p *queries.InsertPromoCodeParams) {
+				p.DiscountType = queries.DiscountTypeFIXED
+				p.Currency = sql.NullString{String: "USD", Valid: true}
+			},
+			wantReason: "not valid for EUR",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			promoCode := storagetesting.NewPromoCode(t, db, tc.params)
+
+			_, err := book(promoCode.Code, 1)
+			var promoCodeErr internal.InvalidPromoCodeError
+			if !errors.As(err, &promoCodeErr) {
+				t.Fatalf("want invalid promo code error, got %v", err)
+			}
+			if promoCodeErr.Reason != tc.wantReason {
+				t.Errorf("want reason %q, got %q", tc.wantReason, promoCodeErr.Reason)
+			}
+		})
+	}
+}
+
+func TestRedeemTicket(t *testing.T) {
+	db := storagetesting.Open(t)
+	user := storagetesting.NewUser(t, db)
+	otherUser := storagetesting.NewUser(t, db)
+	product := storagetesting.NewProduct(t, db, func(ipp *queries.InsertProductParams) {
+		ipp.Timezone = "America/Los_Angeles"
+	})
+	localDate := platform.Must(time.Parse(time.DateOnly, "2025-01-20"))
+	now := platform.Must(time.Parse(time.RFC3339, "2025-01-21T05:00:00Z")) // 21:00 on the local date
+
+	confirmedTicket := func(t *testing.T, pg storage.Postgres) string {
+		t.Helper()
+
+		availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.LocalDate = localDate
+		})
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          1,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		id, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		if err := pg.ConfirmBooking(context.TODO(), service.ConfirmBookingParams{ID: id, UserID: int(user.ID)}); err != nil {
+			t.Fatalf("confirm booking: %v", err)
+		}
+
+		booking, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestNone)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		return *booking.(internal.BookingBase).Units[0].(internal.UnitBase).Ticket
+	}
+
+	t.Run("redeem ticket", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		ticket := confirmedTicket(t, pg)
+
+		redemption, err := pg.RedeemTicket(context.TODO(), ticket, int(user.ID), now)
+		if err != nil {
+			t.Fatalf("redeem ticket: %v", err)
+		}
+		if redemption.Unit.UTCRedeemedAt == nil {
+			t.Fatalf("want unit to be redeemed, got %+v", redemption.Unit)
+		}
+
+		booking, err := pg.Booking(context.TODO(), platform.Must(strconv.Atoi(redemption.BookingID)), int(user.ID), internal.CapabilityRequestNone)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		unit := booking.(internal.BookingBase).Units[0].(internal.UnitBase)
+		if unit.UTCRedeemedAt == nil || !unit.UTCRedeemedAt.Equal(*redemption.Unit.UTCRedeemedAt) {
+			t.Errorf("want unit redeemed at %v, got %v", *redemption.Unit.UTCRedeemedAt, unit.UTCRedeemedAt)
+		}
+
+		// second scan of the same ticket
+		_, err = pg.RedeemTicket(context.TODO(), ticket, int(user.ID), now)
+		var redeemedErr internal.TicketRedeemedError
+		if !errors.As(err, &redeemedErr) || !redeemedErr.UTCRedeemedAt.Equal(*redemption.Unit.UTCRedeemedAt) {
+			t.Errorf("want error %v, got %v", internal.TicketRedeemedError{UTCRedeemedAt: *redemption.Unit.UTCRedeemedAt}, err)
+		}
+	})
+
+	t.Run("redeem ticket reference", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		code := confirmedTicket(t, pg)
+		keyID, publicKey := signer.PublicKey()
+		claims, err := ticket.NewVerifier(map[byte]ed25519.PublicKey{keyID: publicKey}).Verify(code)
+		if err != nil {
+			t.Fatalf("verify ticket: %v", err)
+		}
+		unitTicket, err := pg.UnitTicket(context.TODO(), int(claims.BookingID), int(claims.UnitID), int(user.ID))
+		if err != nil {
+			t.Fatalf("get unit ticket: %v", err)
+		}
+		if unitTicket.Reference == "" || unitTicket.Reference == code {
+			t.Fatalf("want short reference of ticket %s, got %q", code, unitTicket.Reference)
+		}
+
+		// Code128 barcodes carry the reference instead of the signed code
+		redemption, err := pg.RedeemTicket(context.TODO(), unitTicket.Reference, int(user.ID), now)
+		if err != nil {
+			t.Fatalf("redeem ticket: %v", err)
+		}
+		if redemption.Unit.Ticket == nil || *redemption.Unit.Ticket != code {
+			t.Errorf("want redeemed ticket %s, got %v", code, redemption.Unit.Ticket)
+		}
+	})
+
+	t.Run("redeem ticket of other user", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		ticket := confirmedTicket(t, pg)
+
+		_, err := pg.RedeemTicket(context.TODO(), ticket, int(otherUser.ID), now)
+		if !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		}
+
+		// still redeemable by the user
+		if _, err := pg.RedeemTicket(context.TODO(), ticket, int(user.ID), now); err != nil {
+			t.Errorf("redeem ticket: %v", err)
+		}
+	})
+
+	t.Run("redeem ticket on other date", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		ticket := confirmedTicket(t, pg)
+
+		_, err := pg.RedeemTicket(context.TODO(), ticket, int(user.ID), now.Add(4*time.Hour)) // next local date
+		if !errors.Is(err, service.ErrWrongDate) {
+			t.Errorf("want error %v, got %v", service.ErrWrongDate, err)
+		}
+	})
+
+	t.Run("redeem voucher", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		voucherProduct := storagetesting.NewProduct(t, db, func(ipp *queries.InsertProductParams) {
+			ipp.Timezone = product.Timezone
+			ipp.DeliveryMethods = []internal.DeliveryMethod{internal.DeliveryMethodVoucher}
+		})
+		availability := storagetesting.NewAvailability(t, db, voucherProduct.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.LocalDate = localDate
+		})
+		id, err := pg.CreateBooking(context.TODO(), service.CreateBookingParams{
+			ProductID:      int(voucherProduct.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          2,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		if err := pg.ConfirmBooking(context.TODO(), service.ConfirmBookingParams{ID: id, UserID: int(user.ID)}); err != nil {
+			t.Fatalf("confirm booking: %v", err)
+		}
+		booking, err := pg.Booking(context.TODO(), id, int(user.ID), internal.CapabilityRequestNone)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		voucher := *booking.(internal.BookingBase).Voucher
+
+		_, err = pg.RedeemTicket(context.TODO(), voucher, int(otherUser.ID), now)
+		if !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("redeem voucher of other user: want error %v, got %v", service.ErrNotFound, err)
+		}
+
+		redemption, err := pg.RedeemTicket(context.TODO(), voucher, int(user.ID), now)
+		if err != nil {
+			t.Fatalf("redeem voucher: %v", err)
+		}
+		if redemption.BookingID != strconv.Itoa(id) || redemption.Unit != nil || len(redemption.Units) != 2 {
+			t.Fatalf("want all units of booking %d redeemed, got %+v", id, redemption)
+		}
+		for _, unit := range redemption.Units {
+			if unit.UTCRedeemedAt == nil {
+				t.Errorf("want unit %s to be redeemed", unit.ID)
+			}
+		}
+
+		// second scan of the same voucher
+		_, err = pg.RedeemTicket(context.TODO(), voucher, int(user.ID), now)
+		var redeemedErr internal.TicketRedeemedError
+		if !errors.As(err, &redeemedErr) {
+			t.Errorf("want TicketRedeemedError, got %v", err)
+		}
+	})
+
+	t.Run("redeem unknown ticket", func(t *testing.T) {
+		_, err := storage.NewPostgres(db, signer).RedeemTicket(context.TODO(), "unknown", int(user.ID), now)
+		if !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		}
+	})
+}
+
+func TestWaitlist(t *testing.T) {
+	db := storagetesting.Open(t)
+	user := storagetesting.NewUser(t, db)
+	product := storagetesting.NewProduct(t, db)
+
+	t.Run("waitlist availability not found", func(t *testing.T) {
+		params := service.CreateWaitlistEntryParams{
+			AvailabilityID: -1,
+			UserID:         int(user.ID),
+			Units:          1,
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		_, err := storage.NewPostgres(db, signer).CreateWaitlistEntry(context.TODO(), params)
+		if !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		}
+	})
+
+	t.Run("waitlist entry not found", func(t *testing.T) {
+		_, err := storage.NewPostgres(db, signer).WaitlistEntry(context.TODO(), -1, int(user.ID))
+		if !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		}
+	})
+
+	t.Run("reserved right away", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.Vacancies = 5
+		})
+
+		entry := joinWaitlist(t, pg, int(user.ID), availability, 2, "")
+		if entry.Status != internal.WaitlistStatusReserved || entry.BookingID == nil {
+			t.Errorf("want reserved entry, got %+v", entry)
+		}
+		assertVacancies(t, pg, availability, 3)
+	})
+
+	t.Run("reserved when vacancies return", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.Vacancies = 3
+		})
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          3,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		bookingID, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+
+		first := joinWaitlist(t, pg, int(user.ID), availability, 2, "https://reseller.example.com/waitlist")
+		second := joinWaitlist(t, pg, int(user.ID), availability, 2, "")
+		third := joinWaitlist(t, pg, int(user.ID), availability, 1, "")
+		for _, entry := range []internal.WaitlistEntry{first, second, third} {
+			if entry.Status != internal.WaitlistStatusWaiting {
+				t.Errorf("want entry %s to be waiting, got %s", entry.ID, entry.Status)
+			}
+		}
+
+		// cancellation returns 3 vacancies to the waitlist right away, the second entry does not fit after the first one
+		if err := pg.CancelBooking(context.TODO(), bookingID, int(user.ID), "changed plans", time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("cancel booking: %v", err)
+		}
+		assertVacancies(t, pg, availability, 0)
+		assertWaitlistStatus(t, pg, int(user.ID), first, internal.WaitlistStatusReserved)
+		assertWaitlistStatus(t, pg, int(user.ID), second, internal.WaitlistStatusWaiting)
+		assertWaitlistStatus(t, pg, int(user.ID), third, internal.WaitlistStatusReserved)
+
+		got, err := pg.WaitlistEntry(context.TODO(), platform.Must(strconv.Atoi(first.ID)), int(user.ID))
+		if err != nil {
+			t.Fatalf("get waitlist entry: %v", err)
+		}
+		booking, err := pg.Booking(context.TODO(), platform.Must(strconv.Atoi(*got.BookingID)), int(user.ID), internal.CapabilityRequestNone)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		wantBooking := internal.BookingBase{
+			AvailabilityID: strconv.Itoa(int(availability.ID)),
+			Status:         internal.BookingStatusReserved,
+			Units:          make([]internal.Unit, 2),
+		}
+		assertBooking(t, wantBooking, booking.(internal.BookingBase))
+
+		// top-up by the operator
+		topUpParams := queries.SetVacanciesParams{
+			ID:        availability.ID,
+			Vacancies: 2,
+		}
+		if err := queries.New(db).SetVacancies(context.TODO(), topUpParams); err != nil {
+			t.Fatalf("set vacancies: %v", err)
+		}
+		reserved, err := pg.ReserveWaitlisted(context.TODO(), time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("reserve waitlisted: %v", err)
+		}
+		if reserved != 1 {
+			t.Errorf("want 1 reserved entry, got %d", reserved)
+		}
+		assertVacancies(t, pg, availability, 0)
+		assertWaitlistStatus(t, pg, int(user.ID), second, internal.WaitlistStatusReserved)
+
+		// only the entry with a webhook is notified, once
+		unnotified, err := pg.UnnotifiedWaitlistEntries(context.TODO())
+		if err != nil {
+			t.Fatalf("get unnotified waitlist entries: %v", err)
+		}
+		if len(unnotified) != 1 || unnotified[0].ID != first.ID {
+			t.Fatalf("want entry %s to be unnotified, got %+v", first.ID, unnotified)
+		}
+		if err := pg.SetWaitlistEntryNotified(context.TODO(), platform.Must(strconv.Atoi(first.ID))); err != nil {
+			t.Fatalf("set waitlist entry notified: %v", err)
+		}
+		unnotified, err = pg.UnnotifiedWaitlistEntries(context.TODO())
+		if err != nil {
+			t.Fatalf("get unnotified waitlist entries: %v", err)
+		}
+		if len(unnotified) != 0 {
+			t.Errorf("want no unnotified entries, got %+v", unnotified)
+		}
+	})
+
+	t.Run("reserved when reservation expires", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.Vacancies = 2
+		})
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          2,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(-time.Minute),
+		}
+		if _, err := pg.CreateBooking(context.TODO(), params); err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		entry := joinWaitlist(t, pg, int(user.ID), availability, 2, "")
+
+		if _, err := pg.ExpireBookings(context.TODO(), time.Now(), time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("expire bookings: %v", err)
+		}
+		assertVacancies(t, pg, availability, 0)
+		assertWaitlistStatus(t, pg, int(user.ID), entry, internal.WaitlistStatusReserved)
+	})
+
+	t.Run("reserved when booking update releases units", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.Vacancies = 3
+		})
+		params := service.CreateBookingParams{
+			ProductID:      int(product.ID),
+			AvailabilityID: int(availability.ID),
+			Units:          3,
+			UserID:         int(user.ID),
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		bookingID, err := pg.CreateBooking(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create booking: %v", err)
+		}
+		entry := joinWaitlist(t, pg, int(user.ID), availability, 2, "")
+
+		err = pg.UpdateBooking(context.TODO(), service.UpdateBookingParams{
+			ID:                bookingID,
+			UserID:            int(user.ID),
+			Units:             1,
+			WaitlistExpiresAt: time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("update booking: %v", err)
+		}
+		assertVacancies(t, pg, availability, 0)
+		assertWaitlistStatus(t, pg, int(user.ID), entry, internal.WaitlistStatusReserved)
+	})
+
+	t.Run("reserved with unit types", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		product := storagetesting.NewProduct(t, db)
+		basePrice := storagetesting.NewPrice(t, db, product.ID)
+		child := storagetesting.NewUnitType(t, db, product.ID, func(p *queries.InsertUnitTypeParams) {
+			p.Code = "child"
+		})
+		childPrice := storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+			p.Currency = basePrice.Currency
+			p.UnitTypeID = sql.NullInt32{Int32: child.ID, Valid: true}
+			p.Price = basePrice.Price / 2
+		})
+		availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.Vacancies = 5
+		})
+
+		params := service.CreateWaitlistEntryParams{
+			AvailabilityID: int(availability.ID),
+			UserID:         int(user.ID),
+			Units:          2,
+			UnitIDs:        []string{"child", "child"},
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		id, err := pg.CreateWaitlistEntry(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create waitlist entry: %v", err)
+		}
+		entry, err := pg.WaitlistEntry(context.TODO(), id, int(user.ID))
+		if err != nil {
+			t.Fatalf("get waitlist entry: %v", err)
+		}
+		if entry.Status != internal.WaitlistStatusReserved {
+			t.Fatalf("want reserved entry, got %+v", entry)
+		}
+
+		got, err := pg.Booking(context.TODO(), platform.Must(strconv.Atoi(*entry.BookingID)), int(user.ID), internal.CapabilityRequestPrice)
+		if err != nil {
+			t.Fatalf("get booking: %v", err)
+		}
+		booking := got.(internal.BookingWithPrice)
+		if len(booking.Units) != 2 {
+			t.Fatalf("want 2 units, got %d", len(booking.Units))
+		}
+		for i, unit := range booking.Units {
+			unit := unit.(internal.UnitWithPrice)
+			if unit.UnitID == nil || *unit.UnitID != child.Code {
+				t.Errorf("want unit %d type %s, got %v", i, child.Code, unit.UnitID)
+			}
+			if unit.Price != int(childPrice.Price) {
+				t.Errorf("want unit %d price %d, got %d", i, childPrice.Price, unit.Price)
+			}
+		}
+	})
+
+	t.Run("waitlist unknown unit", func(t *testing.T) {
+		availability := storagetesting.NewAvailability(t, db, product.ID)
+		params := service.CreateWaitlistEntryParams{
+			AvailabilityID: int(availability.ID),
+			UserID:         int(user.ID),
+			Units:          1,
+			UnitIDs:        []string{"unknown"},
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		_, err := storage.NewPostgres(db, signer).CreateWaitlistEntry(context.TODO(), params)
+		if !errors.Is(err, service.ErrUnknownUnit) {
+			t.Errorf("want error %v, got %v", service.ErrUnknownUnit, err)
+		}
+	})
+
+	t.Run("reserved in requested currency and option", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		evening := storagetesting.NewOption(t, db, product.ID)
+		storagetesting.NewPrice(t, db, product.ID) // default currency must be priced
+		storagetesting.NewPrice(t, db, product.ID, func(p *queries.InsertPriceParams) {
+			p.Currency = "USD"
+		})
+		availability := storagetesting.NewAvailability(t, db, product.ID, func(iap *queries.InsertAvailabilityParams) {
+			iap.OptionID = sql.NullInt32{Int32: evening.ID, Valid: true}
+			iap.Vacancies = 5
+		})
+		params := service.CreateWaitlistEntryParams{
+			AvailabilityID: int(availability.ID),
+			OptionID:       evening.Code,
+			UserID:         int(user.ID),
+			Units:          1,
+			Currency:       "USD",
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		id, err := pg.CreateWaitlistEntry(context.TODO(), params)
+		if err != nil {
+			t.Fatalf("create waitlist entry: %v", err)
+		}
+
+		entry, err := pg.WaitlistEntry(context.TODO(), id, int(user.ID))
+		if err != nil {
+			t.Fatalf("get waitlist entry: %v", err)
+		}
+		if entry.OptionID != evening.Code || entry.Currency != "USD" {
+			t.Errorf("want entry for option %s in USD, got %+v", evening.Code, entry)
+		}
+		if entry.Status != internal.WaitlistStatusReserved {
+			t.Errorf("want reserved entry, got %+v", entry)
+		}
+	})
+
+	t.Run("waitlist availability of other option", func(t *testing.T) {
+		availability := storagetesting.NewAvailability(t, db, product.ID)
+		params := service.CreateWaitlistEntryParams{
+			AvailabilityID: int(availability.ID),
+			OptionID:       "unknown",
+			UserID:         int(user.ID),
+			Units:          1,
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		_, err := storage.NewPostgres(db, signer).CreateWaitlistEntry(context.TODO(), params)
+		if !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		}
+	})
+
+	t.Run("waitlist in unsupported currency", func(t *testing.T) {
+		availability := storagetesting.NewAvailability(t, db, product.ID)
+		params := service.CreateWaitlistEntryParams{
+			AvailabilityID: int(availability.ID),
+			UserID:         int(user.ID),
+			Units:          1,
+			Currency:       "GBP",
+			ExpiresAt:      time.Now().Add(time.Hour),
+		}
+		_, err := storage.NewPostgres(db, signer).CreateWaitlistEntry(context.TODO(), params)
+		var currencyErr internal.UnsupportedCurrencyError
+		if !errors.As(err, &currencyErr) {
+			t.Errorf("want UnsupportedCurrencyError, got %v", err)
+		}
+	})
+
+	t.Run("entry of other user", func(t *testing.T) {
+		pg := storage.NewPostgres(db, signer)
+		availability := storagetesting.NewAvailability(t, db, product.ID)
+		entry := joinWaitlist(t, pg, int(user.ID), availability, 1, "")
+
+		otherUser := storagetesting.NewUser(t, db)
+		_, err := pg.WaitlistEntry(context.TODO(), platform.Must(strconv.Atoi(entry.ID)), int(otherUser.ID))
+		if !errors.Is(err, service.ErrNotFound) {
+			t.Errorf("want error %v, got %v", service.ErrNotFound, err)
+		}
+	})
+}
+
+func joinWaitlist(t *testing.T, pg storage.Postgres, userID int, availability queries.Availability, units int, webhookURL string) internal.WaitlistEntry {
+	t.Helper()
+
+	params := service.CreateWaitlistEntryParams{
+		AvailabilityID: int(availability.ID),
+		UserID:         userID,
+		Units:          units,
+		WebhookURL:     webhookURL,
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}
+	id, err := pg.CreateWaitlistEntry(context.TODO(), params)
+	if err != nil {
+		t.Fatalf("create waitlist entry: %v", err)
+	}
+
+	entry, err := pg.WaitlistEntry(context.TODO(), id, userID)
+	if err != nil {
+		t.Fatalf("get waitlist entry: %v", err)
+	}
+
+	return entry
+}
+
+func assertWaitlistStatus(t *testing.T, pg storage.Postgres, userID int, entry internal.WaitlistEntry, want internal.WaitlistStatus) {
+	t.Helper()
+
+	got, err := pg.WaitlistEntry(context.TODO(), platform.Must(strconv.Atoi(entry.ID)), userID)
+	if err != nil {
+		t.Fatalf("get waitlist entry: %v", err)
+	}
+	if got.Status != want {
+		t.Errorf("want entry %s status %s, got %s", entry.ID, want, got.Status)
+	}
+	if reserved := got.BookingID != nil; reserved != (want == internal.WaitlistStatusReserved) {
+		t.Errorf("want entry %s booking set %t, got %v", entry.ID, !reserved, got.BookingID)
+	}
+}
+
+func TestIdempotentRequests(t *testing.T) {
+	db := storagetesting.Open(t)
+	user := storagetesting.NewUser(t, db)
+	otherUser := storagetesting.NewUser(t, db)
+	pg := storage.NewPostgres(db, signer)
+	hash := []byte("hash")
+
+	req, err := pg.StartIdempotentRequest(context.TODO(), int(user.ID), "key", hash)
+	if err != nil {
+		t.Fatalf("start idempotent request: %v", err)
+	}
+	if !bytes.Equal(req.Hash, hash) || req.Response != nil {
+		t.Errorf("want new request, got %+v", req)
+	}
+
+	t.Run("in progress", func(t *testing.T) {
+		req, err := pg.StartIdempotentRequest(context.TODO(), int(user.ID), "key", []byte("other"))
+		if !errors.Is(err, idempotency.ErrKeyExists) {
+			t.Fatalf("want error %v, got %v", idempotency.ErrKeyExists, err)
+		}
+		if !bytes.Equal(req.Hash, hash) || req.Response != nil {
+			t.Errorf("want stored request without response, got %+v", req)
+		}
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		_, err := pg.StartIdempotentRequest(context.TODO(), int(otherUser.ID), "key", hash)
+		if err != nil {
+			t.Fatalf("start idempotent request: %v", err)
+		}
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		resp := idempotency.Response{
+			StatusCode:  http.StatusCreated,
+			ContentType: "application/json",
+			Body:        []byte(`{"id":"1"}`),
+		}
+		if err := pg.SaveIdempotentResponse(context.TODO(), int(user.ID), "key", resp); err != nil {
+			t.Fatalf("save idempotent response: %v", err)
+		}
+		// completed requests are kept
+		if err := pg.DeleteIdempotentRequest(context.TODO(), int(user.ID), "key"); err != nil {
+			t.Fatalf("delete idempotent request: %v", err)
+		}
+
+		req, err := pg.StartIdempotentRequest(context.TODO(), int(user.ID), "key", hash)
+		if !errors.Is(err, idempotency.ErrKeyExists) {
+			t.Fatalf("want error %v, got %v", idempotency.ErrKeyExists, err)
+		}
+		if !reflect.DeepEqual(req.Response, &resp) {
+			t.Errorf("want response %+v, got %+v", resp, req.Response)
+		}
+	})
+
+	t.Run("delete in progress", func(t *testing.T) {
+		if _, err := pg.StartIdempotentRequest(context.TODO(), int(user.ID), "failed", hash); err != nil {
+			t.Fatalf("start idempotent request: %v", err)
+		}
+		if err := pg.DeleteIdempotentRequest(context.TODO(), int(user.ID), "failed"); err != nil {
+			t.Fatalf("delete idempotent request: %v", err)
+		}
+
+		if _, err := pg.StartIdempotentRequest(context.TODO(), int(user.ID), "failed", hash); err != nil {
+			t.Fatalf("want key to be reusable, got %v", err)
+		}
+	})
+
+	t.Run("delete expired", func(t *testing.T) {
+		if _, err := pg.StartIdempotentRequest(context.TODO(), int(user.ID), "lost", hash); err != nil {
+			t.Fatalf("start idempotent request: %v", err)
+		}
+
+		// only requests in progress are old enough
+		if _, err := pg.DeleteExpiredIdempotentRequests(context.TODO(), time.Now().Add(time.Hour), time.Now().Add(-time.Hour)); err != nil {
+			t.Fatalf("delete expired idempotent requests: %v", err)
+		}
+		if _, err := pg.StartIdempotentRequest(context.TODO(), int(user.ID), "lost", hash); err != nil {
+			t.Errorf("want lost key to be reusable, got %v", err)
+		}
+		if _, err := pg.StartIdempotentRequest(context.TODO(), int(user.ID), "key", hash); !errors.Is(err, idempotency.ErrKeyExists) {
+			t.Errorf("want completed key to be kept, got %v", err)
+		}
+
+		if _, err := pg.DeleteExpiredIdempotentRequests(context.TODO(), time.Now().Add(-time.Hour), time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("delete expired idempotent requests: %v", err)
+		}
+		if _, err := pg.StartIdempotentRequest(context.TODO(), int(user.ID), "key", hash); err != nil {
+			t.Errorf("want expired key to be reusable, got %v", err)
+		}
+	})
+}
+
+func assertBookingIDs(t *testing.T, want []int, got []internal.Booking) {
+	t.Helper()
+
+	gotIDs := make([]int, len(got))
+	for i, booking := range got {
+		var id string
+		switch b := booking.(type) {
+		case internal.BookingBase:
+			id = b.ID
+		case internal.BookingWithPrice:
+			id = b.ID
+		}
+		gotIDs[i] = platform.Must(strconv.Atoi(id))
+	}
+
+	if !reflect.DeepEqual(want, gotIDs) {
+		t.Errorf("want bookings %v, got %v", want, gotIDs)
+	}
+}
+
+func assertBookingUnits(t *testing.T, pg storage.Postgres, id, userID int, availabilityID int32, units int) {
+	t.Helper()
+
+	got, err := pg.Booking(context.TODO(), id, userID, internal.CapabilityRequestNone)
+	if err != nil {
+		t.Fatalf("get booking: %v", err)
+	}
+	want := internal.BookingBase{
+		AvailabilityID: strconv.Itoa(int(availabilityID)),
+		Status:         internal.BookingStatusReserved,
+		Units:          make([]internal.Unit, units),
+	}
+	assertBooking(t, want, got.(internal.BookingBase))
+}
+
+func assertExpiresAt(t *testing.T, pg storage.Postgres, id, userID int, want time.Time) {
+	t.Helper()
+
+	got, err := pg.Booking(context.TODO(), id, userID, internal.CapabilityRequestNone)
+	if err != nil {
+		t.Fatalf("get booking: %v", err)
+	}
+	gotExpiresAt := got.(internal.BookingBase).UTCExpiresAt
+	if gotExpiresAt == nil || gotExpiresAt.Sub(want).Abs() > time.Millisecond { // postgres stores microseconds
+		t.Errorf("want expiry %s, got %v", want, gotExpiresAt)
+	}
+}
+
+func assertVacancies(t *testing.T, pg storage.Postgres, availability queries.Availability, want int) {
+	t.Helper()
+
+	got, err := pg.Availabilities(context.TODO(), int(availability.ProductID), internal.DefaultOptionID, availability.LocalDate, availability.LocalDate, internal.CapabilityRequestNone, "")
+	if err != nil {
+		t.Fatalf("get availabilities: %v", err)
+	}
+	id := strconv.Itoa(int(availability.ID))
+	for _, a := range got {
+		if a := a.(internal.AvailabilityBase); a.ID == id {
+			if a.Vacancies != want {
+				t.Errorf("want %d vacancies, got %d", want, a.Vacancies)
+			}
+			return
+		}
+	}
+	t.Errorf("availability %s not found", id)
+}
+
+func assertProductEqual(t *testing.T, want queries.Product, got internal.ProductBase) {
+	t.Helper()
+
+	wantID := strconv.Itoa(int(want.ID))
+	if wantID != got.ID {
+		t.Errorf("want product ID %s, got %s", wantID, got.ID)
+	}
+	if want.Name != got.Name {
+		t.Errorf("want product name %s, got %s", want.Name, got.Name)
+	}
+}
+
+func assertProductWithPriceEqual(t *testing.T, want queries.ProductWithPriceRow, got internal.ProductWithPrice) {
+	t.Helper()
+
+	assertProductEqual(t, want.Product, got.ProductBase)
+	assertPriceEqual(t, want.Price, got.CapabilityPrice)
+}
+
+func assertPriceEqual(t *testing.T, want queries.Price, got internal.CapabilityPrice) {
+	t.Helper()
+
+	if want.Price != int32(got.Price) {
+		t.Errorf("want price %d, got %d", want.Price, got.Price)
+	}
+	if want.Currency != got.Currency {
+		t.Errorf("want currency %s, got %s", want.Currency, got.Currency)
+	}
+}
+
+// capabilityPrice returns a price without discounts, net price and taxes.
+func capabilityPrice(price int, currency string) internal.CapabilityPrice {
+	return internal.CapabilityPrice{
+		Price:    price,
+		Currency: currency,
+		Pricing: internal.Pricing{
+			Original:          price,
+			Retail:            price,
+			Currency:          currency,
+			CurrencyPrecision: 2,
+			IncludedTaxes:     internal.IncludedTaxes{},
+		},
+	}
 }
 
 func assertBooking(t *testing.T, want, got internal.BookingBase) {
@@ -364,6 +3347,18 @@ func assertBooking(t *testing.T, want, got internal.BookingBase) {
 	}
 }
 
+func assertContact(t *testing.T, pg storage.Postgres, bookingID, userID int, want internal.Contact) {
+	t.Helper()
+
+	booking, err := pg.Booking(context.TODO(), bookingID, userID, internal.CapabilityRequestNone)
+	if err != nil {
+		t.Fatalf("get booking: %v", err)
+	}
+	if got := booking.(internal.BookingBase).Contact; !reflect.DeepEqual(want, got) {
+		t.Errorf("want contact %+v, got %+v", want, got)
+	}
+}
+
 func assertBookingWithPrice(t *testing.T, want, got internal.BookingWithPrice) {
 	t.Helper()
 
@@ -8,66 +8,186 @@ package queries
 import (
 	"context"
 	"time"
+
+	"github.com/lib/pq"
 )
 
-const avalability = `-- name: Avalability :one
-SELECT id, created_at, updated_at, deleted_at, product_id, local_date, vacancies FROM availabilities
-WHERE product_id = $1 
-AND local_date = $2
-AND deleted_at IS NULL
+const availabilityCalendar = `-- name: AvailabilityCalendar :many
+SELECT
+    days.local_date::DATE AS local_date,
+    (CASE
+        WHEN COUNT(availabilities.id) = 0 THEN 'CLOSED'
+        WHEN bool_or(availabilities.freesale) THEN 'FREESALE'
+        WHEN SUM(availabilities.vacancies) = 0 THEN 'SOLD_OUT'
+        WHEN SUM(availabilities.vacancies) < products.limited_threshold THEN 'LIMITED'
+        ELSE 'AVAILABLE'
+    END)::VARCHAR AS status,
+    COALESCE(SUM(availabilities.vacancies) FILTER (WHERE NOT availabilities.freesale), 0)::INTEGER AS vacancies
+FROM products
+CROSS JOIN generate_series($1::DATE, $2::DATE, '1 day') AS days(local_date)
+LEFT JOIN (
+    availabilities
+    JOIN options ON options.id = availabilities.option_id
+        AND options.deleted_at IS NULL
+) ON availabilities.product_id = products.id
+    AND availabilities.local_date = days.local_date::DATE
+    AND NOT availabilities.closed
+    AND availabilities.deleted_at IS NULL
+WHERE products.id = $3
+AND products.deleted_at IS NULL
+GROUP BY days.local_date, products.id
+ORDER BY days.local_date
 `
 
-type AvalabilityParams struct {
-	ProductID int32
+type AvailabilityCalendarParams struct {
+	LocalDateStart time.Time
+	LocalDateEnd   time.Time
+	ProductID      int32
+}
+
+type AvailabilityCalendarRow struct {
 	LocalDate time.Time
+	Status    string
+	Vacancies int32
 }
 
-func (q *Queries) Avalability(ctx context.Context, arg AvalabilityParams) (Availability, error) {
-	row := q.db.QueryRowContext(ctx, avalability, arg.ProductID, arg.LocalDate)
-	var i Availability
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.DeletedAt,
-		&i.ProductID,
-		&i.LocalDate,
-		&i.Vacancies,
-	)
-	return i, err
+// status and vacancies per local date across the product's open options and slots,
+// dates without open availabilities are closed
+func (q *Queries) AvailabilityCalendar(ctx context.Context, arg AvailabilityCalendarParams) ([]AvailabilityCalendarRow, error) {
+	rows, err := q.db.QueryContext(ctx, availabilityCalendar, arg.LocalDateStart, arg.LocalDateEnd, arg.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AvailabilityCalendarRow
+	for rows.Next() {
+		var i AvailabilityCalendarRow
+		if err := rows.Scan(&i.LocalDate, &i.Status, &i.Vacancies); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const avalabilityRange = `-- name: AvalabilityRange :many
-SELECT id, created_at, updated_at, deleted_at, product_id, local_date, vacancies FROM availabilities
-WHERE product_id = $1 
-AND local_date >= $2
-AND local_date <= $3
-AND deleted_at IS NULL
+const availabilityCalendarWithPrice = `-- name: AvailabilityCalendarWithPrice :many
+WITH slots AS (
+    -- open slots with their effective price, like in AvalabilityWithPriceRange
+    SELECT DISTINCT ON (availabilities.id) availabilities.id, availabilities.local_date, availabilities.vacancies, availabilities.freesale, prices.id AS price_id, prices.price
+    FROM availabilities
+    JOIN options ON options.id = availabilities.option_id
+    JOIN prices ON prices.product_id = availabilities.product_id
+        AND (prices.option_id = availabilities.option_id OR prices.option_id IS NULL)
+        AND (prices.availability_id IS NULL OR prices.availability_id = availabilities.id)
+        AND (prices.local_date_start IS NULL OR prices.local_date_start <= availabilities.local_date)
+        AND (prices.local_date_end IS NULL OR prices.local_date_end >= availabilities.local_date)
+        AND (prices.weekdays IS NULL OR EXTRACT(ISODOW FROM availabilities.local_date)::INTEGER = ANY(prices.weekdays))
+    WHERE availabilities.product_id = $1
+    AND availabilities.local_date >= $2::DATE
+    AND availabilities.local_date <= $3::DATE
+    AND NOT availabilities.closed
+    AND availabilities.deleted_at IS NULL
+    AND options.deleted_at IS NULL
+    AND prices.deleted_at IS NULL
+    AND prices.unit_type_id IS NULL -- base price
+    AND prices.currency = $4::VARCHAR
+    ORDER BY availabilities.id, prices.priority DESC, prices.option_id NULLS LAST
+),
+dates AS (
+    SELECT
+        days.local_date::DATE AS local_date,
+        (CASE
+            WHEN COUNT(slots.id) = 0 THEN 'CLOSED'
+            WHEN bool_or(slots.freesale) THEN 'FREESALE'
+            WHEN SUM(slots.vacancies) = 0 THEN 'SOLD_OUT'
+            WHEN SUM(slots.vacancies) < products.limited_threshold THEN 'LIMITED'
+            ELSE 'AVAILABLE'
+        END)::VARCHAR AS status,
+        COALESCE(SUM(slots.vacancies) FILTER (WHERE NOT slots.freesale), 0)::INTEGER AS vacancies,
+        (array_agg(slots.price_id ORDER BY slots.price) FILTER (WHERE slots.id IS NOT NULL))[1]::BIGINT AS price_id -- lowest price
+    FROM products
+    CROSS JOIN generate_series($2::DATE, $3::DATE, '1 day') AS days(local_date)
+    LEFT JOIN slots ON slots.local_date = days.local_date::DATE
+    WHERE products.id = $1
+    AND products.deleted_at IS NULL
+    GROUP BY days.local_date, products.id
+),
+base_price AS (
+    SELECT prices.id
+    FROM prices
+    WHERE prices.product_id = $1
+    AND prices.option_id IS NULL
+    AND prices.unit_type_id IS NULL
+    AND prices.priority = 0 -- not a price rule
+    AND prices.deleted_at IS NULL
+    AND prices.currency = $4::VARCHAR
+    ORDER BY prices.id
+    LIMIT 1
+)
+SELECT dates.local_date, dates.status, dates.vacancies, prices.id, prices.created_at, prices.updated_at, prices.deleted_at, prices.price, prices.currency, prices.product_id, prices.unit_type_id, prices.option_id, prices.availability_id, prices.local_date_start, prices.local_date_end, prices.weekdays, prices.priority, prices.original, prices.net, prices.included_taxes
+FROM dates
+CROSS JOIN base_price -- no calendar without a base price
+JOIN prices ON prices.id = COALESCE(dates.price_id, base_price.id)
+ORDER BY dates.local_date
 `
 
-type AvalabilityRangeParams struct {
+type AvailabilityCalendarWithPriceParams struct {
 	ProductID      int32
 	LocalDateStart time.Time
 	LocalDateEnd   time.Time
+	Currency       string
+}
+
+type AvailabilityCalendarWithPriceRow struct {
+	LocalDate time.Time
+	Status    string
+	Vacancies int32
+	Price     Price
 }
 
-func (q *Queries) AvalabilityRange(ctx context.Context, arg AvalabilityRangeParams) ([]Availability, error) {
-	rows, err := q.db.QueryContext(ctx, avalabilityRange, arg.ProductID, arg.LocalDateStart, arg.LocalDateEnd)
+// calendar with the lowest price of the open slots per local date in the currency,
+// closed dates have the product's base price
+func (q *Queries) AvailabilityCalendarWithPrice(ctx context.Context, arg AvailabilityCalendarWithPriceParams) ([]AvailabilityCalendarWithPriceRow, error) {
+	rows, err := q.db.QueryContext(ctx, availabilityCalendarWithPrice,
+		arg.ProductID,
+		arg.LocalDateStart,
+		arg.LocalDateEnd,
+		arg.Currency,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Availability
+	var items []AvailabilityCalendarWithPriceRow
 	for rows.Next() {
-		var i Availability
+		var i AvailabilityCalendarWithPriceRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.DeletedAt,
-			&i.ProductID,
 			&i.LocalDate,
+			&i.Status,
 			&i.Vacancies,
+			&i.Price.ID,
+			&i.Price.CreatedAt,
+			&i.Price.UpdatedAt,
+			&i.Price.DeletedAt,
+			&i.Price.Price,
+			&i.Price.Currency,
+			&i.Price.ProductID,
+			&i.Price.UnitTypeID,
+			&i.Price.OptionID,
+			&i.Price.AvailabilityID,
+			&i.Price.LocalDateStart,
+			&i.Price.LocalDateEnd,
+			pq.Array(&i.Price.Weekdays),
+			&i.Price.Priority,
+			&i.Price.Original,
+			&i.Price.Net,
+			&i.Price.IncludedTaxes,
 		); err != nil {
 			return nil, err
 		}
@@ -82,72 +202,130 @@ func (q *Queries) AvalabilityRange(ctx context.Context, arg AvalabilityRangePara
 	return items, nil
 }
 
-const avalabilityWithPrice = `-- name: AvalabilityWithPrice :one
-SELECT availabilities.id, availabilities.created_at, availabilities.updated_at, availabilities.deleted_at, availabilities.product_id, availabilities.local_date, availabilities.vacancies, prices.id, prices.created_at, prices.updated_at, prices.deleted_at, prices.price, prices.currency, prices.product_id
+const avalabilityRange = `-- name: AvalabilityRange :many
+SELECT availabilities.id, availabilities.created_at, availabilities.updated_at, availabilities.deleted_at, availabilities.product_id, availabilities.local_date, availabilities.vacancies, availabilities.option_id, availabilities.local_date_time_start, availabilities.local_date_time_end, availabilities.all_day, availabilities.closed, availabilities.freesale, availabilities.capacity, products.timezone, products.limited_threshold
 FROM availabilities
-JOIN prices ON availabilities.product_id = prices.product_id
-WHERE availabilities.product_id = $1
-AND availabilities.local_date = $2
+JOIN products ON products.id = availabilities.product_id
+WHERE availabilities.product_id = $1 
+AND availabilities.option_id = (
+    SELECT id FROM options
+    WHERE options.product_id = $1
+    AND code = $2::VARCHAR
+    AND options.deleted_at IS NULL
+)
+AND availabilities.local_date >= $3
+AND availabilities.local_date <= $4
 AND availabilities.deleted_at IS NULL
-AND prices.deleted_at IS NULL
+ORDER BY availabilities.local_date_time_start, availabilities.id
 `
 
-type AvalabilityWithPriceParams struct {
-	ProductID int32
-	LocalDate time.Time
+type AvalabilityRangeParams struct {
+	ProductID      int32
+	OptionCode     string
+	LocalDateStart time.Time
+	LocalDateEnd   time.Time
 }
 
-type AvalabilityWithPriceRow struct {
-	Availability Availability
-	Price        Price
-}
-
-func (q *Queries) AvalabilityWithPrice(ctx context.Context, arg AvalabilityWithPriceParams) (AvalabilityWithPriceRow, error) {
-	row := q.db.QueryRowContext(ctx, avalabilityWithPrice, arg.ProductID, arg.LocalDate)
-	var i AvalabilityWithPriceRow
-	err := row.Scan(
-		&i.Availability.ID,
-		&i.Availability.CreatedAt,
-		&i.Availability.UpdatedAt,
-		&i.Availability.DeletedAt,
-		&i.Availability.ProductID,
-		&i.Availability.LocalDate,
-		&i.Availability.Vacancies,
-		&i.Price.ID,
-		&i.Price.CreatedAt,
-		&i.Price.UpdatedAt,
-		&i.Price.DeletedAt,
-		&i.Price.Price,
-		&i.Price.Currency,
-		&i.Price.ProductID,
+type AvalabilityRangeRow struct {
+	Availability     Availability
+	Timezone         string
+	LimitedThreshold int32
+}
+
+// slots starting on the local dates, local date-times are in the product's time zone
+func (q *Queries) AvalabilityRange(ctx context.Context, arg AvalabilityRangeParams) ([]AvalabilityRangeRow, error) {
+	rows, err := q.db.QueryContext(ctx, avalabilityRange,
+		arg.ProductID,
+		arg.OptionCode,
+		arg.LocalDateStart,
+		arg.LocalDateEnd,
 	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AvalabilityRangeRow
+	for rows.Next() {
+		var i AvalabilityRangeRow
+		if err := rows.Scan(
+			&i.Availability.ID,
+			&i.Availability.CreatedAt,
+			&i.Availability.UpdatedAt,
+			&i.Availability.DeletedAt,
+			&i.Availability.ProductID,
+			&i.Availability.LocalDate,
+			&i.Availability.Vacancies,
+			&i.Availability.OptionID,
+			&i.Availability.LocalDateTimeStart,
+			&i.Availability.LocalDateTimeEnd,
+			&i.Availability.AllDay,
+			&i.Availability.Closed,
+			&i.Availability.Freesale,
+			&i.Availability.Capacity,
+			&i.Timezone,
+			&i.LimitedThreshold,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const avalabilityWithPriceRange = `-- name: AvalabilityWithPriceRange :many
-SELECT availabilities.id, availabilities.created_at, availabilities.updated_at, availabilities.deleted_at, availabilities.product_id, availabilities.local_date, availabilities.vacancies, prices.id, prices.created_at, prices.updated_at, prices.deleted_at, prices.price, prices.currency, prices.product_id
+SELECT DISTINCT ON (availabilities.local_date_time_start, availabilities.id) availabilities.id, availabilities.created_at, availabilities.updated_at, availabilities.deleted_at, availabilities.product_id, availabilities.local_date, availabilities.vacancies, availabilities.option_id, availabilities.local_date_time_start, availabilities.local_date_time_end, availabilities.all_day, availabilities.closed, availabilities.freesale, availabilities.capacity, products.timezone, products.limited_threshold, prices.id, prices.created_at, prices.updated_at, prices.deleted_at, prices.price, prices.currency, prices.product_id, prices.unit_type_id, prices.option_id, prices.availability_id, prices.local_date_start, prices.local_date_end, prices.weekdays, prices.priority, prices.original, prices.net, prices.included_taxes
 FROM availabilities
+JOIN products ON products.id = availabilities.product_id
+JOIN options ON options.id = availabilities.option_id
 JOIN prices ON availabilities.product_id = prices.product_id
+    AND (prices.option_id = availabilities.option_id OR prices.option_id IS NULL)
+    AND (prices.availability_id IS NULL OR prices.availability_id = availabilities.id)
+    AND (prices.local_date_start IS NULL OR prices.local_date_start <= availabilities.local_date)
+    AND (prices.local_date_end IS NULL OR prices.local_date_end >= availabilities.local_date)
+    AND (prices.weekdays IS NULL OR EXTRACT(ISODOW FROM availabilities.local_date)::INTEGER = ANY(prices.weekdays))
 WHERE availabilities.product_id = $1 
-AND availabilities.local_date >= $2
-AND availabilities.local_date <= $3
+AND options.code = $2::VARCHAR
+AND options.deleted_at IS NULL
+AND availabilities.local_date >= $3
+AND availabilities.local_date <= $4
 AND availabilities.deleted_at IS NULL
 AND prices.deleted_at IS NULL
+AND prices.unit_type_id IS NULL -- base price
+AND prices.currency = $5::VARCHAR
+ORDER BY availabilities.local_date_time_start, availabilities.id, prices.priority DESC, prices.option_id NULLS LAST
 `
 
 type AvalabilityWithPriceRangeParams struct {
 	ProductID      int32
+	OptionCode     string
 	LocalDateStart time.Time
 	LocalDateEnd   time.Time
+	Currency       string
 }
 
 type AvalabilityWithPriceRangeRow struct {
-	Availability Availability
-	Price        Price
+	Availability     Availability
+	Timezone         string
+	LimitedThreshold int32
+	Price            Price
 }
 
+// availabilities with their effective price in the currency: the matching price rule with the highest priority,
+// then the option's price, falling back to the product's base price, the same precedence as in UnitPriceIDs
 func (q *Queries) AvalabilityWithPriceRange(ctx context.Context, arg AvalabilityWithPriceRangeParams) ([]AvalabilityWithPriceRangeRow, error) {
-	rows, err := q.db.QueryContext(ctx, avalabilityWithPriceRange, arg.ProductID, arg.LocalDateStart, arg.LocalDateEnd)
+	rows, err := q.db.QueryContext(ctx, avalabilityWithPriceRange,
+		arg.ProductID,
+		arg.OptionCode,
+		arg.LocalDateStart,
+		arg.LocalDateEnd,
+		arg.Currency,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -163,6 +341,15 @@ func (q *Queries) AvalabilityWithPriceRange(ctx context.Context, arg Avalability
 			&i.Availability.ProductID,
 			&i.Availability.LocalDate,
 			&i.Availability.Vacancies,
+			&i.Availability.OptionID,
+			&i.Availability.LocalDateTimeStart,
+			&i.Availability.LocalDateTimeEnd,
+			&i.Availability.AllDay,
+			&i.Availability.Closed,
+			&i.Availability.Freesale,
+			&i.Availability.Capacity,
+			&i.Timezone,
+			&i.LimitedThreshold,
 			&i.Price.ID,
 			&i.Price.CreatedAt,
 			&i.Price.UpdatedAt,
@@ -170,6 +357,67 @@ func (q *Queries) AvalabilityWithPriceRange(ctx context.Context, arg Avalability
 			&i.Price.Price,
 			&i.Price.Currency,
 			&i.Price.ProductID,
+			&i.Price.UnitTypeID,
+			&i.Price.OptionID,
+			&i.Price.AvailabilityID,
+			&i.Price.LocalDateStart,
+			&i.Price.LocalDateEnd,
+			pq.Array(&i.Price.Weekdays),
+			&i.Price.Priority,
+			&i.Price.Original,
+			&i.Price.Net,
+			&i.Price.IncludedTaxes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockAvailabilities = `-- name: LockAvailabilities :exec
+SELECT id FROM availabilities
+WHERE id = ANY($1::INTEGER[])
+ORDER BY id
+FOR UPDATE
+`
+
+// locks in ID order, so concurrent updates of bookings swapping availabilities do not deadlock
+func (q *Queries) LockAvailabilities(ctx context.Context, ids []int32) error {
+	_, err := q.db.ExecContext(ctx, lockAvailabilities, pq.Array(ids))
+	return err
+}
+
+const openingHours = `-- name: OpeningHours :many
+SELECT id, created_at, updated_at, deleted_at, availability_id, time_from, time_to FROM opening_hours
+WHERE availability_id = ANY($1::INTEGER[])
+AND deleted_at IS NULL
+ORDER BY availability_id, time_from
+`
+
+func (q *Queries) OpeningHours(ctx context.Context, availabilityIds []int32) ([]OpeningHour, error) {
+	rows, err := q.db.QueryContext(ctx, openingHours, pq.Array(availabilityIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OpeningHour
+	for rows.Next() {
+		var i OpeningHour
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.AvailabilityID,
+			&i.TimeFrom,
+			&i.TimeTo,
 		); err != nil {
 			return nil, err
 		}
@@ -183,3 +431,116 @@ func (q *Queries) AvalabilityWithPriceRange(ctx context.Context, arg Avalability
 	}
 	return items, nil
 }
+
+const releaseVacancies = `-- name: ReleaseVacancies :exec
+UPDATE availabilities
+SET vacancies = CASE WHEN freesale THEN vacancies ELSE LEAST(vacancies + $1, capacity) END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+`
+
+type ReleaseVacanciesParams struct {
+	Units int32
+	ID    int32
+}
+
+// up to the capacity, which may have been lowered since the units were sold
+func (q *Queries) ReleaseVacancies(ctx context.Context, arg ReleaseVacanciesParams) error {
+	_, err := q.db.ExecContext(ctx, releaseVacancies, arg.Units, arg.ID)
+	return err
+}
+
+const reserveVacancies = `-- name: ReserveVacancies :execrows
+UPDATE availabilities
+SET vacancies = CASE WHEN freesale THEN vacancies ELSE vacancies - $1 END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+AND product_id = $3
+AND option_id = $4
+AND NOT closed
+AND deleted_at IS NULL
+AND (freesale OR vacancies >= $1)
+`
+
+type ReserveVacanciesParams struct {
+	Units     int32
+	ID        int32
+	ProductID int32
+	OptionID  int32
+}
+
+// closed availabilities cannot be reserved, freesale ones have unlimited vacancies
+func (q *Queries) ReserveVacancies(ctx context.Context, arg ReserveVacanciesParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reserveVacancies,
+		arg.Units,
+		arg.ID,
+		arg.ProductID,
+		arg.OptionID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const unitPriceIDs = `-- name: UnitPriceIDs :many
+SELECT DISTINCT ON (unit_type.id) unit_type.id::INTEGER AS unit_type_id, prices.id AS price_id
+FROM availabilities
+CROSS JOIN unnest($1::INTEGER[]) AS unit_type(id)
+JOIN prices ON prices.product_id = availabilities.product_id
+    AND (prices.unit_type_id = unit_type.id OR prices.unit_type_id IS NULL)
+    AND (prices.option_id = availabilities.option_id OR prices.option_id IS NULL)
+    AND (prices.availability_id IS NULL OR prices.availability_id = availabilities.id)
+    AND (prices.local_date_start IS NULL OR prices.local_date_start <= availabilities.local_date)
+    AND (prices.local_date_end IS NULL OR prices.local_date_end >= availabilities.local_date)
+    AND (prices.weekdays IS NULL OR EXTRACT(ISODOW FROM availabilities.local_date)::INTEGER = ANY(prices.weekdays))
+WHERE availabilities.id = $2
+AND availabilities.product_id = $3
+AND availabilities.deleted_at IS NULL
+AND prices.deleted_at IS NULL
+AND prices.currency = $4::VARCHAR
+ORDER BY unit_type.id, prices.priority DESC, prices.unit_type_id NULLS LAST, prices.option_id NULLS LAST
+`
+
+type UnitPriceIDsParams struct {
+	UnitTypeIds    []int32
+	AvailabilityID int32
+	ProductID      int32
+	Currency       string
+}
+
+type UnitPriceIDsRow struct {
+	UnitTypeID int32
+	PriceID    int64
+}
+
+// effective prices of the unit types on the availability in the currency, zero is a unit without type,
+// units are priced like the availability in AvalabilityWithPriceRange: the matching price with the highest priority wins,
+// of the same priority, the unit type's price wins over the option's and the product's price
+func (q *Queries) UnitPriceIDs(ctx context.Context, arg UnitPriceIDsParams) ([]UnitPriceIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, unitPriceIDs,
+		pq.Array(arg.UnitTypeIds),
+		arg.AvailabilityID,
+		arg.ProductID,
+		arg.Currency,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UnitPriceIDsRow
+	for rows.Next() {
+		var i UnitPriceIDsRow
+		if err := rows.Scan(&i.UnitTypeID, &i.PriceID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -8,54 +8,88 @@ package queries
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/dmksnnk/octo/internal"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-const booking = `-- name: Booking :many
-SELECT bookings.id, bookings.created_at, bookings.updated_at, bookings.deleted_at, bookings.product_id, bookings.availability_id, bookings.user_id, bookings.status, units.id, units.created_at, units.updated_at, units.deleted_at, units.booking_id, units.ticket
+const addUnits = `-- name: AddUnits :exec
+INSERT INTO units (booking_id, unit_type_id, price, original, net, included_taxes)
+SELECT $1, NULLIF(unit_type.id, 0), -- zero is a unit without type
+    COALESCE(prices.price, 0), COALESCE(prices.original, prices.price, 0), prices.net, COALESCE(prices.included_taxes, '[]')
+FROM unnest($2::INTEGER[]) WITH ORDINALITY AS unit_type(id, n) -- one row per unit
+JOIN unnest($3::BIGINT[]) WITH ORDINALITY AS unit_price(id, n) USING (n) -- price of each unit
+LEFT JOIN prices ON prices.id = unit_price.id
+`
+
+type AddUnitsParams struct {
+	BookingID   int64
+	UnitTypeIds []int32
+	PriceIds    []int64
+}
+
+// unit type IDs and price IDs are in the same order, units without a price are free
+func (q *Queries) AddUnits(ctx context.Context, arg AddUnitsParams) error {
+	_, err := q.db.ExecContext(ctx, addUnits, arg.BookingID, pq.Array(arg.UnitTypeIds), pq.Array(arg.PriceIds))
+	return err
+}
+
+const bookingForUpdate = `-- name: BookingForUpdate :many
+SELECT bookings.status, bookings.product_id, bookings.option_id, bookings.availability_id, bookings.expires_at, bookings.currency, bookings.promo_code_id, units.id as unit_id, units.unit_type_id, availabilities.local_date, products.delivery_methods
 FROM bookings
-LEFT JOIN units ON units.booking_id = bookings.id
+JOIN units ON units.booking_id = bookings.id
+JOIN availabilities ON availabilities.id = bookings.availability_id
+JOIN products ON products.id = bookings.product_id
 WHERE bookings.id = $1
 AND bookings.user_id = $2
 AND bookings.deleted_at IS NULL
 AND units.deleted_at IS NULL
+ORDER BY units.id
+FOR UPDATE OF bookings, units
 `
 
-type BookingParams struct {
+type BookingForUpdateParams struct {
 	ID     int64
 	UserID int32
 }
 
-type BookingRow struct {
-	Booking Booking
-	Unit    Unit
+type BookingForUpdateRow struct {
+	Status          internal.BookingStatus
+	ProductID       int32
+	OptionID        int32
+	AvailabilityID  int32
+	ExpiresAt       sql.NullTime
+	Currency        string
+	PromoCodeID     sql.NullInt32
+	UnitID          int64
+	UnitTypeID      sql.NullInt32
+	LocalDate       time.Time
+	DeliveryMethods []internal.DeliveryMethod
 }
 
-func (q *Queries) Booking(ctx context.Context, arg BookingParams) ([]BookingRow, error) {
-	rows, err := q.db.QueryContext(ctx, booking, arg.ID, arg.UserID)
+func (q *Queries) BookingForUpdate(ctx context.Context, arg BookingForUpdateParams) ([]BookingForUpdateRow, error) {
+	rows, err := q.db.QueryContext(ctx, bookingForUpdate, arg.ID, arg.UserID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []BookingRow
+	var items []BookingForUpdateRow
 	for rows.Next() {
-		var i BookingRow
+		var i BookingForUpdateRow
 		if err := rows.Scan(
-			&i.Booking.ID,
-			&i.Booking.CreatedAt,
-			&i.Booking.UpdatedAt,
-			&i.Booking.DeletedAt,
-			&i.Booking.ProductID,
-			&i.Booking.AvailabilityID,
-			&i.Booking.UserID,
-			&i.Booking.Status,
-			&i.Unit.ID,
-			&i.Unit.CreatedAt,
-			&i.Unit.UpdatedAt,
-			&i.Unit.DeletedAt,
-			&i.Unit.BookingID,
-			&i.Unit.Ticket,
+			&i.Status,
+			&i.ProductID,
+			&i.OptionID,
+			&i.AvailabilityID,
+			&i.ExpiresAt,
+			&i.Currency,
+			&i.PromoCodeID,
+			&i.UnitID,
+			&i.UnitTypeID,
+			&i.LocalDate,
+			pq.Array(&i.DeliveryMethods),
 		); err != nil {
 			return nil, err
 		}
@@ -70,40 +104,99 @@ func (q *Queries) Booking(ctx context.Context, arg BookingParams) ([]BookingRow,
 	return items, nil
 }
 
-const bookingForUpdate = `-- name: BookingForUpdate :many
-SELECT bookings.status, units.id as unit_id
+const bookingHoldForUpdate = `-- name: BookingHoldForUpdate :one
+SELECT bookings.status, bookings.created_at, bookings.expires_at, products.max_reservation_hold_minutes
 FROM bookings
-JOIN units ON units.booking_id = bookings.id
+JOIN products ON products.id = bookings.product_id
 WHERE bookings.id = $1
 AND bookings.user_id = $2
 AND bookings.deleted_at IS NULL
-AND units.deleted_at IS NULL
-FOR UPDATE
+FOR UPDATE OF bookings
 `
 
-type BookingForUpdateParams struct {
+type BookingHoldForUpdateParams struct {
 	ID     int64
 	UserID int32
 }
 
-type BookingForUpdateRow struct {
-	Status internal.BookingStatus
-	UnitID int64
+type BookingHoldForUpdateRow struct {
+	Status                    internal.BookingStatus
+	CreatedAt                 sql.NullTime
+	ExpiresAt                 sql.NullTime
+	MaxReservationHoldMinutes int32
 }
 
-func (q *Queries) BookingForUpdate(ctx context.Context, arg BookingForUpdateParams) ([]BookingForUpdateRow, error) {
-	rows, err := q.db.QueryContext(ctx, bookingForUpdate, arg.ID, arg.UserID)
+func (q *Queries) BookingHoldForUpdate(ctx context.Context, arg BookingHoldForUpdateParams) (BookingHoldForUpdateRow, error) {
+	row := q.db.QueryRowContext(ctx, bookingHoldForUpdate, arg.ID, arg.UserID)
+	var i BookingHoldForUpdateRow
+	err := row.Scan(
+		&i.Status,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.MaxReservationHoldMinutes,
+	)
+	return i, err
+}
+
+const bookingIDs = `-- name: BookingIDs :many
+SELECT bookings.id
+FROM bookings
+JOIN availabilities ON availabilities.id = bookings.availability_id
+WHERE bookings.user_id = $1
+AND bookings.id > $2
+AND bookings.deleted_at IS NULL
+AND ($3::booking_status IS NULL OR bookings.status = $3::booking_status)
+AND ($4::INTEGER IS NULL OR bookings.product_id = $4::INTEGER)
+AND ($5::DATE IS NULL OR availabilities.local_date >= $5::DATE)
+AND ($6::DATE IS NULL OR availabilities.local_date <= $6::DATE)
+AND ($7::TIMESTAMPTZ IS NULL OR bookings.created_at >= $7::TIMESTAMPTZ)
+AND ($8::TIMESTAMPTZ IS NULL OR bookings.created_at <= $8::TIMESTAMPTZ)
+AND ($9::UUID IS NULL OR bookings.uuid = $9::UUID)
+AND ($10::VARCHAR IS NULL OR bookings.reseller_reference = $10::VARCHAR)
+ORDER BY bookings.id
+LIMIT $11
+`
+
+type BookingIDsParams struct {
+	UserID            int32
+	After             int64
+	Status            NullBookingStatus
+	ProductID         sql.NullInt32
+	LocalDateStart    sql.NullTime
+	LocalDateEnd      sql.NullTime
+	CreatedAtStart    sql.NullTime
+	CreatedAtEnd      sql.NullTime
+	Uuid              uuid.NullUUID
+	ResellerReference sql.NullString
+	MaxResults        int32
+}
+
+// lists IDs of user's bookings matching the filters, NULL filters are ignored
+func (q *Queries) BookingIDs(ctx context.Context, arg BookingIDsParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, bookingIDs,
+		arg.UserID,
+		arg.After,
+		arg.Status,
+		arg.ProductID,
+		arg.LocalDateStart,
+		arg.LocalDateEnd,
+		arg.CreatedAtStart,
+		arg.CreatedAtEnd,
+		arg.Uuid,
+		arg.ResellerReference,
+		arg.MaxResults,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []BookingForUpdateRow
+	var items []int64
 	for rows.Next() {
-		var i BookingForUpdateRow
-		if err := rows.Scan(&i.Status, &i.UnitID); err != nil {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, id)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -114,38 +207,42 @@ func (q *Queries) BookingForUpdate(ctx context.Context, arg BookingForUpdatePara
 	return items, nil
 }
 
-const bookingWithPrice = `-- name: BookingWithPrice :many
-SELECT bookings.id, bookings.created_at, bookings.updated_at, bookings.deleted_at, bookings.product_id, bookings.availability_id, bookings.user_id, bookings.status, units.id, units.created_at, units.updated_at, units.deleted_at, units.booking_id, units.ticket, prices.id, prices.created_at, prices.updated_at, prices.deleted_at, prices.price, prices.currency, prices.product_id
+const bookings = `-- name: Bookings :many
+SELECT bookings.id, bookings.created_at, bookings.updated_at, bookings.deleted_at, bookings.product_id, bookings.availability_id, bookings.user_id, bookings.status, bookings.cancellation_reason, bookings.cancelled_at, bookings.expires_at, bookings.uuid, bookings.reseller_reference, bookings.option_id, bookings.voucher, bookings.currency, bookings.promo_code_id, bookings.discount_type, bookings.discount_amount, options.code AS option_code, units.id, units.created_at, units.updated_at, units.deleted_at, units.booking_id, units.ticket, units.unit_type_id, units.redeemed_at, units.redeemed_by, units.price, units.original, units.net, units.included_taxes, units.ticket_reference, unit_types.code AS unit_type_code, promo_codes.code AS promo_code
 FROM bookings
+JOIN options ON options.id = bookings.option_id
+LEFT JOIN promo_codes ON promo_codes.id = bookings.promo_code_id
 LEFT JOIN units ON units.booking_id = bookings.id
-JOIN prices ON  prices.product_id = bookings.product_id
-WHERE bookings.id = $1
+LEFT JOIN unit_types ON unit_types.id = units.unit_type_id
+WHERE bookings.id = ANY($1::BIGINT[])
 AND bookings.user_id = $2
 AND bookings.deleted_at IS NULL
 AND units.deleted_at IS NULL
-AND prices.deleted_at IS NULL
+ORDER BY bookings.id, units.id
 `
 
-type BookingWithPriceParams struct {
-	ID     int64
+type BookingsParams struct {
+	Ids    []int64
 	UserID int32
 }
 
-type BookingWithPriceRow struct {
-	Booking Booking
-	Unit    Unit
-	Price   Price
+type BookingsRow struct {
+	Booking      Booking
+	OptionCode   string
+	Unit         Unit
+	UnitTypeCode sql.NullString
+	PromoCode    sql.NullString
 }
 
-func (q *Queries) BookingWithPrice(ctx context.Context, arg BookingWithPriceParams) ([]BookingWithPriceRow, error) {
-	rows, err := q.db.QueryContext(ctx, bookingWithPrice, arg.ID, arg.UserID)
+func (q *Queries) Bookings(ctx context.Context, arg BookingsParams) ([]BookingsRow, error) {
+	rows, err := q.db.QueryContext(ctx, bookings, pq.Array(arg.Ids), arg.UserID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []BookingWithPriceRow
+	var items []BookingsRow
 	for rows.Next() {
-		var i BookingWithPriceRow
+		var i BookingsRow
 		if err := rows.Scan(
 			&i.Booking.ID,
 			&i.Booking.CreatedAt,
@@ -155,19 +252,34 @@ func (q *Queries) BookingWithPrice(ctx context.Context, arg BookingWithPricePara
 			&i.Booking.AvailabilityID,
 			&i.Booking.UserID,
 			&i.Booking.Status,
+			&i.Booking.CancellationReason,
+			&i.Booking.CancelledAt,
+			&i.Booking.ExpiresAt,
+			&i.Booking.Uuid,
+			&i.Booking.ResellerReference,
+			&i.Booking.OptionID,
+			&i.Booking.Voucher,
+			&i.Booking.Currency,
+			&i.Booking.PromoCodeID,
+			&i.Booking.DiscountType,
+			&i.Booking.DiscountAmount,
+			&i.OptionCode,
 			&i.Unit.ID,
 			&i.Unit.CreatedAt,
 			&i.Unit.UpdatedAt,
 			&i.Unit.DeletedAt,
 			&i.Unit.BookingID,
 			&i.Unit.Ticket,
-			&i.Price.ID,
-			&i.Price.CreatedAt,
-			&i.Price.UpdatedAt,
-			&i.Price.DeletedAt,
-			&i.Price.Price,
-			&i.Price.Currency,
-			&i.Price.ProductID,
+			&i.Unit.UnitTypeID,
+			&i.Unit.RedeemedAt,
+			&i.Unit.RedeemedBy,
+			&i.Unit.Price,
+			&i.Unit.Original,
+			&i.Unit.Net,
+			&i.Unit.IncludedTaxes,
+			&i.Unit.TicketReference,
+			&i.UnitTypeCode,
+			&i.PromoCode,
 		); err != nil {
 			return nil, err
 		}
@@ -182,9 +294,31 @@ func (q *Queries) BookingWithPrice(ctx context.Context, arg BookingWithPricePara
 	return items, nil
 }
 
+const cancelBooking = `-- name: CancelBooking :exec
+UPDATE bookings
+SET status = 'CANCELLED',
+    voucher = NULL,
+    cancellation_reason = $1::VARCHAR,
+    cancelled_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+`
+
+type CancelBookingParams struct {
+	Reason string
+	ID     int64
+}
+
+// invalidates the voucher, tickets of units are invalidated by InvalidateTickets
+func (q *Queries) CancelBooking(ctx context.Context, arg CancelBookingParams) error {
+	_, err := q.db.ExecContext(ctx, cancelBooking, arg.Reason, arg.ID)
+	return err
+}
+
 const confirmBooking = `-- name: ConfirmBooking :exec
 UPDATE bookings
 SET status = 'CONFIRMED',
+    expires_at = NULL,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
 `
@@ -197,34 +331,60 @@ func (q *Queries) ConfirmBooking(ctx context.Context, id int64) error {
 const createBooking = `-- name: CreateBooking :one
 WITH reservation AS (
     UPDATE availabilities
-    SET vacancies = vacancies - $1, 
+    SET vacancies = CASE WHEN freesale THEN vacancies ELSE vacancies - $1 END, -- freesale has unlimited vacancies
         updated_at = CURRENT_TIMESTAMP
     WHERE id = $2::INTEGER
     AND product_id = $3::INTEGER
+    AND ( -- availability must belong to the option, if given
+        $4::VARCHAR IS NULL
+        OR option_id = (
+            SELECT options.id FROM options
+            WHERE options.product_id = $3::INTEGER
+            AND options.code = $4::VARCHAR
+            AND options.deleted_at IS NULL
+        )
+    )
+    AND NOT closed
     AND deleted_at IS NULL
-    AND vacancies >= $1
-    RETURNING product_id, id AS availability_id
+    AND (freesale OR vacancies >= $1)
+    RETURNING product_id, option_id, id AS availability_id
 ),
 reserved_booking AS (
-    INSERT INTO bookings (product_id, availability_id, user_id, status)
-    SELECT reservation.product_id, reservation.availability_id, $4, 'RESERVED' 
+    INSERT INTO bookings (product_id, option_id, availability_id, user_id, status, expires_at, uuid, reseller_reference, currency, promo_code_id, discount_type, discount_amount)
+    SELECT reservation.product_id, reservation.option_id, reservation.availability_id, $5, 'RESERVED', $6::TIMESTAMPTZ,
+        COALESCE($7::UUID, gen_random_uuid()), $8::VARCHAR, $9::VARCHAR,
+        $10::INTEGER, $11::discount_type, $12::INTEGER
     FROM reservation
     RETURNING id
 ),
 new_units AS (
-    INSERT INTO units (booking_id)
-    SELECT reserved_booking.id 
-    FROM reserved_booking, generate_series(1, $1) -- creating units number of rows
+    -- units keep the price they are reserved for, units without a price are free
+    INSERT INTO units (booking_id, unit_type_id, price, original, net, included_taxes)
+    SELECT reserved_booking.id, NULLIF(unit_type.id, 0), -- zero is a unit without type
+        COALESCE(prices.price, 0), COALESCE(prices.original, prices.price, 0), prices.net, COALESCE(prices.included_taxes, '[]')
+    FROM reserved_booking, unnest($13::INTEGER[]) WITH ORDINALITY AS unit_type(id, n) -- one row per unit
+    JOIN unnest($14::BIGINT[]) WITH ORDINALITY AS unit_price(id, n) USING (n) -- price of each unit
+    LEFT JOIN prices ON prices.id = unit_price.id
 )
 SELECT id
 FROM reserved_booking
 `
 
 type CreateBookingParams struct {
-	Units          int32
-	AvailabilityID int32
-	ProductID      int32
-	UserID         int32
+	Units             int32
+	AvailabilityID    int32
+	ProductID         int32
+	OptionCode        sql.NullString
+	UserID            int32
+	ExpiresAt         time.Time
+	Uuid              uuid.NullUUID
+	ResellerReference sql.NullString
+	Currency          string
+	PromoCodeID       sql.NullInt32
+	DiscountType      NullDiscountType
+	DiscountAmount    sql.NullInt32
+	UnitTypeIds       []int32
+	PriceIds          []int64
 }
 
 func (q *Queries) CreateBooking(ctx context.Context, arg CreateBookingParams) (int64, error) {
@@ -232,26 +392,377 @@ func (q *Queries) CreateBooking(ctx context.Context, arg CreateBookingParams) (i
 		arg.Units,
 		arg.AvailabilityID,
 		arg.ProductID,
+		arg.OptionCode,
 		arg.UserID,
+		arg.ExpiresAt,
+		arg.Uuid,
+		arg.ResellerReference,
+		arg.Currency,
+		arg.PromoCodeID,
+		arg.DiscountType,
+		arg.DiscountAmount,
+		pq.Array(arg.UnitTypeIds),
+		pq.Array(arg.PriceIds),
 	)
 	var id int64
 	err := row.Scan(&id)
 	return id, err
 }
 
+const expireBooking = `-- name: ExpireBooking :exec
+UPDATE bookings
+SET status = 'EXPIRED',
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) ExpireBooking(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, expireBooking, id)
+	return err
+}
+
+const expiredBookingsForUpdate = `-- name: ExpiredBookingsForUpdate :many
+SELECT bookings.id, bookings.availability_id, bookings.promo_code_id, (
+    SELECT COUNT(*)
+    FROM units
+    WHERE units.booking_id = bookings.id
+    AND units.deleted_at IS NULL
+) AS units
+FROM bookings
+WHERE bookings.status = 'RESERVED'
+AND bookings.expires_at <= $1::TIMESTAMPTZ
+AND bookings.deleted_at IS NULL
+FOR UPDATE SKIP LOCKED
+`
+
+type ExpiredBookingsForUpdateRow struct {
+	ID             int64
+	AvailabilityID int32
+	PromoCodeID    sql.NullInt32
+	Units          int64
+}
+
+// skips bookings locked by other transactions, they will be picked up on the next run
+func (q *Queries) ExpiredBookingsForUpdate(ctx context.Context, now time.Time) ([]ExpiredBookingsForUpdateRow, error) {
+	rows, err := q.db.QueryContext(ctx, expiredBookingsForUpdate, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExpiredBookingsForUpdateRow
+	for rows.Next() {
+		var i ExpiredBookingsForUpdateRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AvailabilityID,
+			&i.PromoCodeID,
+			&i.Units,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const extendBooking = `-- name: ExtendBooking :exec
+UPDATE bookings
+SET expires_at = $1::TIMESTAMPTZ,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+`
+
+type ExtendBookingParams struct {
+	ExpiresAt time.Time
+	ID        int64
+}
+
+func (q *Queries) ExtendBooking(ctx context.Context, arg ExtendBookingParams) error {
+	_, err := q.db.ExecContext(ctx, extendBooking, arg.ExpiresAt, arg.ID)
+	return err
+}
+
+const invalidateTickets = `-- name: InvalidateTickets :exec
+UPDATE units
+SET ticket = NULL,
+    ticket_reference = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE booking_id = $1
+AND deleted_at IS NULL
+`
+
+func (q *Queries) InvalidateTickets(ctx context.Context, bookingID int64) error {
+	_, err := q.db.ExecContext(ctx, invalidateTickets, bookingID)
+	return err
+}
+
+const redeemUnit = `-- name: RedeemUnit :one
+UPDATE units
+SET redeemed_at = CURRENT_TIMESTAMP,
+    redeemed_by = $1::INTEGER,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+RETURNING redeemed_at
+`
+
+type RedeemUnitParams struct {
+	RedeemedBy int32
+	ID         int64
+}
+
+func (q *Queries) RedeemUnit(ctx context.Context, arg RedeemUnitParams) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, redeemUnit, arg.RedeemedBy, arg.ID)
+	var redeemed_at sql.NullTime
+	err := row.Scan(&redeemed_at)
+	return redeemed_at, err
+}
+
+const removeUnits = `-- name: RemoveUnits :exec
+UPDATE units
+SET deleted_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ANY($1::BIGINT[])
+`
+
+func (q *Queries) RemoveUnits(ctx context.Context, ids []int64) error {
+	_, err := q.db.ExecContext(ctx, removeUnits, pq.Array(ids))
+	return err
+}
+
+const setBookingAvailability = `-- name: SetBookingAvailability :exec
+UPDATE bookings
+SET availability_id = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+`
+
+type SetBookingAvailabilityParams struct {
+	AvailabilityID int32
+	ID             int64
+}
+
+func (q *Queries) SetBookingAvailability(ctx context.Context, arg SetBookingAvailabilityParams) error {
+	_, err := q.db.ExecContext(ctx, setBookingAvailability, arg.AvailabilityID, arg.ID)
+	return err
+}
+
+const setBookingVoucher = `-- name: SetBookingVoucher :exec
+UPDATE bookings
+SET voucher = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+`
+
+type SetBookingVoucherParams struct {
+	Voucher sql.NullString
+	ID      int64
+}
+
+func (q *Queries) SetBookingVoucher(ctx context.Context, arg SetBookingVoucherParams) error {
+	_, err := q.db.ExecContext(ctx, setBookingVoucher, arg.Voucher, arg.ID)
+	return err
+}
+
+const setUnitPrices = `-- name: SetUnitPrices :exec
+UPDATE units
+SET price = COALESCE(prices.price, 0),
+    original = COALESCE(prices.original, prices.price, 0),
+    net = prices.net,
+    included_taxes = COALESCE(prices.included_taxes, '[]'),
+    updated_at = CURRENT_TIMESTAMP
+FROM unnest($1::BIGINT[]) WITH ORDINALITY AS unit(id, n)
+JOIN unnest($2::BIGINT[]) WITH ORDINALITY AS unit_price(price_id, n) USING (n)
+LEFT JOIN prices ON prices.id = unit_price.price_id
+WHERE units.id = unit.id
+`
+
+type SetUnitPricesParams struct {
+	Ids      []int64
+	PriceIds []int64
+}
+
+// ids and price IDs are in the same order, units without a price are free
+func (q *Queries) SetUnitPrices(ctx context.Context, arg SetUnitPricesParams) error {
+	_, err := q.db.ExecContext(ctx, setUnitPrices, pq.Array(arg.Ids), pq.Array(arg.PriceIds))
+	return err
+}
+
 const setUnitTicket = `-- name: SetUnitTicket :exec
 UPDATE units
 SET ticket = $1,
+    ticket_reference = $2,
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $2
+WHERE id = $3
 `
 
 type SetUnitTicketParams struct {
-	Ticket sql.NullString
-	ID     int64
+	Ticket          sql.NullString
+	TicketReference sql.NullString
+	ID              int64
 }
 
 func (q *Queries) SetUnitTicket(ctx context.Context, arg SetUnitTicketParams) error {
-	_, err := q.db.ExecContext(ctx, setUnitTicket, arg.Ticket, arg.ID)
+	_, err := q.db.ExecContext(ctx, setUnitTicket, arg.Ticket, arg.TicketReference, arg.ID)
 	return err
 }
+
+const ticketForUpdate = `-- name: TicketForUpdate :one
+SELECT units.id AS unit_id, units.redeemed_at, units.ticket, unit_types.code AS unit_type_code,
+    bookings.id AS booking_id, bookings.status, bookings.product_id, bookings.availability_id,
+    availabilities.local_date, products.timezone
+FROM units
+JOIN bookings ON bookings.id = units.booking_id
+JOIN availabilities ON availabilities.id = bookings.availability_id
+JOIN products ON products.id = bookings.product_id
+LEFT JOIN unit_types ON unit_types.id = units.unit_type_id
+WHERE $1::VARCHAR IN (units.ticket, units.ticket_reference)
+AND bookings.user_id = $2
+AND units.deleted_at IS NULL
+AND bookings.deleted_at IS NULL
+FOR UPDATE OF units
+`
+
+type TicketForUpdateParams struct {
+	Ticket string
+	UserID int32
+}
+
+type TicketForUpdateRow struct {
+	UnitID         int64
+	RedeemedAt     sql.NullTime
+	Ticket         sql.NullString
+	UnitTypeCode   sql.NullString
+	BookingID      int64
+	Status         internal.BookingStatus
+	ProductID      int32
+	AvailabilityID int32
+	LocalDate      time.Time
+	Timezone       string
+}
+
+// unit with the ticket or its reference of the user's booking, its booking and the local date of its availability
+func (q *Queries) TicketForUpdate(ctx context.Context, arg TicketForUpdateParams) (TicketForUpdateRow, error) {
+	row := q.db.QueryRowContext(ctx, ticketForUpdate, arg.Ticket, arg.UserID)
+	var i TicketForUpdateRow
+	err := row.Scan(
+		&i.UnitID,
+		&i.RedeemedAt,
+		&i.Ticket,
+		&i.UnitTypeCode,
+		&i.BookingID,
+		&i.Status,
+		&i.ProductID,
+		&i.AvailabilityID,
+		&i.LocalDate,
+		&i.Timezone,
+	)
+	return i, err
+}
+
+const unitTicket = `-- name: UnitTicket :one
+SELECT units.ticket, units.ticket_reference, products.delivery_format
+FROM units
+JOIN bookings ON bookings.id = units.booking_id
+JOIN products ON products.id = bookings.product_id
+WHERE units.id = $1
+AND units.booking_id = $2
+AND bookings.user_id = $3
+AND units.ticket IS NOT NULL
+AND units.deleted_at IS NULL
+AND bookings.deleted_at IS NULL
+`
+
+type UnitTicketParams struct {
+	ID        int64
+	BookingID int64
+	UserID    int32
+}
+
+type UnitTicketRow struct {
+	Ticket          sql.NullString
+	TicketReference sql.NullString
+	DeliveryFormat  internal.DeliveryFormat
+}
+
+func (q *Queries) UnitTicket(ctx context.Context, arg UnitTicketParams) (UnitTicketRow, error) {
+	row := q.db.QueryRowContext(ctx, unitTicket, arg.ID, arg.BookingID, arg.UserID)
+	var i UnitTicketRow
+	err := row.Scan(&i.Ticket, &i.TicketReference, &i.DeliveryFormat)
+	return i, err
+}
+
+const voucherForUpdate = `-- name: VoucherForUpdate :many
+SELECT units.id AS unit_id, units.redeemed_at, units.ticket, unit_types.code AS unit_type_code,
+    bookings.id AS booking_id, bookings.status, bookings.product_id, bookings.availability_id,
+    availabilities.local_date, products.timezone
+FROM bookings
+JOIN units ON units.booking_id = bookings.id
+JOIN availabilities ON availabilities.id = bookings.availability_id
+JOIN products ON products.id = bookings.product_id
+LEFT JOIN unit_types ON unit_types.id = units.unit_type_id
+WHERE bookings.voucher = $1::VARCHAR
+AND bookings.user_id = $2
+AND units.deleted_at IS NULL
+AND bookings.deleted_at IS NULL
+ORDER BY units.id
+FOR UPDATE OF units
+`
+
+type VoucherForUpdateParams struct {
+	Voucher string
+	UserID  int32
+}
+
+type VoucherForUpdateRow struct {
+	UnitID         int64
+	RedeemedAt     sql.NullTime
+	Ticket         sql.NullString
+	UnitTypeCode   sql.NullString
+	BookingID      int64
+	Status         internal.BookingStatus
+	ProductID      int32
+	AvailabilityID int32
+	LocalDate      time.Time
+	Timezone       string
+}
+
+// units of the user's booking with the voucher and the local date of its availability
+func (q *Queries) VoucherForUpdate(ctx context.Context, arg VoucherForUpdateParams) ([]VoucherForUpdateRow, error) {
+	rows, err := q.db.QueryContext(ctx, voucherForUpdate, arg.Voucher, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VoucherForUpdateRow
+	for rows.Next() {
+		var i VoucherForUpdateRow
+		if err := rows.Scan(
+			&i.UnitID,
+			&i.RedeemedAt,
+			&i.Ticket,
+			&i.UnitTypeCode,
+			&i.BookingID,
+			&i.Status,
+			&i.ProductID,
+			&i.AvailabilityID,
+			&i.LocalDate,
+			&i.Timezone,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
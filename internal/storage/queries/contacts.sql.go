@@ -0,0 +1,121 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: contacts.sql
+
+package queries
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+const confirmationContact = `-- name: ConfirmationContact :one
+SELECT products.contact_required_to_confirm, contacts.full_name, contacts.email_address
+FROM products
+LEFT JOIN contacts ON contacts.booking_id = $1
+WHERE products.id = $2
+`
+
+type ConfirmationContactParams struct {
+	BookingID int64
+	ProductID int32
+}
+
+type ConfirmationContactRow struct {
+	ContactRequiredToConfirm bool
+	FullName                 sql.NullString
+	EmailAddress             sql.NullString
+}
+
+// contact of the booking and whether the product requires it to confirm
+func (q *Queries) ConfirmationContact(ctx context.Context, arg ConfirmationContactParams) (ConfirmationContactRow, error) {
+	row := q.db.QueryRowContext(ctx, confirmationContact, arg.BookingID, arg.ProductID)
+	var i ConfirmationContactRow
+	err := row.Scan(&i.ContactRequiredToConfirm, &i.FullName, &i.EmailAddress)
+	return i, err
+}
+
+const contacts = `-- name: Contacts :many
+SELECT booking_id, created_at, updated_at, full_name, email_address, phone_number, locales, country, notes FROM contacts
+WHERE booking_id = ANY($1::BIGINT[])
+`
+
+func (q *Queries) Contacts(ctx context.Context, bookingIds []int64) ([]Contact, error) {
+	rows, err := q.db.QueryContext(ctx, contacts, pq.Array(bookingIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Contact
+	for rows.Next() {
+		var i Contact
+		if err := rows.Scan(
+			&i.BookingID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FullName,
+			&i.EmailAddress,
+			&i.PhoneNumber,
+			pq.Array(&i.Locales),
+			&i.Country,
+			&i.Notes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertContact = `-- name: UpsertContact :exec
+INSERT INTO contacts (booking_id, full_name, email_address, phone_number, locales, country, notes)
+VALUES (
+    $1,
+    $2::VARCHAR,
+    $3::VARCHAR,
+    $4::VARCHAR,
+    $5::VARCHAR[],
+    $6::CHAR(2),
+    $7::TEXT
+)
+ON CONFLICT (booking_id) DO UPDATE
+SET full_name = EXCLUDED.full_name,
+    email_address = EXCLUDED.email_address,
+    phone_number = EXCLUDED.phone_number,
+    locales = EXCLUDED.locales,
+    country = EXCLUDED.country,
+    notes = EXCLUDED.notes,
+    updated_at = CURRENT_TIMESTAMP
+`
+
+type UpsertContactParams struct {
+	BookingID    int64
+	FullName     sql.NullString
+	EmailAddress sql.NullString
+	PhoneNumber  sql.NullString
+	Locales      []string
+	Country      sql.NullString
+	Notes        sql.NullString
+}
+
+func (q *Queries) UpsertContact(ctx context.Context, arg UpsertContactParams) error {
+	_, err := q.db.ExecContext(ctx, upsertContact,
+		arg.BookingID,
+		arg.FullName,
+		arg.EmailAddress,
+		arg.PhoneNumber,
+		pq.Array(arg.Locales),
+		arg.Country,
+		arg.Notes,
+	)
+	return err
+}
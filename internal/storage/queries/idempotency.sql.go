@@ -0,0 +1,132 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: idempotency.sql
+
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE (response_status IS NULL AND created_at < $1::TIMESTAMPTZ)
+OR (response_status IS NOT NULL AND updated_at < $2::TIMESTAMPTZ)
+`
+
+type DeleteExpiredIdempotencyKeysParams struct {
+	InProgressBefore time.Time
+	CompletedBefore  time.Time
+}
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, arg DeleteExpiredIdempotencyKeysParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredIdempotencyKeys, arg.InProgressBefore, arg.CompletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1::INTEGER
+AND key = $2::VARCHAR
+AND response_status IS NULL
+`
+
+type DeleteIdempotencyKeyParams struct {
+	UserID int32
+	Key    string
+}
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKey, arg.UserID, arg.Key)
+	return err
+}
+
+const idempotencyKey = `-- name: IdempotencyKey :one
+SELECT request_hash, response_status, response_content_type, response_body
+FROM idempotency_keys
+WHERE user_id = $1::INTEGER
+AND key = $2::VARCHAR
+`
+
+type IdempotencyKeyParams struct {
+	UserID int32
+	Key    string
+}
+
+type IdempotencyKeyRow struct {
+	RequestHash         []byte
+	ResponseStatus      sql.NullInt32
+	ResponseContentType sql.NullString
+	ResponseBody        []byte
+}
+
+func (q *Queries) IdempotencyKey(ctx context.Context, arg IdempotencyKeyParams) (IdempotencyKeyRow, error) {
+	row := q.db.QueryRowContext(ctx, idempotencyKey, arg.UserID, arg.Key)
+	var i IdempotencyKeyRow
+	err := row.Scan(
+		&i.RequestHash,
+		&i.ResponseStatus,
+		&i.ResponseContentType,
+		&i.ResponseBody,
+	)
+	return i, err
+}
+
+const insertIdempotencyKey = `-- name: InsertIdempotencyKey :execrows
+INSERT INTO idempotency_keys (user_id, key, request_hash)
+VALUES (
+    $1::INTEGER,
+    $2::VARCHAR,
+    $3::BYTEA
+)
+ON CONFLICT (user_id, key) DO NOTHING
+`
+
+type InsertIdempotencyKeyParams struct {
+	UserID      int32
+	Key         string
+	RequestHash []byte
+}
+
+func (q *Queries) InsertIdempotencyKey(ctx context.Context, arg InsertIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, insertIdempotencyKey, arg.UserID, arg.Key, arg.RequestHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setIdempotencyKeyResponse = `-- name: SetIdempotencyKeyResponse :exec
+UPDATE idempotency_keys
+SET response_status = $1::INTEGER,
+    response_content_type = $2::VARCHAR,
+    response_body = $3::BYTEA,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $4::INTEGER
+AND key = $5::VARCHAR
+`
+
+type SetIdempotencyKeyResponseParams struct {
+	ResponseStatus      int32
+	ResponseContentType string
+	ResponseBody        []byte
+	UserID              int32
+	Key                 string
+}
+
+func (q *Queries) SetIdempotencyKeyResponse(ctx context.Context, arg SetIdempotencyKeyResponseParams) error {
+	_, err := q.db.ExecContext(ctx, setIdempotencyKeyResponse,
+		arg.ResponseStatus,
+		arg.ResponseContentType,
+		arg.ResponseBody,
+		arg.UserID,
+		arg.Key,
+	)
+	return err
+}
@@ -11,13 +11,58 @@ import (
 	"time"
 
 	"github.com/dmksnnk/octo/internal"
+	"github.com/google/uuid"
 )
 
+type AvailabilityType string
+
+const (
+	AvailabilityTypeSTARTTIME    AvailabilityType = "START_TIME"
+	AvailabilityTypeOPENINGHOURS AvailabilityType = "OPENING_HOURS"
+)
+
+func (e *AvailabilityType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AvailabilityType(s)
+	case string:
+		*e = AvailabilityType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AvailabilityType: %T", src)
+	}
+	return nil
+}
+
+type NullAvailabilityType struct {
+	AvailabilityType AvailabilityType
+	Valid            bool // Valid is true if AvailabilityType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAvailabilityType) Scan(value interface{}) error {
+	if value == nil {
+		ns.AvailabilityType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AvailabilityType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAvailabilityType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AvailabilityType), nil
+}
+
 type BookingStatus string
 
 const (
 	BookingStatusRESERVED  BookingStatus = "RESERVED"
 	BookingStatusCONFIRMED BookingStatus = "CONFIRMED"
+	BookingStatusCANCELLED BookingStatus = "CANCELLED"
+	BookingStatusEXPIRED   BookingStatus = "EXPIRED"
 )
 
 func (e *BookingStatus) Scan(src interface{}) error {
@@ -55,53 +100,335 @@ func (ns NullBookingStatus) Value() (driver.Value, error) {
 	return string(ns.BookingStatus), nil
 }
 
+type DeliveryFormat string
+
+const (
+	DeliveryFormatQRCODE  DeliveryFormat = "QRCODE"
+	DeliveryFormatCODE128 DeliveryFormat = "CODE128"
+)
+
+func (e *DeliveryFormat) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DeliveryFormat(s)
+	case string:
+		*e = DeliveryFormat(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DeliveryFormat: %T", src)
+	}
+	return nil
+}
+
+type NullDeliveryFormat struct {
+	DeliveryFormat DeliveryFormat
+	Valid          bool // Valid is true if DeliveryFormat is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDeliveryFormat) Scan(value interface{}) error {
+	if value == nil {
+		ns.DeliveryFormat, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DeliveryFormat.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDeliveryFormat) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DeliveryFormat), nil
+}
+
+type DeliveryMethod string
+
+const (
+	DeliveryMethodTICKET  DeliveryMethod = "TICKET"
+	DeliveryMethodVOUCHER DeliveryMethod = "VOUCHER"
+)
+
+func (e *DeliveryMethod) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DeliveryMethod(s)
+	case string:
+		*e = DeliveryMethod(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DeliveryMethod: %T", src)
+	}
+	return nil
+}
+
+type NullDeliveryMethod struct {
+	DeliveryMethod DeliveryMethod
+	Valid          bool // Valid is true if DeliveryMethod is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDeliveryMethod) Scan(value interface{}) error {
+	if value == nil {
+		ns.DeliveryMethod, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DeliveryMethod.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDeliveryMethod) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DeliveryMethod), nil
+}
+
+type DiscountType string
+
+const (
+	DiscountTypePERCENTAGE DiscountType = "PERCENTAGE"
+	DiscountTypeFIXED      DiscountType = "FIXED"
+)
+
+func (e *DiscountType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = DiscountType(s)
+	case string:
+		*e = DiscountType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for DiscountType: %T", src)
+	}
+	return nil
+}
+
+type NullDiscountType struct {
+	DiscountType DiscountType
+	Valid        bool // Valid is true if DiscountType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullDiscountType) Scan(value interface{}) error {
+	if value == nil {
+		ns.DiscountType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.DiscountType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullDiscountType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.DiscountType), nil
+}
+
+type WaitlistStatus string
+
+const (
+	WaitlistStatusWAITING  WaitlistStatus = "WAITING"
+	WaitlistStatusRESERVED WaitlistStatus = "RESERVED"
+)
+
+func (e *WaitlistStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WaitlistStatus(s)
+	case string:
+		*e = WaitlistStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WaitlistStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWaitlistStatus struct {
+	WaitlistStatus WaitlistStatus
+	Valid          bool // Valid is true if WaitlistStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWaitlistStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WaitlistStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WaitlistStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWaitlistStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WaitlistStatus), nil
+}
+
 type Availability struct {
-	ID        int32
-	CreatedAt sql.NullTime
-	UpdatedAt sql.NullTime
-	DeletedAt sql.NullTime
-	ProductID int32
-	LocalDate time.Time
-	Vacancies int32
+	ID                 int32
+	CreatedAt          sql.NullTime
+	UpdatedAt          sql.NullTime
+	DeletedAt          sql.NullTime
+	ProductID          int32
+	LocalDate          time.Time
+	Vacancies          int32
+	OptionID           int32
+	LocalDateTimeStart time.Time
+	LocalDateTimeEnd   time.Time
+	AllDay             bool
+	Closed             bool
+	Freesale           bool
+	Capacity           int32
 }
 
 type Booking struct {
-	ID             int64
+	ID                 int64
+	CreatedAt          sql.NullTime
+	UpdatedAt          sql.NullTime
+	DeletedAt          sql.NullTime
+	ProductID          int32
+	AvailabilityID     int32
+	UserID             int32
+	Status             internal.BookingStatus
+	CancellationReason sql.NullString
+	CancelledAt        sql.NullTime
+	ExpiresAt          sql.NullTime
+	Uuid               uuid.UUID
+	ResellerReference  sql.NullString
+	OptionID           int32
+	Voucher            sql.NullString
+	Currency           string
+	PromoCodeID        sql.NullInt32
+	DiscountType       NullDiscountType
+	DiscountAmount     sql.NullInt32
+}
+
+type Contact struct {
+	BookingID    int64
+	CreatedAt    sql.NullTime
+	UpdatedAt    sql.NullTime
+	FullName     sql.NullString
+	EmailAddress sql.NullString
+	PhoneNumber  sql.NullString
+	Locales      []string
+	Country      sql.NullString
+	Notes        sql.NullString
+}
+
+type IdempotencyKey struct {
+	UserID              int32
+	Key                 string
+	CreatedAt           sql.NullTime
+	UpdatedAt           sql.NullTime
+	RequestHash         []byte
+	ResponseStatus      sql.NullInt32
+	ResponseContentType sql.NullString
+	ResponseBody        []byte
+}
+
+type OpeningHour struct {
+	ID             int32
 	CreatedAt      sql.NullTime
 	UpdatedAt      sql.NullTime
 	DeletedAt      sql.NullTime
-	ProductID      int32
 	AvailabilityID int32
-	UserID         int32
-	Status         internal.BookingStatus
+	TimeFrom       time.Time
+	TimeTo         time.Time
 }
 
-type Price struct {
-	ID        int64
+type Option struct {
+	ID        int32
 	CreatedAt sql.NullTime
 	UpdatedAt sql.NullTime
 	DeletedAt sql.NullTime
-	Price     int32
-	Currency  string
 	ProductID int32
+	Code      string
+	Name      string
+	Capacity  int32
+}
+
+type Price struct {
+	ID             int64
+	CreatedAt      sql.NullTime
+	UpdatedAt      sql.NullTime
+	DeletedAt      sql.NullTime
+	Price          int32
+	Currency       string
+	ProductID      int32
+	UnitTypeID     sql.NullInt32
+	OptionID       sql.NullInt32
+	AvailabilityID sql.NullInt32
+	LocalDateStart sql.NullTime
+	LocalDateEnd   sql.NullTime
+	Weekdays       []int32
+	Priority       int32
+	Original       sql.NullInt32
+	Net            sql.NullInt32
+	IncludedTaxes  internal.IncludedTaxes
 }
 
 type Product struct {
-	ID        int32
-	CreatedAt sql.NullTime
-	UpdatedAt sql.NullTime
-	DeletedAt sql.NullTime
-	Name      string
-	Capacity  int32
+	ID                        int32
+	CreatedAt                 sql.NullTime
+	UpdatedAt                 sql.NullTime
+	DeletedAt                 sql.NullTime
+	Name                      string
+	Capacity                  int32
+	MaxReservationHoldMinutes int32
+	ContactRequiredToConfirm  bool
+	AvailabilityType          internal.AvailabilityType
+	Timezone                  string
+	LimitedThreshold          int32
+	DeliveryFormat            internal.DeliveryFormat
+	DeliveryMethods           []internal.DeliveryMethod
+	DefaultCurrency           string
+}
+
+type PromoCode struct {
+	ID           int32
+	CreatedAt    sql.NullTime
+	UpdatedAt    sql.NullTime
+	DeletedAt    sql.NullTime
+	Code         string
+	DiscountType DiscountType
+	Amount       int32
+	Currency     sql.NullString
+	ValidFrom    sql.NullTime
+	ValidUntil   sql.NullTime
+	MaxUses      sql.NullInt32
+	Uses         int32
+	ProductIds   []int32
 }
 
 type Unit struct {
-	ID        int64
+	ID              int64
+	CreatedAt       sql.NullTime
+	UpdatedAt       sql.NullTime
+	DeletedAt       sql.NullTime
+	BookingID       int64
+	Ticket          sql.NullString
+	UnitTypeID      sql.NullInt32
+	RedeemedAt      sql.NullTime
+	RedeemedBy      sql.NullInt32
+	Price           int32
+	Original        int32
+	Net             sql.NullInt32
+	IncludedTaxes   internal.IncludedTaxes
+	TicketReference sql.NullString
+}
+
+type UnitType struct {
+	ID        int32
 	CreatedAt sql.NullTime
 	UpdatedAt sql.NullTime
 	DeletedAt sql.NullTime
-	BookingID int64
-	Ticket    sql.NullString
+	ProductID int32
+	Code      string
+	Name      string
 }
 
 type User struct {
@@ -112,3 +439,20 @@ type User struct {
 	Email     string
 	ApiKey    sql.NullString
 }
+
+type WaitlistEntry struct {
+	ID             int64
+	CreatedAt      sql.NullTime
+	UpdatedAt      sql.NullTime
+	DeletedAt      sql.NullTime
+	UserID         int32
+	AvailabilityID int32
+	OptionID       int32
+	Currency       string
+	Units          int32
+	UnitTypeIds    []int32
+	Status         internal.WaitlistStatus
+	BookingID      sql.NullInt64
+	WebhookUrl     sql.NullString
+	NotifiedAt     sql.NullTime
+}
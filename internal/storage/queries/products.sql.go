@@ -7,10 +7,168 @@ package queries
 
 import (
 	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
 )
 
+const currencies = `-- name: Currencies :many
+SELECT DISTINCT product_id, currency
+FROM prices
+WHERE product_id = ANY($1::INTEGER[])
+AND unit_type_id IS NULL
+AND option_id IS NULL
+AND priority = 0
+AND deleted_at IS NULL
+ORDER BY product_id, currency
+`
+
+type CurrenciesRow struct {
+	ProductID int32
+	Currency  string
+}
+
+// currencies the products have a base price in
+func (q *Queries) Currencies(ctx context.Context, productIds []int32) ([]CurrenciesRow, error) {
+	rows, err := q.db.QueryContext(ctx, currencies, pq.Array(productIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CurrenciesRow
+	for rows.Next() {
+		var i CurrenciesRow
+		if err := rows.Scan(&i.ProductID, &i.Currency); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const options = `-- name: Options :many
+SELECT id, created_at, updated_at, deleted_at, product_id, code, name, capacity FROM options
+WHERE product_id = ANY($1::INTEGER[])
+AND deleted_at IS NULL
+ORDER BY id
+`
+
+func (q *Queries) Options(ctx context.Context, productIds []int32) ([]Option, error) {
+	rows, err := q.db.QueryContext(ctx, options, pq.Array(productIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Option
+	for rows.Next() {
+		var i Option
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ProductID,
+			&i.Code,
+			&i.Name,
+			&i.Capacity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const optionsWithPrices = `-- name: OptionsWithPrices :many
+SELECT DISTINCT ON (options.id) options.id, options.created_at, options.updated_at, options.deleted_at, options.product_id, options.code, options.name, options.capacity, prices.id, prices.created_at, prices.updated_at, prices.deleted_at, prices.price, prices.currency, prices.product_id, prices.unit_type_id, prices.option_id, prices.availability_id, prices.local_date_start, prices.local_date_end, prices.weekdays, prices.priority, prices.original, prices.net, prices.included_taxes
+FROM options
+JOIN products ON products.id = options.product_id
+JOIN prices ON prices.product_id = options.product_id
+    AND (prices.option_id = options.id OR prices.option_id IS NULL)
+WHERE options.product_id = ANY($1::INTEGER[])
+AND options.deleted_at IS NULL
+AND prices.deleted_at IS NULL
+AND prices.unit_type_id IS NULL
+AND prices.priority = 0 -- not a price rule
+AND prices.currency = COALESCE($2::VARCHAR, products.default_currency)
+ORDER BY options.id, prices.option_id NULLS LAST
+`
+
+type OptionsWithPricesParams struct {
+	ProductIds []int32
+	Currency   sql.NullString
+}
+
+type OptionsWithPricesRow struct {
+	Option Option
+	Price  Price
+}
+
+// options with their price, falling back to the product's base price,
+// in the currency, or in the product's default currency if NULL
+func (q *Queries) OptionsWithPrices(ctx context.Context, arg OptionsWithPricesParams) ([]OptionsWithPricesRow, error) {
+	rows, err := q.db.QueryContext(ctx, optionsWithPrices, pq.Array(arg.ProductIds), arg.Currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OptionsWithPricesRow
+	for rows.Next() {
+		var i OptionsWithPricesRow
+		if err := rows.Scan(
+			&i.Option.ID,
+			&i.Option.CreatedAt,
+			&i.Option.UpdatedAt,
+			&i.Option.DeletedAt,
+			&i.Option.ProductID,
+			&i.Option.Code,
+			&i.Option.Name,
+			&i.Option.Capacity,
+			&i.Price.ID,
+			&i.Price.CreatedAt,
+			&i.Price.UpdatedAt,
+			&i.Price.DeletedAt,
+			&i.Price.Price,
+			&i.Price.Currency,
+			&i.Price.ProductID,
+			&i.Price.UnitTypeID,
+			&i.Price.OptionID,
+			&i.Price.AvailabilityID,
+			&i.Price.LocalDateStart,
+			&i.Price.LocalDateEnd,
+			pq.Array(&i.Price.Weekdays),
+			&i.Price.Priority,
+			&i.Price.Original,
+			&i.Price.Net,
+			&i.Price.IncludedTaxes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const product = `-- name: Product :one
-SELECT id, created_at, updated_at, deleted_at, name, capacity FROM products
+SELECT id, created_at, updated_at, deleted_at, name, capacity, max_reservation_hold_minutes, contact_required_to_confirm, availability_type, timezone, limited_threshold, delivery_format, delivery_methods, default_currency FROM products
 WHERE products.id = $1
 AND products.deleted_at IS NULL
 `
@@ -25,26 +183,43 @@ func (q *Queries) Product(ctx context.Context, id int32) (Product, error) {
 		&i.DeletedAt,
 		&i.Name,
 		&i.Capacity,
+		&i.MaxReservationHoldMinutes,
+		&i.ContactRequiredToConfirm,
+		&i.AvailabilityType,
+		&i.Timezone,
+		&i.LimitedThreshold,
+		&i.DeliveryFormat,
+		pq.Array(&i.DeliveryMethods),
+		&i.DefaultCurrency,
 	)
 	return i, err
 }
 
 const productWithPrice = `-- name: ProductWithPrice :one
-SELECT products.id, products.created_at, products.updated_at, products.deleted_at, products.name, products.capacity, prices.id, prices.created_at, prices.updated_at, prices.deleted_at, prices.price, prices.currency, prices.product_id
+SELECT products.id, products.created_at, products.updated_at, products.deleted_at, products.name, products.capacity, products.max_reservation_hold_minutes, products.contact_required_to_confirm, products.availability_type, products.timezone, products.limited_threshold, products.delivery_format, products.delivery_methods, products.default_currency, prices.id, prices.created_at, prices.updated_at, prices.deleted_at, prices.price, prices.currency, prices.product_id, prices.unit_type_id, prices.option_id, prices.availability_id, prices.local_date_start, prices.local_date_end, prices.weekdays, prices.priority, prices.original, prices.net, prices.included_taxes
 FROM products
 JOIN prices ON products.id = prices.product_id
 WHERE products.id = $1
 AND products.deleted_at IS NULL
 AND prices.deleted_at IS NULL
+AND prices.unit_type_id IS NULL -- base price
+AND prices.option_id IS NULL
+AND prices.priority = 0 -- not a price rule
+AND prices.currency = $2::VARCHAR
 `
 
+type ProductWithPriceParams struct {
+	ID       int32
+	Currency string
+}
+
 type ProductWithPriceRow struct {
 	Product Product
 	Price   Price
 }
 
-func (q *Queries) ProductWithPrice(ctx context.Context, id int32) (ProductWithPriceRow, error) {
-	row := q.db.QueryRowContext(ctx, productWithPrice, id)
+func (q *Queries) ProductWithPrice(ctx context.Context, arg ProductWithPriceParams) (ProductWithPriceRow, error) {
+	row := q.db.QueryRowContext(ctx, productWithPrice, arg.ID, arg.Currency)
 	var i ProductWithPriceRow
 	err := row.Scan(
 		&i.Product.ID,
@@ -53,6 +228,14 @@ func (q *Queries) ProductWithPrice(ctx context.Context, id int32) (ProductWithPr
 		&i.Product.DeletedAt,
 		&i.Product.Name,
 		&i.Product.Capacity,
+		&i.Product.MaxReservationHoldMinutes,
+		&i.Product.ContactRequiredToConfirm,
+		&i.Product.AvailabilityType,
+		&i.Product.Timezone,
+		&i.Product.LimitedThreshold,
+		&i.Product.DeliveryFormat,
+		pq.Array(&i.Product.DeliveryMethods),
+		&i.Product.DefaultCurrency,
 		&i.Price.ID,
 		&i.Price.CreatedAt,
 		&i.Price.UpdatedAt,
@@ -60,12 +243,22 @@ func (q *Queries) ProductWithPrice(ctx context.Context, id int32) (ProductWithPr
 		&i.Price.Price,
 		&i.Price.Currency,
 		&i.Price.ProductID,
+		&i.Price.UnitTypeID,
+		&i.Price.OptionID,
+		&i.Price.AvailabilityID,
+		&i.Price.LocalDateStart,
+		&i.Price.LocalDateEnd,
+		pq.Array(&i.Price.Weekdays),
+		&i.Price.Priority,
+		&i.Price.Original,
+		&i.Price.Net,
+		&i.Price.IncludedTaxes,
 	)
 	return i, err
 }
 
 const products = `-- name: Products :many
-SELECT id, created_at, updated_at, deleted_at, name, capacity FROM products
+SELECT id, created_at, updated_at, deleted_at, name, capacity, max_reservation_hold_minutes, contact_required_to_confirm, availability_type, timezone, limited_threshold, delivery_format, delivery_methods, default_currency FROM products
 WHERE products.deleted_at IS NULL
 `
 
@@ -85,6 +278,14 @@ func (q *Queries) Products(ctx context.Context) ([]Product, error) {
 			&i.DeletedAt,
 			&i.Name,
 			&i.Capacity,
+			&i.MaxReservationHoldMinutes,
+			&i.ContactRequiredToConfirm,
+			&i.AvailabilityType,
+			&i.Timezone,
+			&i.LimitedThreshold,
+			&i.DeliveryFormat,
+			pq.Array(&i.DeliveryMethods),
+			&i.DefaultCurrency,
 		); err != nil {
 			return nil, err
 		}
@@ -100,11 +301,15 @@ func (q *Queries) Products(ctx context.Context) ([]Product, error) {
 }
 
 const productsWithPrices = `-- name: ProductsWithPrices :many
-SELECT products.id, products.created_at, products.updated_at, products.deleted_at, products.name, products.capacity, prices.id, prices.created_at, prices.updated_at, prices.deleted_at, prices.price, prices.currency, prices.product_id
+SELECT products.id, products.created_at, products.updated_at, products.deleted_at, products.name, products.capacity, products.max_reservation_hold_minutes, products.contact_required_to_confirm, products.availability_type, products.timezone, products.limited_threshold, products.delivery_format, products.delivery_methods, products.default_currency, prices.id, prices.created_at, prices.updated_at, prices.deleted_at, prices.price, prices.currency, prices.product_id, prices.unit_type_id, prices.option_id, prices.availability_id, prices.local_date_start, prices.local_date_end, prices.weekdays, prices.priority, prices.original, prices.net, prices.included_taxes
 FROM products
 JOIN prices ON products.id = prices.product_id
 WHERE products.deleted_at IS NULL 
 AND prices.deleted_at IS NULL
+AND prices.unit_type_id IS NULL -- base price
+AND prices.option_id IS NULL
+AND prices.priority = 0 -- not a price rule
+AND prices.currency = COALESCE($1::VARCHAR, products.default_currency)
 `
 
 type ProductsWithPricesRow struct {
@@ -112,8 +317,10 @@ type ProductsWithPricesRow struct {
 	Price   Price
 }
 
-func (q *Queries) ProductsWithPrices(ctx context.Context) ([]ProductsWithPricesRow, error) {
-	rows, err := q.db.QueryContext(ctx, productsWithPrices)
+// products priced in the currency, or in their default currency if NULL,
+// products without a price in the currency are skipped
+func (q *Queries) ProductsWithPrices(ctx context.Context, currency sql.NullString) ([]ProductsWithPricesRow, error) {
+	rows, err := q.db.QueryContext(ctx, productsWithPrices, currency)
 	if err != nil {
 		return nil, err
 	}
@@ -128,6 +335,167 @@ func (q *Queries) ProductsWithPrices(ctx context.Context) ([]ProductsWithPricesR
 			&i.Product.DeletedAt,
 			&i.Product.Name,
 			&i.Product.Capacity,
+			&i.Product.MaxReservationHoldMinutes,
+			&i.Product.ContactRequiredToConfirm,
+			&i.Product.AvailabilityType,
+			&i.Product.Timezone,
+			&i.Product.LimitedThreshold,
+			&i.Product.DeliveryFormat,
+			pq.Array(&i.Product.DeliveryMethods),
+			&i.Product.DefaultCurrency,
+			&i.Price.ID,
+			&i.Price.CreatedAt,
+			&i.Price.UpdatedAt,
+			&i.Price.DeletedAt,
+			&i.Price.Price,
+			&i.Price.Currency,
+			&i.Price.ProductID,
+			&i.Price.UnitTypeID,
+			&i.Price.OptionID,
+			&i.Price.AvailabilityID,
+			&i.Price.LocalDateStart,
+			&i.Price.LocalDateEnd,
+			pq.Array(&i.Price.Weekdays),
+			&i.Price.Priority,
+			&i.Price.Original,
+			&i.Price.Net,
+			&i.Price.IncludedTaxes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unitTypeIDs = `-- name: UnitTypeIDs :many
+SELECT id, code FROM unit_types
+WHERE product_id = $1
+AND code = ANY($2::VARCHAR[])
+AND deleted_at IS NULL
+`
+
+type UnitTypeIDsParams struct {
+	ProductID int32
+	Codes     []string
+}
+
+type UnitTypeIDsRow struct {
+	ID   int32
+	Code string
+}
+
+func (q *Queries) UnitTypeIDs(ctx context.Context, arg UnitTypeIDsParams) ([]UnitTypeIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, unitTypeIDs, arg.ProductID, pq.Array(arg.Codes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UnitTypeIDsRow
+	for rows.Next() {
+		var i UnitTypeIDsRow
+		if err := rows.Scan(&i.ID, &i.Code); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unitTypes = `-- name: UnitTypes :many
+SELECT id, created_at, updated_at, deleted_at, product_id, code, name FROM unit_types
+WHERE product_id = ANY($1::INTEGER[])
+AND deleted_at IS NULL
+ORDER BY id
+`
+
+func (q *Queries) UnitTypes(ctx context.Context, productIds []int32) ([]UnitType, error) {
+	rows, err := q.db.QueryContext(ctx, unitTypes, pq.Array(productIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UnitType
+	for rows.Next() {
+		var i UnitType
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ProductID,
+			&i.Code,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unitTypesWithPrices = `-- name: UnitTypesWithPrices :many
+SELECT DISTINCT ON (unit_types.id) unit_types.id, unit_types.created_at, unit_types.updated_at, unit_types.deleted_at, unit_types.product_id, unit_types.code, unit_types.name, prices.id, prices.created_at, prices.updated_at, prices.deleted_at, prices.price, prices.currency, prices.product_id, prices.unit_type_id, prices.option_id, prices.availability_id, prices.local_date_start, prices.local_date_end, prices.weekdays, prices.priority, prices.original, prices.net, prices.included_taxes
+FROM unit_types
+JOIN products ON products.id = unit_types.product_id
+JOIN prices ON prices.product_id = unit_types.product_id
+    AND (prices.unit_type_id = unit_types.id OR prices.unit_type_id IS NULL)
+WHERE unit_types.product_id = ANY($1::INTEGER[])
+AND unit_types.deleted_at IS NULL
+AND prices.deleted_at IS NULL
+AND prices.option_id IS NULL
+AND prices.priority = 0 -- not a price rule
+AND prices.currency = COALESCE($2::VARCHAR, products.default_currency)
+ORDER BY unit_types.id, prices.unit_type_id NULLS LAST
+`
+
+type UnitTypesWithPricesParams struct {
+	ProductIds []int32
+	Currency   sql.NullString
+}
+
+type UnitTypesWithPricesRow struct {
+	UnitType UnitType
+	Price    Price
+}
+
+// unit types with their price, falling back to the product's base price,
+// in the currency, or in the product's default currency if NULL
+func (q *Queries) UnitTypesWithPrices(ctx context.Context, arg UnitTypesWithPricesParams) ([]UnitTypesWithPricesRow, error) {
+	rows, err := q.db.QueryContext(ctx, unitTypesWithPrices, pq.Array(arg.ProductIds), arg.Currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UnitTypesWithPricesRow
+	for rows.Next() {
+		var i UnitTypesWithPricesRow
+		if err := rows.Scan(
+			&i.UnitType.ID,
+			&i.UnitType.CreatedAt,
+			&i.UnitType.UpdatedAt,
+			&i.UnitType.DeletedAt,
+			&i.UnitType.ProductID,
+			&i.UnitType.Code,
+			&i.UnitType.Name,
 			&i.Price.ID,
 			&i.Price.CreatedAt,
 			&i.Price.UpdatedAt,
@@ -135,6 +503,16 @@ func (q *Queries) ProductsWithPrices(ctx context.Context) ([]ProductsWithPricesR
 			&i.Price.Price,
 			&i.Price.Currency,
 			&i.Price.ProductID,
+			&i.Price.UnitTypeID,
+			&i.Price.OptionID,
+			&i.Price.AvailabilityID,
+			&i.Price.LocalDateStart,
+			&i.Price.LocalDateEnd,
+			pq.Array(&i.Price.Weekdays),
+			&i.Price.Priority,
+			&i.Price.Original,
+			&i.Price.Net,
+			&i.Price.IncludedTaxes,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,67 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: promo_codes.sql
+
+package queries
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+const promoCodeForUpdate = `-- name: PromoCodeForUpdate :one
+SELECT id, created_at, updated_at, deleted_at, code, discount_type, amount, currency, valid_from, valid_until, max_uses, uses, product_ids FROM promo_codes
+WHERE upper(code) = upper($1::VARCHAR)
+AND deleted_at IS NULL
+FOR UPDATE
+`
+
+// locks the promo code, so concurrent bookings do not exceed its uses
+func (q *Queries) PromoCodeForUpdate(ctx context.Context, code string) (PromoCode, error) {
+	row := q.db.QueryRowContext(ctx, promoCodeForUpdate, code)
+	var i PromoCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Code,
+		&i.DiscountType,
+		&i.Amount,
+		&i.Currency,
+		&i.ValidFrom,
+		&i.ValidUntil,
+		&i.MaxUses,
+		&i.Uses,
+		pq.Array(&i.ProductIds),
+	)
+	return i, err
+}
+
+const releasePromoCode = `-- name: ReleasePromoCode :exec
+UPDATE promo_codes
+SET uses = uses - 1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+AND uses > 0
+`
+
+// gives the use back when the booking is cancelled or expires
+func (q *Queries) ReleasePromoCode(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, releasePromoCode, id)
+	return err
+}
+
+const usePromoCode = `-- name: UsePromoCode :exec
+UPDATE promo_codes
+SET uses = uses + 1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) UsePromoCode(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, usePromoCode, id)
+	return err
+}
@@ -9,26 +9,71 @@ import (
 	"context"
 	"database/sql"
 	"time"
+
+	"github.com/dmksnnk/octo/internal"
+	"github.com/lib/pq"
 )
 
+const closeAvailability = `-- name: CloseAvailability :exec
+UPDATE availabilities
+SET closed = TRUE,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+// used in tests, like an operator shutting an availability
+func (q *Queries) CloseAvailability(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, closeAvailability, id)
+	return err
+}
+
 const insertAvailability = `-- name: InsertAvailability :one
-INSERT INTO availabilities (product_id, local_date, vacancies, deleted_at) 
-VALUES ($1, $2, $3, $4)
-RETURNING id, created_at, updated_at, deleted_at, product_id, local_date, vacancies
+INSERT INTO availabilities (product_id, option_id, local_date, local_date_time_start, local_date_time_end, all_day, capacity, vacancies, closed, freesale, deleted_at) 
+VALUES (
+    $1,
+    COALESCE(
+        $2::INTEGER,
+        (SELECT id FROM options WHERE options.product_id = $1 AND code = 'DEFAULT' AND options.deleted_at IS NULL)
+    ),
+    $3,
+    COALESCE($4::TIMESTAMP, $3::DATE::TIMESTAMP),
+    COALESCE($5::TIMESTAMP, ($3::DATE + 1)::TIMESTAMP),
+    $4::TIMESTAMP IS NULL,
+    $6::INTEGER,
+    $7,
+    $8,
+    $9,
+    $10
+)
+RETURNING id, created_at, updated_at, deleted_at, product_id, local_date, vacancies, option_id, local_date_time_start, local_date_time_end, all_day, closed, freesale, capacity
 `
 
 type InsertAvailabilityParams struct {
-	ProductID int32
-	LocalDate time.Time
-	Vacancies int32
-	DeletedAt sql.NullTime
+	ProductID          int32
+	OptionID           sql.NullInt32
+	LocalDate          time.Time
+	LocalDateTimeStart sql.NullTime
+	LocalDateTimeEnd   sql.NullTime
+	Capacity           sql.NullInt32
+	Vacancies          int32
+	Closed             bool
+	Freesale           bool
+	DeletedAt          sql.NullTime
 }
 
+// option defaults to the product's DEFAULT option, slot defaults to the whole local date,
+// capacity defaults to the option's capacity
 func (q *Queries) InsertAvailability(ctx context.Context, arg InsertAvailabilityParams) (Availability, error) {
 	row := q.db.QueryRowContext(ctx, insertAvailability,
 		arg.ProductID,
+		arg.OptionID,
 		arg.LocalDate,
+		arg.LocalDateTimeStart,
+		arg.LocalDateTimeEnd,
+		arg.Capacity,
 		arg.Vacancies,
+		arg.Closed,
+		arg.Freesale,
 		arg.DeletedAt,
 	)
 	var i Availability
@@ -40,29 +85,121 @@ func (q *Queries) InsertAvailability(ctx context.Context, arg InsertAvailability
 		&i.ProductID,
 		&i.LocalDate,
 		&i.Vacancies,
+		&i.OptionID,
+		&i.LocalDateTimeStart,
+		&i.LocalDateTimeEnd,
+		&i.AllDay,
+		&i.Closed,
+		&i.Freesale,
+		&i.Capacity,
 	)
 	return i, err
 }
 
-const insertPrice = `-- name: InsertPrice :one
-INSERT INTO prices (price, currency, product_id, deleted_at)
-VALUES ($1, $2, $3, $4) 
-RETURNING id, created_at, updated_at, deleted_at, price, currency, product_id
+const insertOpeningHours = `-- name: InsertOpeningHours :one
+INSERT INTO opening_hours (availability_id, time_from, time_to)
+VALUES ($1, $2, $3)
+RETURNING id, created_at, updated_at, deleted_at, availability_id, time_from, time_to
 `
 
-type InsertPriceParams struct {
-	Price     int32
-	Currency  string
+type InsertOpeningHoursParams struct {
+	AvailabilityID int32
+	TimeFrom       time.Time
+	TimeTo         time.Time
+}
+
+// used in tests
+func (q *Queries) InsertOpeningHours(ctx context.Context, arg InsertOpeningHoursParams) (OpeningHour, error) {
+	row := q.db.QueryRowContext(ctx, insertOpeningHours, arg.AvailabilityID, arg.TimeFrom, arg.TimeTo)
+	var i OpeningHour
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.AvailabilityID,
+		&i.TimeFrom,
+		&i.TimeTo,
+	)
+	return i, err
+}
+
+const insertOption = `-- name: InsertOption :one
+INSERT INTO options (product_id, code, name, capacity, deleted_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at, updated_at, deleted_at, product_id, code, name, capacity
+`
+
+type InsertOptionParams struct {
 	ProductID int32
+	Code      string
+	Name      string
+	Capacity  int32
 	DeletedAt sql.NullTime
 }
 
+// used in tests
+func (q *Queries) InsertOption(ctx context.Context, arg InsertOptionParams) (Option, error) {
+	row := q.db.QueryRowContext(ctx, insertOption,
+		arg.ProductID,
+		arg.Code,
+		arg.Name,
+		arg.Capacity,
+		arg.DeletedAt,
+	)
+	var i Option
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ProductID,
+		&i.Code,
+		&i.Name,
+		&i.Capacity,
+	)
+	return i, err
+}
+
+const insertPrice = `-- name: InsertPrice :one
+INSERT INTO prices (price, original, net, included_taxes, currency, product_id, unit_type_id, option_id, availability_id, local_date_start, local_date_end, weekdays, priority, deleted_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) 
+RETURNING id, created_at, updated_at, deleted_at, price, currency, product_id, unit_type_id, option_id, availability_id, local_date_start, local_date_end, weekdays, priority, original, net, included_taxes
+`
+
+type InsertPriceParams struct {
+	Price          int32
+	Original       sql.NullInt32
+	Net            sql.NullInt32
+	IncludedTaxes  internal.IncludedTaxes
+	Currency       string
+	ProductID      int32
+	UnitTypeID     sql.NullInt32
+	OptionID       sql.NullInt32
+	AvailabilityID sql.NullInt32
+	LocalDateStart sql.NullTime
+	LocalDateEnd   sql.NullTime
+	Weekdays       []int32
+	Priority       int32
+	DeletedAt      sql.NullTime
+}
+
 // used in tests
 func (q *Queries) InsertPrice(ctx context.Context, arg InsertPriceParams) (Price, error) {
 	row := q.db.QueryRowContext(ctx, insertPrice,
 		arg.Price,
+		arg.Original,
+		arg.Net,
+		arg.IncludedTaxes,
 		arg.Currency,
 		arg.ProductID,
+		arg.UnitTypeID,
+		arg.OptionID,
+		arg.AvailabilityID,
+		arg.LocalDateStart,
+		arg.LocalDateEnd,
+		pq.Array(arg.Weekdays),
+		arg.Priority,
 		arg.DeletedAt,
 	)
 	var i Price
@@ -74,25 +211,55 @@ func (q *Queries) InsertPrice(ctx context.Context, arg InsertPriceParams) (Price
 		&i.Price,
 		&i.Currency,
 		&i.ProductID,
+		&i.UnitTypeID,
+		&i.OptionID,
+		&i.AvailabilityID,
+		&i.LocalDateStart,
+		&i.LocalDateEnd,
+		pq.Array(&i.Weekdays),
+		&i.Priority,
+		&i.Original,
+		&i.Net,
+		&i.IncludedTaxes,
 	)
 	return i, err
 }
 
 const insertProduct = `-- name: InsertProduct :one
-INSERT INTO products (name, capacity, deleted_at) 
-VALUES ($1, $2, $3) 
-RETURNING id, created_at, updated_at, deleted_at, name, capacity
+INSERT INTO products (name, capacity, max_reservation_hold_minutes, contact_required_to_confirm, availability_type, timezone, limited_threshold, delivery_format, delivery_methods, default_currency, deleted_at) 
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) 
+RETURNING id, created_at, updated_at, deleted_at, name, capacity, max_reservation_hold_minutes, contact_required_to_confirm, availability_type, timezone, limited_threshold, delivery_format, delivery_methods, default_currency
 `
 
 type InsertProductParams struct {
-	Name      string
-	Capacity  int32
-	DeletedAt sql.NullTime
+	Name                      string
+	Capacity                  int32
+	MaxReservationHoldMinutes int32
+	ContactRequiredToConfirm  bool
+	AvailabilityType          internal.AvailabilityType
+	Timezone                  string
+	LimitedThreshold          int32
+	DeliveryFormat            internal.DeliveryFormat
+	DeliveryMethods           []internal.DeliveryMethod
+	DefaultCurrency           string
+	DeletedAt                 sql.NullTime
 }
 
 // used in tests
 func (q *Queries) InsertProduct(ctx context.Context, arg InsertProductParams) (Product, error) {
-	row := q.db.QueryRowContext(ctx, insertProduct, arg.Name, arg.Capacity, arg.DeletedAt)
+	row := q.db.QueryRowContext(ctx, insertProduct,
+		arg.Name,
+		arg.Capacity,
+		arg.MaxReservationHoldMinutes,
+		arg.ContactRequiredToConfirm,
+		arg.AvailabilityType,
+		arg.Timezone,
+		arg.LimitedThreshold,
+		arg.DeliveryFormat,
+		pq.Array(arg.DeliveryMethods),
+		arg.DefaultCurrency,
+		arg.DeletedAt,
+	)
 	var i Product
 	err := row.Scan(
 		&i.ID,
@@ -101,6 +268,100 @@ func (q *Queries) InsertProduct(ctx context.Context, arg InsertProductParams) (P
 		&i.DeletedAt,
 		&i.Name,
 		&i.Capacity,
+		&i.MaxReservationHoldMinutes,
+		&i.ContactRequiredToConfirm,
+		&i.AvailabilityType,
+		&i.Timezone,
+		&i.LimitedThreshold,
+		&i.DeliveryFormat,
+		pq.Array(&i.DeliveryMethods),
+		&i.DefaultCurrency,
+	)
+	return i, err
+}
+
+const insertPromoCode = `-- name: InsertPromoCode :one
+INSERT INTO promo_codes (code, discount_type, amount, currency, valid_from, valid_until, max_uses, uses, product_ids, deleted_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, created_at, updated_at, deleted_at, code, discount_type, amount, currency, valid_from, valid_until, max_uses, uses, product_ids
+`
+
+type InsertPromoCodeParams struct {
+	Code         string
+	DiscountType DiscountType
+	Amount       int32
+	Currency     sql.NullString
+	ValidFrom    sql.NullTime
+	ValidUntil   sql.NullTime
+	MaxUses      sql.NullInt32
+	Uses         int32
+	ProductIds   []int32
+	DeletedAt    sql.NullTime
+}
+
+// used in tests
+func (q *Queries) InsertPromoCode(ctx context.Context, arg InsertPromoCodeParams) (PromoCode, error) {
+	row := q.db.QueryRowContext(ctx, insertPromoCode,
+		arg.Code,
+		arg.DiscountType,
+		arg.Amount,
+		arg.Currency,
+		arg.ValidFrom,
+		arg.ValidUntil,
+		arg.MaxUses,
+		arg.Uses,
+		pq.Array(arg.ProductIds),
+		arg.DeletedAt,
+	)
+	var i PromoCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Code,
+		&i.DiscountType,
+		&i.Amount,
+		&i.Currency,
+		&i.ValidFrom,
+		&i.ValidUntil,
+		&i.MaxUses,
+		&i.Uses,
+		pq.Array(&i.ProductIds),
+	)
+	return i, err
+}
+
+const insertUnitType = `-- name: InsertUnitType :one
+INSERT INTO unit_types (product_id, code, name, deleted_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, created_at, updated_at, deleted_at, product_id, code, name
+`
+
+type InsertUnitTypeParams struct {
+	ProductID int32
+	Code      string
+	Name      string
+	DeletedAt sql.NullTime
+}
+
+// used in tests
+func (q *Queries) InsertUnitType(ctx context.Context, arg InsertUnitTypeParams) (UnitType, error) {
+	row := q.db.QueryRowContext(ctx, insertUnitType,
+		arg.ProductID,
+		arg.Code,
+		arg.Name,
+		arg.DeletedAt,
+	)
+	var i UnitType
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ProductID,
+		&i.Code,
+		&i.Name,
 	)
 	return i, err
 }
@@ -130,3 +391,39 @@ func (q *Queries) InsertUser(ctx context.Context, arg InsertUserParams) (User, e
 	)
 	return i, err
 }
+
+const setCapacity = `-- name: SetCapacity :exec
+UPDATE availabilities
+SET capacity = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+`
+
+type SetCapacityParams struct {
+	Capacity int32
+	ID       int32
+}
+
+// used in tests, like an operator lowering the capacity of an availability
+func (q *Queries) SetCapacity(ctx context.Context, arg SetCapacityParams) error {
+	_, err := q.db.ExecContext(ctx, setCapacity, arg.Capacity, arg.ID)
+	return err
+}
+
+const setVacancies = `-- name: SetVacancies :exec
+UPDATE availabilities
+SET vacancies = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+`
+
+type SetVacanciesParams struct {
+	Vacancies int32
+	ID        int32
+}
+
+// used in tests, like an operator topping up an availability
+func (q *Queries) SetVacancies(ctx context.Context, arg SetVacanciesParams) error {
+	_, err := q.db.ExecContext(ctx, setVacancies, arg.Vacancies, arg.ID)
+	return err
+}
@@ -0,0 +1,274 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: waitlist.sql
+
+package queries
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+const availabilityProductID = `-- name: AvailabilityProductID :one
+SELECT product_id FROM availabilities
+WHERE id = $1
+AND deleted_at IS NULL
+`
+
+func (q *Queries) AvailabilityProductID(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, availabilityProductID, id)
+	var product_id int32
+	err := row.Scan(&product_id)
+	return product_id, err
+}
+
+const createWaitlistEntry = `-- name: CreateWaitlistEntry :one
+INSERT INTO waitlist_entries (user_id, availability_id, option_id, units, unit_type_ids, webhook_url, currency)
+SELECT $1, availabilities.id, availabilities.option_id, cardinality($2::INTEGER[]), $2::INTEGER[],
+    $3::VARCHAR, $4::VARCHAR
+FROM availabilities
+JOIN options ON options.id = availabilities.option_id
+WHERE availabilities.id = $5
+AND availabilities.deleted_at IS NULL
+AND ($6::VARCHAR IS NULL OR options.code = $6::VARCHAR)
+AND options.deleted_at IS NULL
+RETURNING id
+`
+
+type CreateWaitlistEntryParams struct {
+	UserID         int32
+	UnitTypeIds    []int32
+	WebhookUrl     sql.NullString
+	Currency       string
+	AvailabilityID int32
+	OptionCode     sql.NullString
+}
+
+// no rows if the availability does not exist or does not belong to the option, if given
+func (q *Queries) CreateWaitlistEntry(ctx context.Context, arg CreateWaitlistEntryParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, createWaitlistEntry,
+		arg.UserID,
+		pq.Array(arg.UnitTypeIds),
+		arg.WebhookUrl,
+		arg.Currency,
+		arg.AvailabilityID,
+		arg.OptionCode,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const reserveWaitlistEntry = `-- name: ReserveWaitlistEntry :exec
+UPDATE waitlist_entries
+SET status = 'RESERVED',
+    booking_id = $1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $2
+`
+
+type ReserveWaitlistEntryParams struct {
+	BookingID sql.NullInt64
+	ID        int64
+}
+
+func (q *Queries) ReserveWaitlistEntry(ctx context.Context, arg ReserveWaitlistEntryParams) error {
+	_, err := q.db.ExecContext(ctx, reserveWaitlistEntry, arg.BookingID, arg.ID)
+	return err
+}
+
+const setWaitlistEntryNotified = `-- name: SetWaitlistEntryNotified :exec
+UPDATE waitlist_entries
+SET notified_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (q *Queries) SetWaitlistEntryNotified(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, setWaitlistEntryNotified, id)
+	return err
+}
+
+const unnotifiedWaitlistEntries = `-- name: UnnotifiedWaitlistEntries :many
+SELECT waitlist_entries.id, waitlist_entries.created_at, waitlist_entries.updated_at, waitlist_entries.deleted_at, waitlist_entries.user_id, waitlist_entries.availability_id, waitlist_entries.option_id, waitlist_entries.currency, waitlist_entries.units, waitlist_entries.unit_type_ids, waitlist_entries.status, waitlist_entries.booking_id, waitlist_entries.webhook_url, waitlist_entries.notified_at, options.code AS option_code
+FROM waitlist_entries
+JOIN options ON options.id = waitlist_entries.option_id
+WHERE waitlist_entries.status = 'RESERVED'
+AND waitlist_entries.webhook_url IS NOT NULL
+AND waitlist_entries.notified_at IS NULL
+AND waitlist_entries.deleted_at IS NULL
+ORDER BY waitlist_entries.id
+`
+
+type UnnotifiedWaitlistEntriesRow struct {
+	WaitlistEntry WaitlistEntry
+	OptionCode    string
+}
+
+// reserved entries with a webhook which was not delivered yet
+func (q *Queries) UnnotifiedWaitlistEntries(ctx context.Context) ([]UnnotifiedWaitlistEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, unnotifiedWaitlistEntries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UnnotifiedWaitlistEntriesRow
+	for rows.Next() {
+		var i UnnotifiedWaitlistEntriesRow
+		if err := rows.Scan(
+			&i.WaitlistEntry.ID,
+			&i.WaitlistEntry.CreatedAt,
+			&i.WaitlistEntry.UpdatedAt,
+			&i.WaitlistEntry.DeletedAt,
+			&i.WaitlistEntry.UserID,
+			&i.WaitlistEntry.AvailabilityID,
+			&i.WaitlistEntry.OptionID,
+			&i.WaitlistEntry.Currency,
+			&i.WaitlistEntry.Units,
+			pq.Array(&i.WaitlistEntry.UnitTypeIds),
+			&i.WaitlistEntry.Status,
+			&i.WaitlistEntry.BookingID,
+			&i.WaitlistEntry.WebhookUrl,
+			&i.WaitlistEntry.NotifiedAt,
+			&i.OptionCode,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const waitingEntriesForUpdate = `-- name: WaitingEntriesForUpdate :many
+SELECT waitlist_entries.id, waitlist_entries.user_id, waitlist_entries.unit_type_ids, waitlist_entries.currency, availabilities.product_id, options.code AS option_code
+FROM waitlist_entries
+JOIN availabilities ON availabilities.id = waitlist_entries.availability_id
+JOIN options ON options.id = waitlist_entries.option_id
+WHERE waitlist_entries.availability_id = $1
+AND waitlist_entries.status = 'WAITING'
+AND waitlist_entries.deleted_at IS NULL
+ORDER BY waitlist_entries.created_at, waitlist_entries.id
+FOR UPDATE OF waitlist_entries SKIP LOCKED
+`
+
+type WaitingEntriesForUpdateRow struct {
+	ID          int64
+	UserID      int32
+	UnitTypeIds []int32
+	Currency    string
+	ProductID   int32
+	OptionCode  string
+}
+
+// oldest first, skips entries locked by other transactions, they will be picked up on the next run
+func (q *Queries) WaitingEntriesForUpdate(ctx context.Context, availabilityID int32) ([]WaitingEntriesForUpdateRow, error) {
+	rows, err := q.db.QueryContext(ctx, waitingEntriesForUpdate, availabilityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WaitingEntriesForUpdateRow
+	for rows.Next() {
+		var i WaitingEntriesForUpdateRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			pq.Array(&i.UnitTypeIds),
+			&i.Currency,
+			&i.ProductID,
+			&i.OptionCode,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const waitlistEntry = `-- name: WaitlistEntry :one
+SELECT waitlist_entries.id, waitlist_entries.created_at, waitlist_entries.updated_at, waitlist_entries.deleted_at, waitlist_entries.user_id, waitlist_entries.availability_id, waitlist_entries.option_id, waitlist_entries.currency, waitlist_entries.units, waitlist_entries.unit_type_ids, waitlist_entries.status, waitlist_entries.booking_id, waitlist_entries.webhook_url, waitlist_entries.notified_at, options.code AS option_code
+FROM waitlist_entries
+JOIN options ON options.id = waitlist_entries.option_id
+WHERE waitlist_entries.id = $1
+AND waitlist_entries.user_id = $2
+AND waitlist_entries.deleted_at IS NULL
+`
+
+type WaitlistEntryParams struct {
+	ID     int64
+	UserID int32
+}
+
+type WaitlistEntryRow struct {
+	WaitlistEntry WaitlistEntry
+	OptionCode    string
+}
+
+func (q *Queries) WaitlistEntry(ctx context.Context, arg WaitlistEntryParams) (WaitlistEntryRow, error) {
+	row := q.db.QueryRowContext(ctx, waitlistEntry, arg.ID, arg.UserID)
+	var i WaitlistEntryRow
+	err := row.Scan(
+		&i.WaitlistEntry.ID,
+		&i.WaitlistEntry.CreatedAt,
+		&i.WaitlistEntry.UpdatedAt,
+		&i.WaitlistEntry.DeletedAt,
+		&i.WaitlistEntry.UserID,
+		&i.WaitlistEntry.AvailabilityID,
+		&i.WaitlistEntry.OptionID,
+		&i.WaitlistEntry.Currency,
+		&i.WaitlistEntry.Units,
+		pq.Array(&i.WaitlistEntry.UnitTypeIds),
+		&i.WaitlistEntry.Status,
+		&i.WaitlistEntry.BookingID,
+		&i.WaitlistEntry.WebhookUrl,
+		&i.WaitlistEntry.NotifiedAt,
+		&i.OptionCode,
+	)
+	return i, err
+}
+
+const waitlistedAvailabilities = `-- name: WaitlistedAvailabilities :many
+SELECT DISTINCT availability_id
+FROM waitlist_entries
+WHERE status = 'WAITING'
+AND deleted_at IS NULL
+ORDER BY availability_id
+`
+
+func (q *Queries) WaitlistedAvailabilities(ctx context.Context) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, waitlistedAvailabilities)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var availability_id int32
+		if err := rows.Scan(&availability_id); err != nil {
+			return nil, err
+		}
+		items = append(items, availability_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/brianvoe/gofakeit/v7"
+	"github.com/dmksnnk/octo/internal"
 	"github.com/dmksnnk/octo/internal/storage/queries"
 )
 
@@ -31,12 +33,19 @@ func NewUser(t *testing.T, db *sql.DB, ops ...func(*queries.InsertUserParams)) q
 	return user
 }
 
+// NewProduct creates a product with a DEFAULT option, like products without variants.
 func NewProduct(t *testing.T, db *sql.DB, ops ...func(*queries.InsertProductParams)) queries.Product {
 	t.Helper()
 
 	p := queries.InsertProductParams{
-		Name:     gofakeit.ProductName(),
-		Capacity: int32(gofakeit.IntRange(1, 100)),
+		Name:                      gofakeit.ProductName(),
+		Capacity:                  int32(gofakeit.IntRange(100, 1000)),
+		MaxReservationHoldMinutes: 60,
+		AvailabilityType:          internal.AvailabilityTypeStartTime,
+		Timezone:                  "UTC",
+		DeliveryFormat:            internal.DeliveryFormatQRCode,
+		DeliveryMethods:           []internal.DeliveryMethod{internal.DeliveryMethodTicket},
+		DefaultCurrency:           "EUR",
 	}
 	for _, op := range ops {
 		op(&p)
@@ -47,16 +56,44 @@ func NewProduct(t *testing.T, db *sql.DB, ops ...func(*queries.InsertProductPara
 		t.Fatalf("insert product: %v", err)
 	}
 
+	NewOption(t, db, product.ID, func(o *queries.InsertOptionParams) {
+		o.Code = internal.DefaultOptionID
+		o.Name = product.Name
+		o.Capacity = product.Capacity
+		o.DeletedAt = product.DeletedAt
+	})
+
 	return product
 }
 
+func NewOption(t *testing.T, db *sql.DB, productID int32, ops ...func(*queries.InsertOptionParams)) queries.Option {
+	t.Helper()
+
+	p := queries.InsertOptionParams{
+		ProductID: productID,
+		Code:      gofakeit.UUID(),
+		Name:      gofakeit.Noun(),
+		Capacity:  int32(gofakeit.IntRange(100, 1000)),
+	}
+	for _, op := range ops {
+		op(&p)
+	}
+
+	option, err := queries.New(db).InsertOption(context.TODO(), p)
+	if err != nil {
+		t.Fatalf("insert option: %v", err)
+	}
+
+	return option
+}
+
 func NewPrice(t *testing.T, db *sql.DB, productID int32, ops ...func(*queries.InsertPriceParams)) queries.Price {
 	t.Helper()
 
 	p := queries.InsertPriceParams{
 		ProductID: productID,
 		Price:     int32(gofakeit.IntRange(1, 1000)),
-		Currency:  gofakeit.CurrencyShort(),
+		Currency:  "EUR", // default currency of NewProduct
 	}
 	for _, op := range ops {
 		op(&p)
@@ -70,13 +107,33 @@ func NewPrice(t *testing.T, db *sql.DB, productID int32, ops ...func(*queries.In
 	return price
 }
 
+func NewUnitType(t *testing.T, db *sql.DB, productID int32, ops ...func(*queries.InsertUnitTypeParams)) queries.UnitType {
+	t.Helper()
+
+	p := queries.InsertUnitTypeParams{
+		ProductID: productID,
+		Code:      gofakeit.UUID(),
+		Name:      gofakeit.Noun(),
+	}
+	for _, op := range ops {
+		op(&p)
+	}
+
+	unitType, err := queries.New(db).InsertUnitType(context.TODO(), p)
+	if err != nil {
+		t.Fatalf("insert unit type: %v", err)
+	}
+
+	return unitType
+}
+
 func NewAvailability(t *testing.T, db *sql.DB, productID int32, ops ...func(*queries.InsertAvailabilityParams)) queries.Availability {
 	t.Helper()
 
 	p := queries.InsertAvailabilityParams{
 		ProductID: productID,
 		LocalDate: gofakeit.Date(),
-		Vacancies: int32(gofakeit.IntRange(1, 1000)),
+		Vacancies: int32(gofakeit.IntRange(1, 100)), // within default capacity
 	}
 	for _, op := range ops {
 		op(&p)
@@ -89,3 +146,46 @@ func NewAvailability(t *testing.T, db *sql.DB, productID int32, ops ...func(*que
 
 	return availability
 }
+
+// NewOpeningHours creates opening hours of an availability, from 09:00 to 17:00 by default.
+// Only the time of day of the params is stored.
+func NewOpeningHours(t *testing.T, db *sql.DB, availabilityID int32, ops ...func(*queries.InsertOpeningHoursParams)) queries.OpeningHour {
+	t.Helper()
+
+	p := queries.InsertOpeningHoursParams{
+		AvailabilityID: availabilityID,
+		TimeFrom:       time.Date(2000, time.January, 1, 9, 0, 0, 0, time.UTC),
+		TimeTo:         time.Date(2000, time.January, 1, 17, 0, 0, 0, time.UTC),
+	}
+	for _, op := range ops {
+		op(&p)
+	}
+
+	openingHours, err := queries.New(db).InsertOpeningHours(context.TODO(), p)
+	if err != nil {
+		t.Fatalf("insert opening hours: %v", err)
+	}
+
+	return openingHours
+}
+
+// NewPromoCode creates a promo code with 10% discount on all products by default.
+func NewPromoCode(t *testing.T, db *sql.DB, ops ...func(*queries.InsertPromoCodeParams)) queries.PromoCode {
+	t.Helper()
+
+	p := queries.InsertPromoCodeParams{
+		Code:         gofakeit.LetterN(10),
+		DiscountType: queries.DiscountTypePERCENTAGE,
+		Amount:       10,
+	}
+	for _, op := range ops {
+		op(&p)
+	}
+
+	promoCode, err := queries.New(db).InsertPromoCode(context.TODO(), p)
+	if err != nil {
+		t.Fatalf("insert promo code: %v", err)
+	}
+
+	return promoCode
+}
@@ -0,0 +1,207 @@
+// Package ticket issues and verifies signed ticket codes.
+//
+// A code embeds the booking, the unit and the local date it is valid on,
+// signed with an Ed25519 key. Scanners verify codes offline with the public keys only.
+// Each code names the key it was signed with, so the signing key can be rotated
+// while codes issued with previous keys stay valid as long as verifiers know their public keys.
+//
+// Verification does not know about cancellations or redemptions,
+// scanners should check with the API once online.
+package ticket
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// version of the code layout.
+const version = 1
+
+var (
+	// ErrMalformed is returned when a code cannot be decoded.
+	ErrMalformed = errors.New("malformed ticket code")
+	// ErrUnknownKey is returned when a code is signed with a key the verifier does not know.
+	ErrUnknownKey = errors.New("unknown ticket key")
+	// ErrInvalidSignature is returned when a code is not signed by its key, e.g. it was forged or altered.
+	ErrInvalidSignature = errors.New("invalid ticket signature")
+)
+
+// encoding is uppercase letters and digits only, to keep QR codes and barcodes compact.
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// referenceSize is the number of random bytes of a reference, enough not to be guessed.
+const referenceSize = 10
+
+// Reference returns a random short code of a ticket, for linear barcodes which cannot fit a signed code.
+// References carry no claims and cannot be verified offline, they are redeemed online only.
+func Reference() string {
+	b := make([]byte, referenceSize)
+	rand.Read(b) // never returns an error
+	return encoding.EncodeToString(b)
+}
+
+// Claims are the details embedded in a ticket code.
+type Claims struct {
+	BookingID int64
+	UnitID    int64     // zero for a voucher, which admits all units of the booking
+	LocalDate time.Time // date of the availability, time of day is ignored
+}
+
+// Signer issues ticket codes.
+type Signer struct {
+	keyID byte
+	key   ed25519.PrivateKey
+}
+
+// NewSigner creates a signer which signs codes with the key, naming it by keyID.
+func NewSigner(keyID byte, key ed25519.PrivateKey) Signer {
+	return Signer{
+		keyID: keyID,
+		key:   key,
+	}
+}
+
+// Sign returns a ticket code for the claims.
+func (s Signer) Sign(c Claims) string {
+	payload := []byte{version, s.keyID}
+	payload = binary.AppendUvarint(payload, uint64(c.BookingID))
+	payload = binary.AppendUvarint(payload, uint64(c.UnitID))
+	payload = binary.AppendVarint(payload, days(c.LocalDate))
+
+	return encoding.EncodeToString(append(payload, ed25519.Sign(s.key, payload)...))
+}
+
+// PublicKey returns the key ID and the public key to verify codes of the signer.
+func (s Signer) PublicKey() (byte, ed25519.PublicKey) {
+	return s.keyID, s.key.Public().(ed25519.PublicKey)
+}
+
+// Verifier validates ticket codes offline.
+type Verifier struct {
+	keys map[byte]ed25519.PublicKey
+}
+
+// NewVerifier creates a verifier which accepts codes signed by any of the keys, by key ID.
+func NewVerifier(keys map[byte]ed25519.PublicKey) Verifier {
+	return Verifier{
+		keys: keys,
+	}
+}
+
+// Verify checks the signature of the code and returns its claims.
+// It returns ErrMalformed, ErrUnknownKey or ErrInvalidSignature if the code is not valid.
+func (v Verifier) Verify(code string) (Claims, error) {
+	data, err := encoding.DecodeString(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil || len(data) < 2+ed25519.SignatureSize {
+		return Claims{}, ErrMalformed
+	}
+	if data[0] != version {
+		return Claims{}, fmt.Errorf("%w: unsupported version %d", ErrMalformed, data[0])
+	}
+
+	payload, signature := data[:len(data)-ed25519.SignatureSize], data[len(data)-ed25519.SignatureSize:]
+	key, ok := v.keys[payload[1]]
+	if !ok {
+		return Claims{}, fmt.Errorf("%w: %d", ErrUnknownKey, payload[1])
+	}
+	if !ed25519.Verify(key, payload, signature) {
+		return Claims{}, ErrInvalidSignature
+	}
+
+	rest := payload[2:]
+	bookingID, n := binary.Uvarint(rest)
+	if n <= 0 {
+		return Claims{}, ErrMalformed
+	}
+	rest = rest[n:]
+	unitID, n := binary.Uvarint(rest)
+	if n <= 0 {
+		return Claims{}, ErrMalformed
+	}
+	rest = rest[n:]
+	date, n := binary.Varint(rest)
+	if n <= 0 || n != len(rest) {
+		return Claims{}, ErrMalformed
+	}
+
+	return Claims{
+		BookingID: int64(bookingID),
+		UnitID:    int64(unitID),
+		LocalDate: time.Unix(date*secondsPerDay, 0).UTC(),
+	}, nil
+}
+
+const secondsPerDay = 24 * 60 * 60
+
+// days returns the number of days since the Unix epoch of the date.
+func days(date time.Time) int64 {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / secondsPerDay
+}
+
+// ParseSigningKey parses a signing key in the form "<key ID>:<base64 Ed25519 seed>".
+func ParseSigningKey(s string) (Signer, error) {
+	keyID, encoded, err := splitKey(s)
+	if err != nil {
+		return Signer{}, err
+	}
+
+	seed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return Signer{}, fmt.Errorf("decode seed: %w", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return Signer{}, fmt.Errorf("seed must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+	}
+
+	return NewSigner(keyID, ed25519.NewKeyFromSeed(seed)), nil
+}
+
+// ParsePublicKeys parses comma separated public keys in the form "<key ID>:<base64 Ed25519 public key>".
+func ParsePublicKeys(s string) (map[byte]ed25519.PublicKey, error) {
+	keys := make(map[byte]ed25519.PublicKey)
+	for part := range strings.SplitSeq(s, ",") {
+		keyID, encoded, err := splitKey(part)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("decode public key %d: %w", keyID, err)
+		}
+		if len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("public key %d must be %d bytes, got %d", keyID, ed25519.PublicKeySize, len(key))
+		}
+		keys[keyID] = key
+	}
+
+	return keys, nil
+}
+
+func splitKey(s string) (byte, string, error) {
+	id, key, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, "", errors.New(`key must be in the form "<key ID>:<base64 key>"`)
+	}
+
+	keyID, err := strconv.ParseUint(id, 10, 8)
+	if err != nil {
+		return 0, "", fmt.Errorf("parse key ID: %w", err)
+	}
+
+	return byte(keyID), key, nil
+}
+
+// FormatKey formats a key as "<key ID>:<base64 key>", the form parsed by ParseSigningKey and ParsePublicKeys.
+func FormatKey(keyID byte, key []byte) string {
+	return fmt.Sprintf("%d:%s", keyID, base64.StdEncoding.EncodeToString(key))
+}
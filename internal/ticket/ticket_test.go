@@ -0,0 +1,183 @@
+package ticket_test
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmksnnk/octo/internal/ticket"
+)
+
+func TestVerify(t *testing.T) {
+	signer := newSigner(1, 1)
+	claims := ticket.Claims{
+		BookingID: 42,
+		UnitID:    1234567,
+		LocalDate: time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		code := signer.Sign(claims)
+
+		got, err := newVerifier(signer).Verify(code)
+		if err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+		if got != claims {
+			t.Errorf("want claims %+v, got %+v", claims, got)
+		}
+	})
+
+	t.Run("date before epoch", func(t *testing.T) {
+		claims := claims
+		claims.LocalDate = time.Date(1969, time.July, 20, 0, 0, 0, 0, time.UTC)
+
+		got, err := newVerifier(signer).Verify(signer.Sign(claims))
+		if err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+		if got != claims {
+			t.Errorf("want claims %+v, got %+v", claims, got)
+		}
+	})
+
+	t.Run("time of day is ignored", func(t *testing.T) {
+		loc, err := time.LoadLocation("America/Los_Angeles")
+		if err != nil {
+			t.Fatalf("load location: %v", err)
+		}
+		local := claims
+		local.LocalDate = time.Date(2026, time.October, 17, 23, 30, 0, 0, loc)
+
+		got, err := newVerifier(signer).Verify(signer.Sign(local))
+		if err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+		if got != claims {
+			t.Errorf("want claims %+v, got %+v", claims, got)
+		}
+	})
+
+	t.Run("lowercase", func(t *testing.T) {
+		got, err := newVerifier(signer).Verify(strings.ToLower(signer.Sign(claims)))
+		if err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+		if got != claims {
+			t.Errorf("want claims %+v, got %+v", claims, got)
+		}
+	})
+
+	t.Run("rotated key", func(t *testing.T) {
+		rotated := newSigner(2, 2)
+		verifier := newVerifier(signer, rotated)
+
+		for _, s := range []ticket.Signer{signer, rotated} {
+			got, err := verifier.Verify(s.Sign(claims))
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if got != claims {
+				t.Errorf("want claims %+v, got %+v", claims, got)
+			}
+		}
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, err := newVerifier(signer).Verify(newSigner(2, 2).Sign(claims))
+		if !errors.Is(err, ticket.ErrUnknownKey) {
+			t.Errorf("want error %v, got %v", ticket.ErrUnknownKey, err)
+		}
+	})
+
+	t.Run("signed by other key with same ID", func(t *testing.T) {
+		_, err := newVerifier(signer).Verify(newSigner(1, 2).Sign(claims))
+		if !errors.Is(err, ticket.ErrInvalidSignature) {
+			t.Errorf("want error %v, got %v", ticket.ErrInvalidSignature, err)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		code := []byte(signer.Sign(claims))
+		// flip a character in the claims
+		if code[4] == 'A' {
+			code[4] = 'B'
+		} else {
+			code[4] = 'A'
+		}
+
+		_, err := newVerifier(signer).Verify(string(code))
+		if !errors.Is(err, ticket.ErrInvalidSignature) {
+			t.Errorf("want error %v, got %v", ticket.ErrInvalidSignature, err)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		tests := map[string]string{
+			"empty":      "",
+			"not base32": "not a ticket!",
+			"truncated":  signer.Sign(claims)[:20],
+			"random":     "ABCDEFGHIJKLM",
+		}
+		for name, code := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := newVerifier(signer).Verify(code)
+				if !errors.Is(err, ticket.ErrMalformed) {
+					t.Errorf("want error %v, got %v", ticket.ErrMalformed, err)
+				}
+			})
+		}
+	})
+}
+
+func TestParseKeys(t *testing.T) {
+	signer := newSigner(3, 1)
+	keyID, publicKey := signer.PublicKey()
+
+	parsedSigner, err := ticket.ParseSigningKey(ticket.FormatKey(keyID, seed(1)))
+	if err != nil {
+		t.Fatalf("parse signing key: %v", err)
+	}
+	keys, err := ticket.ParsePublicKeys(ticket.FormatKey(keyID, publicKey) + "," + ticket.FormatKey(4, publicKey))
+	if err != nil {
+		t.Fatalf("parse public keys: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Errorf("want 2 keys, got %d", len(keys))
+	}
+
+	claims := ticket.Claims{BookingID: 1, UnitID: 2, LocalDate: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	if _, err := ticket.NewVerifier(keys).Verify(parsedSigner.Sign(claims)); err != nil {
+		t.Errorf("verify: %v", err)
+	}
+
+	for _, s := range []string{"", "1", "x:AAAA", "256:AAAA", "1:not base64", "1:AAAA"} {
+		if _, err := ticket.ParseSigningKey(s); err == nil {
+			t.Errorf("want error parsing signing key %q", s)
+		}
+		if _, err := ticket.ParsePublicKeys(s); err == nil {
+			t.Errorf("want error parsing public keys %q", s)
+		}
+	}
+}
+
+func newSigner(keyID, seedByte byte) ticket.Signer {
+	return ticket.NewSigner(keyID, ed25519.NewKeyFromSeed(seed(seedByte)))
+}
+
+func seed(b byte) []byte {
+	s := make([]byte, ed25519.SeedSize)
+	s[0] = b
+	return s
+}
+
+func newVerifier(signers ...ticket.Signer) ticket.Verifier {
+	keys := make(map[byte]ed25519.PublicKey)
+	for _, s := range signers {
+		keyID, publicKey := s.PublicKey()
+		keys[keyID] = publicKey
+	}
+	return ticket.NewVerifier(keys)
+}
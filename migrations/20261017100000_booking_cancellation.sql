@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE booking_status ADD VALUE 'CANCELLED';
+
+ALTER TABLE bookings
+    ADD COLUMN cancellation_reason VARCHAR,
+    ADD COLUMN cancelled_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- cancelled bookings cannot be represented without CANCELLED, so rolling back would lose them,
+-- they must be archived and deleted explicitly before migrating down
+DO $$
+DECLARE
+    offending TEXT;
+BEGIN
+    SELECT string_agg(id::TEXT, ', ' ORDER BY id) INTO offending
+    FROM bookings
+    WHERE status = 'CANCELLED';
+
+    IF offending IS NOT NULL THEN
+        RAISE EXCEPTION 'cancelled bookings cannot be rolled back: %', offending
+            USING HINT = 'archive and delete them, then migrate down again';
+    END IF;
+END;
+$$;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS cancellation_reason,
+    DROP COLUMN IF EXISTS cancelled_at;
+
+-- enum values cannot be dropped, so recreate the type without CANCELLED
+ALTER TYPE booking_status RENAME TO booking_status_old;
+CREATE TYPE booking_status AS ENUM (
+    'RESERVED',
+    'CONFIRMED'
+);
+ALTER TABLE bookings
+    ALTER COLUMN status TYPE booking_status USING status::TEXT::booking_status;
+DROP TYPE booking_status_old;
+-- +goose StatementEnd
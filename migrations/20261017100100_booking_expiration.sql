@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE booking_status ADD VALUE 'EXPIRED';
+
+ALTER TABLE bookings
+    ADD COLUMN expires_at TIMESTAMPTZ; -- when the reservation expires unless confirmed
+
+CREATE INDEX idx_reserved_bookings_by_expires_at ON bookings (expires_at)
+    WHERE status = 'RESERVED' AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- expired bookings cannot be represented without EXPIRED, so rolling back would lose them,
+-- they must be archived and deleted explicitly before migrating down
+DO $$
+DECLARE
+    offending TEXT;
+BEGIN
+    SELECT string_agg(id::TEXT, ', ' ORDER BY id) INTO offending
+    FROM bookings
+    WHERE status = 'EXPIRED';
+
+    IF offending IS NOT NULL THEN
+        RAISE EXCEPTION 'expired bookings cannot be rolled back: %', offending
+            USING HINT = 'archive and delete them, then migrate down again';
+    END IF;
+END;
+$$;
+
+DROP INDEX IF EXISTS idx_reserved_bookings_by_expires_at;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS expires_at;
+
+-- enum values cannot be dropped, so recreate the type without EXPIRED
+ALTER TYPE booking_status RENAME TO booking_status_old;
+CREATE TYPE booking_status AS ENUM (
+    'RESERVED',
+    'CONFIRMED',
+    'CANCELLED'
+);
+ALTER TABLE bookings
+    ALTER COLUMN status TYPE booking_status USING status::TEXT::booking_status;
+DROP TYPE booking_status_old;
+-- +goose StatementEnd
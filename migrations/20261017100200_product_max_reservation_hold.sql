@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+    ADD COLUMN max_reservation_hold_minutes INTEGER NOT NULL DEFAULT 60 -- how long a reservation can be held since it was created
+        CONSTRAINT positive_max_reservation_hold_minutes CHECK ( max_reservation_hold_minutes > 0 );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE products
+    DROP COLUMN IF EXISTS max_reservation_hold_minutes;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE idempotency_keys (
+    user_id INTEGER NOT NULL REFERENCES users(id), -- keys are scoped to the user
+    key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    request_hash BYTEA NOT NULL,
+    -- response is NULL while the first request is being processed
+    response_status INTEGER,
+    response_content_type VARCHAR,
+    response_body BYTEA,
+
+    PRIMARY KEY (user_id, key)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS idempotency_keys;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE bookings
+    ADD COLUMN uuid UUID NOT NULL DEFAULT gen_random_uuid(), -- supplied by the reseller or generated
+    ADD COLUMN reseller_reference VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_bookings_by_user_uuid ON bookings (user_id, uuid);
+
+CREATE UNIQUE INDEX idx_bookings_by_user_reseller_reference ON bookings (user_id, reseller_reference)
+    WHERE reseller_reference IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_bookings_by_user_reseller_reference;
+DROP INDEX IF EXISTS idx_bookings_by_user_uuid;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS uuid,
+    DROP COLUMN IF EXISTS reseller_reference;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE contacts (
+    booking_id BIGINT PRIMARY KEY REFERENCES bookings(id), -- lead traveller of the booking
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+
+    full_name VARCHAR(255),
+    email_address VARCHAR(255),
+    phone_number VARCHAR(32),
+    locales VARCHAR[] NOT NULL DEFAULT '{}',
+    country CHAR(2), -- ISO 3166-1 alpha-2
+    notes TEXT
+);
+
+ALTER TABLE products
+    ADD COLUMN contact_required_to_confirm BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE products
+    DROP COLUMN IF EXISTS contact_required_to_confirm;
+
+DROP TABLE IF EXISTS contacts;
+-- +goose StatementEnd
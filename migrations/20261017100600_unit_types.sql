@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE unit_types (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ,
+
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    code VARCHAR NOT NULL, -- e.g. "adult", referenced as unitId in the API
+    name VARCHAR NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_active_unit_types_by_product_code ON unit_types (product_id, code)
+    WHERE deleted_at IS NULL;
+
+-- prices without unit type are the product's base price, used for units without a type-specific price
+ALTER TABLE prices
+    ADD COLUMN unit_type_id INTEGER REFERENCES unit_types(id);
+
+-- units without type are priced with the base price
+ALTER TABLE units
+    ADD COLUMN unit_type_id INTEGER REFERENCES unit_types(id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE units
+    DROP COLUMN IF EXISTS unit_type_id;
+
+DELETE FROM prices
+WHERE unit_type_id IS NOT NULL;
+
+ALTER TABLE prices
+    DROP COLUMN IF EXISTS unit_type_id;
+
+DROP INDEX IF EXISTS idx_active_unit_types_by_product_code;
+DROP TABLE IF EXISTS unit_types;
+-- +goose StatementEnd
@@ -0,0 +1,76 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE options (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ,
+
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    code VARCHAR NOT NULL, -- e.g. "DEFAULT", referenced as optionId in the API
+    name VARCHAR NOT NULL,
+    capacity INTEGER NOT NULL
+        CONSTRAINT non_negative_capacity CHECK ( capacity >= 0 )
+);
+
+CREATE UNIQUE INDEX idx_active_options_by_product_code ON options (product_id, code)
+    WHERE deleted_at IS NULL;
+
+-- existing products have a single variant
+INSERT INTO options (product_id, code, name, capacity, deleted_at)
+SELECT id, 'DEFAULT', name, capacity, deleted_at
+FROM products;
+
+ALTER TABLE availabilities
+    ADD COLUMN option_id INTEGER REFERENCES options(id);
+
+UPDATE availabilities
+SET option_id = options.id
+FROM options
+WHERE options.product_id = availabilities.product_id
+AND options.code = 'DEFAULT';
+
+ALTER TABLE availabilities
+    ALTER COLUMN option_id SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_active_availabilities_by_product_local_date;
+CREATE INDEX idx_active_availabilities_by_product_option_local_date ON availabilities (product_id, option_id, local_date, deleted_at)
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE bookings
+    ADD COLUMN option_id INTEGER REFERENCES options(id);
+
+UPDATE bookings
+SET option_id = availabilities.option_id
+FROM availabilities
+WHERE availabilities.id = bookings.availability_id;
+
+ALTER TABLE bookings
+    ALTER COLUMN option_id SET NOT NULL;
+
+-- prices without option apply to all options of the product
+ALTER TABLE prices
+    ADD COLUMN option_id INTEGER REFERENCES options(id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM prices
+WHERE option_id IS NOT NULL;
+
+ALTER TABLE prices
+    DROP COLUMN IF EXISTS option_id;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS option_id;
+
+DROP INDEX IF EXISTS idx_active_availabilities_by_product_option_local_date;
+CREATE INDEX idx_active_availabilities_by_product_local_date ON availabilities (product_id, local_date, deleted_at)
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE availabilities
+    DROP COLUMN IF EXISTS option_id;
+
+DROP INDEX IF EXISTS idx_active_options_by_product_code;
+DROP TABLE IF EXISTS options;
+-- +goose StatementEnd
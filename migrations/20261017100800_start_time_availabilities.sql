@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE availability_type AS ENUM (
+    'START_TIME'
+);
+
+ALTER TABLE products
+    ADD COLUMN availability_type availability_type NOT NULL DEFAULT 'START_TIME',
+    ADD COLUMN timezone VARCHAR NOT NULL DEFAULT 'UTC'; -- IANA time zone of local date-times, e.g. "Europe/Berlin"
+
+-- local date-times are wall clock times in the product's time zone
+ALTER TABLE availabilities
+    ADD COLUMN local_date_time_start TIMESTAMP,
+    ADD COLUMN local_date_time_end TIMESTAMP,
+    ADD COLUMN all_day BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- existing availabilities last the whole day
+UPDATE availabilities
+SET local_date_time_start = local_date::TIMESTAMP,
+    local_date_time_end = (local_date + 1)::TIMESTAMP,
+    all_day = TRUE;
+
+ALTER TABLE availabilities
+    ALTER COLUMN local_date_time_start SET NOT NULL,
+    ALTER COLUMN local_date_time_end SET NOT NULL,
+    ADD CONSTRAINT local_date_of_start CHECK ( local_date = local_date_time_start::DATE ),
+    ADD CONSTRAINT end_after_start CHECK ( local_date_time_end > local_date_time_start );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE availabilities
+    DROP CONSTRAINT IF EXISTS end_after_start,
+    DROP CONSTRAINT IF EXISTS local_date_of_start,
+    DROP COLUMN IF EXISTS all_day,
+    DROP COLUMN IF EXISTS local_date_time_end,
+    DROP COLUMN IF EXISTS local_date_time_start;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS timezone,
+    DROP COLUMN IF EXISTS availability_type;
+
+DROP TYPE IF EXISTS availability_type;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE availability_type ADD VALUE 'OPENING_HOURS';
+
+-- opening hours of availabilities of OPENING_HOURS products, local times in the product's time zone
+CREATE TABLE opening_hours (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ,
+
+    availability_id INTEGER NOT NULL REFERENCES availabilities(id),
+    time_from TIME NOT NULL,
+    time_to TIME NOT NULL,
+    CONSTRAINT to_after_from CHECK ( time_to > time_from )
+);
+
+CREATE INDEX idx_active_opening_hours_by_availability ON opening_hours (availability_id, deleted_at)
+    WHERE deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_active_opening_hours_by_availability;
+DROP TABLE IF EXISTS opening_hours;
+
+-- enum values cannot be dropped, recreate the type without OPENING_HOURS
+UPDATE products
+SET availability_type = 'START_TIME'
+WHERE availability_type = 'OPENING_HOURS';
+
+ALTER TYPE availability_type RENAME TO availability_type_old;
+CREATE TYPE availability_type AS ENUM (
+    'START_TIME'
+);
+
+ALTER TABLE products
+    ALTER COLUMN availability_type DROP DEFAULT,
+    ALTER COLUMN availability_type TYPE availability_type USING availability_type::TEXT::availability_type,
+    ALTER COLUMN availability_type SET DEFAULT 'START_TIME';
+
+DROP TYPE IF EXISTS availability_type_old;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+    ADD COLUMN limited_threshold INTEGER NOT NULL DEFAULT 0 -- availabilities with fewer vacancies are LIMITED, zero disables
+        CONSTRAINT non_negative_limited_threshold CHECK ( limited_threshold >= 0 );
+
+ALTER TABLE availabilities
+    ADD COLUMN closed BOOLEAN NOT NULL DEFAULT FALSE, -- shut by the operator, existing bookings are kept
+    ADD COLUMN freesale BOOLEAN NOT NULL DEFAULT FALSE; -- unlimited inventory, vacancies are not checked nor changed
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE availabilities
+    DROP COLUMN IF EXISTS freesale,
+    DROP COLUMN IF EXISTS closed;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS limited_threshold;
+-- +goose StatementEnd
@@ -0,0 +1,68 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE availabilities
+    ADD COLUMN capacity INTEGER; -- vacancies when nothing is sold
+
+UPDATE availabilities
+SET capacity = LEAST(options.capacity, products.capacity)
+FROM options
+JOIN products ON products.id = options.product_id
+WHERE options.id = availabilities.option_id;
+
+-- vacancies above the product's capacity were never meant to be sold,
+-- but they are inventory, so they must be fixed explicitly before migrating
+DO $$
+DECLARE
+    offending TEXT;
+BEGIN
+    SELECT string_agg(format('%s (vacancies %s, capacity %s)', id, vacancies, capacity), ', ' ORDER BY id) INTO offending
+    FROM availabilities
+    WHERE vacancies > capacity;
+
+    IF offending IS NOT NULL THEN
+        RAISE EXCEPTION 'availabilities have more vacancies than capacity: %', offending
+            USING HINT = 'lower their vacancies or raise the capacity of their option and product, then migrate again';
+    END IF;
+END;
+$$;
+
+ALTER TABLE availabilities
+    ALTER COLUMN capacity SET NOT NULL,
+    ADD CONSTRAINT vacancies_within_capacity CHECK ( vacancies <= capacity );
+
+-- capacity of an availability defaults to and cannot exceed the capacity of its option and product
+CREATE FUNCTION check_availability_capacity() RETURNS TRIGGER AS $$
+DECLARE
+    max_capacity INTEGER;
+BEGIN
+    SELECT LEAST(options.capacity, products.capacity) INTO max_capacity
+    FROM options
+    JOIN products ON products.id = options.product_id
+    WHERE options.id = NEW.option_id;
+
+    IF NEW.capacity IS NULL THEN
+        NEW.capacity := max_capacity;
+    END IF;
+    IF NEW.capacity > max_capacity THEN
+        RAISE EXCEPTION 'availability capacity % exceeds product capacity %', NEW.capacity, max_capacity
+            USING ERRCODE = 'check_violation';
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER availability_capacity
+    BEFORE INSERT OR UPDATE OF capacity, option_id ON availabilities
+    FOR EACH ROW EXECUTE FUNCTION check_availability_capacity();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS availability_capacity ON availabilities;
+DROP FUNCTION IF EXISTS check_availability_capacity;
+
+ALTER TABLE availabilities
+    DROP CONSTRAINT IF EXISTS vacancies_within_capacity,
+    DROP COLUMN IF EXISTS capacity;
+-- +goose StatementEnd
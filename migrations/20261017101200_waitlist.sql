@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE waitlist_status AS ENUM (
+    'WAITING',
+    'RESERVED'
+);
+
+CREATE TABLE waitlist_entries (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ,
+
+    user_id INTEGER NOT NULL REFERENCES users(id), -- who is waiting
+    availability_id INTEGER NOT NULL REFERENCES availabilities(id), -- which availability is waited for
+    option_id INTEGER NOT NULL REFERENCES options(id), -- option of the availability, the reservation is made for
+    currency VARCHAR NOT NULL, -- the reservation is priced in
+    units INTEGER NOT NULL
+        CONSTRAINT positive_units CHECK ( units > 0 ),
+    unit_type_ids INTEGER[] NOT NULL, -- unit type of each unit, zero is a unit without type
+    status waitlist_status NOT NULL DEFAULT 'WAITING',
+    booking_id BIGINT REFERENCES bookings(id), -- reservation made once enough vacancies returned
+    webhook_url VARCHAR, -- notified when the entry is reserved
+    notified_at TIMESTAMPTZ,
+
+    CONSTRAINT unit_type_of_each_unit CHECK ( cardinality(unit_type_ids) = units )
+);
+
+-- waiting entries are served oldest first
+CREATE INDEX idx_waiting_entries_by_availability ON waitlist_entries (availability_id, created_at)
+    WHERE status = 'WAITING' AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_waiting_entries_by_availability;
+DROP TABLE IF EXISTS waitlist_entries;
+DROP TYPE IF EXISTS waitlist_status;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE units
+    ADD COLUMN redeemed_at TIMESTAMPTZ, -- when the ticket was scanned at the gate
+    ADD COLUMN redeemed_by INTEGER REFERENCES users(id); -- who scanned the ticket
+
+CREATE INDEX idx_units_by_ticket ON units (ticket)
+    WHERE ticket IS NOT NULL AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_units_by_ticket;
+
+ALTER TABLE units
+    DROP COLUMN IF EXISTS redeemed_by,
+    DROP COLUMN IF EXISTS redeemed_at;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE delivery_format AS ENUM (
+    'QRCODE',
+    'CODE128'
+);
+
+ALTER TABLE products
+    ADD COLUMN delivery_format delivery_format NOT NULL DEFAULT 'QRCODE'; -- how tickets are rendered for scanning
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE products
+    DROP COLUMN IF EXISTS delivery_format;
+
+DROP TYPE IF EXISTS delivery_format;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_units_by_ticket;
+
+-- tickets are signed codes scanned offline, a code must identify a single unit
+CREATE UNIQUE INDEX idx_units_by_ticket ON units (ticket)
+    WHERE ticket IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_units_by_ticket;
+
+CREATE INDEX idx_units_by_ticket ON units (ticket)
+    WHERE ticket IS NOT NULL AND deleted_at IS NULL;
+-- +goose StatementEnd
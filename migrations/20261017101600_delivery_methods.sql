@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE delivery_method AS ENUM (
+    'TICKET', -- a ticket per unit
+    'VOUCHER' -- a voucher per booking, admitting all of its units
+);
+
+ALTER TABLE products
+    ADD COLUMN delivery_methods delivery_method[] NOT NULL DEFAULT '{TICKET}'
+        CONSTRAINT delivery_methods_not_empty CHECK ( cardinality(delivery_methods) > 0 );
+
+ALTER TABLE bookings
+    ADD COLUMN voucher VARCHAR; -- generated when the booking is CONFIRMED if the product is delivered with vouchers
+
+CREATE UNIQUE INDEX idx_bookings_by_voucher ON bookings (voucher)
+    WHERE voucher IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_bookings_by_voucher;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS voucher;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS delivery_methods;
+
+DROP TYPE IF EXISTS delivery_method;
+-- +goose StatementEnd
@@ -0,0 +1,133 @@
+-- +goose Up
+-- +goose StatementBegin
+-- products are priced in every currency they have a base price in, the default is used when no currency is requested
+ALTER TABLE products
+    ADD COLUMN default_currency CHAR(3);
+
+UPDATE products
+SET default_currency = COALESCE((
+    SELECT prices.currency
+    FROM prices
+    WHERE prices.product_id = products.id
+    AND prices.unit_type_id IS NULL
+    AND prices.option_id IS NULL
+    AND prices.deleted_at IS NULL
+    ORDER BY prices.id
+    LIMIT 1
+), 'EUR');
+
+ALTER TABLE products
+    ALTER COLUMN default_currency SET NOT NULL;
+
+-- bookings are priced in the currency they were created in
+ALTER TABLE bookings
+    ADD COLUMN currency CHAR(3);
+
+UPDATE bookings
+SET currency = products.default_currency
+FROM products
+WHERE products.id = bookings.product_id;
+
+ALTER TABLE bookings
+    ALTER COLUMN currency SET NOT NULL;
+
+CREATE INDEX idx_active_prices_by_product_currency ON prices (product_id, currency)
+    WHERE deleted_at IS NULL;
+
+-- a product has a single base price per currency, otherwise it is priced by an arbitrary one,
+-- duplicates are prices, so they must be fixed explicitly before migrating
+DO $$
+DECLARE
+    offending TEXT;
+BEGIN
+    SELECT string_agg(format('%s (%s)', product_id, currency), ', ' ORDER BY product_id, currency) INTO offending
+    FROM (
+        SELECT product_id, currency
+        FROM prices
+        WHERE unit_type_id IS NULL
+        AND option_id IS NULL
+        AND deleted_at IS NULL
+        GROUP BY product_id, currency
+        HAVING count(*) > 1
+    ) AS duplicates;
+
+    IF offending IS NOT NULL THEN
+        RAISE EXCEPTION 'products have more than one base price in a currency: %', offending
+            USING HINT = 'delete all but one base price of each product and currency, then migrate again';
+    END IF;
+END;
+$$;
+
+CREATE UNIQUE INDEX idx_base_prices_by_product_currency ON prices (product_id, currency)
+    WHERE unit_type_id IS NULL AND option_id IS NULL AND deleted_at IS NULL;
+
+-- a product with base prices must have one in its default currency, checked on commit,
+-- so the default currency and its price can be changed in one transaction
+CREATE FUNCTION check_default_currency_priced() RETURNS TRIGGER AS $$
+DECLARE
+    checked_product_id INTEGER;
+BEGIN
+    IF TG_TABLE_NAME = 'products' THEN
+        checked_product_id := NEW.id;
+    ELSIF TG_OP = 'INSERT' THEN
+        checked_product_id := NEW.product_id;
+    ELSE
+        checked_product_id := OLD.product_id;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1
+        FROM products
+        WHERE products.id = checked_product_id
+        AND EXISTS (
+            SELECT 1
+            FROM prices
+            WHERE prices.product_id = products.id
+            AND prices.unit_type_id IS NULL
+            AND prices.option_id IS NULL
+            AND prices.deleted_at IS NULL
+        )
+        AND NOT EXISTS (
+            SELECT 1
+            FROM prices
+            WHERE prices.product_id = products.id
+            AND prices.currency = products.default_currency
+            AND prices.unit_type_id IS NULL
+            AND prices.option_id IS NULL
+            AND prices.deleted_at IS NULL
+        )
+    ) THEN
+        RAISE EXCEPTION 'product % has no base price in its default currency', checked_product_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER default_currency_priced
+    AFTER INSERT OR UPDATE OF default_currency ON products
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_default_currency_priced();
+
+CREATE CONSTRAINT TRIGGER default_currency_priced
+    AFTER INSERT OR UPDATE OR DELETE ON prices
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_default_currency_priced();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS default_currency_priced ON prices;
+DROP TRIGGER IF EXISTS default_currency_priced ON products;
+DROP FUNCTION IF EXISTS check_default_currency_priced;
+
+DROP INDEX IF EXISTS idx_base_prices_by_product_currency;
+DROP INDEX IF EXISTS idx_active_prices_by_product_currency;
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS default_currency;
+-- +goose StatementEnd
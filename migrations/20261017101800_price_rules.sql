@@ -0,0 +1,133 @@
+-- +goose Up
+-- +goose StatementBegin
+-- price rules are prices which apply only to some availabilities, e.g. peak season or weekends,
+-- conditions are combined, NULL conditions match all availabilities
+ALTER TABLE prices
+    ADD COLUMN availability_id INTEGER REFERENCES availabilities(id),
+    ADD COLUMN local_date_start DATE, -- inclusive
+    ADD COLUMN local_date_end DATE, -- inclusive
+    ADD COLUMN weekdays INTEGER[], -- ISO weekdays, 1 is Monday, 7 is Sunday
+    -- the matching price with the highest priority wins, base prices have priority 0 and apply when no rule matches,
+    -- of the same priority, unit type prices win over product and option prices
+    ADD COLUMN priority INTEGER NOT NULL DEFAULT 0
+        CONSTRAINT non_negative_priority CHECK ( priority >= 0 ),
+    ADD CONSTRAINT valid_local_date_range CHECK ( local_date_start <= local_date_end ),
+    ADD CONSTRAINT valid_weekdays CHECK ( weekdays <@ ARRAY[1, 2, 3, 4, 5, 6, 7] AND cardinality(weekdays) > 0 ),
+    ADD CONSTRAINT rules_have_priority CHECK (
+        (priority = 0) = (availability_id IS NULL AND local_date_start IS NULL AND local_date_end IS NULL AND weekdays IS NULL)
+    );
+
+-- rules are not base prices, a product may have many of them in a currency
+DROP INDEX idx_base_prices_by_product_currency;
+CREATE UNIQUE INDEX idx_base_prices_by_product_currency ON prices (product_id, currency)
+    WHERE unit_type_id IS NULL AND option_id IS NULL AND priority = 0 AND deleted_at IS NULL;
+
+CREATE OR REPLACE FUNCTION check_default_currency_priced() RETURNS TRIGGER AS $$
+DECLARE
+    checked_product_id INTEGER;
+BEGIN
+    IF TG_TABLE_NAME = 'products' THEN
+        checked_product_id := NEW.id;
+    ELSIF TG_OP = 'INSERT' THEN
+        checked_product_id := NEW.product_id;
+    ELSE
+        checked_product_id := OLD.product_id;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1
+        FROM products
+        WHERE products.id = checked_product_id
+        AND EXISTS (
+            SELECT 1
+            FROM prices
+            WHERE prices.product_id = products.id
+            AND prices.unit_type_id IS NULL
+            AND prices.option_id IS NULL
+            AND prices.priority = 0
+            AND prices.deleted_at IS NULL
+        )
+        AND NOT EXISTS (
+            SELECT 1
+            FROM prices
+            WHERE prices.product_id = products.id
+            AND prices.currency = products.default_currency
+            AND prices.unit_type_id IS NULL
+            AND prices.option_id IS NULL
+            AND prices.priority = 0
+            AND prices.deleted_at IS NULL
+        )
+    ) THEN
+        RAISE EXCEPTION 'product % has no base price in its default currency', checked_product_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- checked right away, prices cannot be altered with pending trigger events
+SET CONSTRAINTS default_currency_priced IMMEDIATE;
+
+DELETE FROM prices
+WHERE priority > 0;
+
+DROP INDEX IF EXISTS idx_base_prices_by_product_currency;
+CREATE UNIQUE INDEX idx_base_prices_by_product_currency ON prices (product_id, currency)
+    WHERE unit_type_id IS NULL AND option_id IS NULL AND deleted_at IS NULL;
+
+CREATE OR REPLACE FUNCTION check_default_currency_priced() RETURNS TRIGGER AS $$
+DECLARE
+    checked_product_id INTEGER;
+BEGIN
+    IF TG_TABLE_NAME = 'products' THEN
+        checked_product_id := NEW.id;
+    ELSIF TG_OP = 'INSERT' THEN
+        checked_product_id := NEW.product_id;
+    ELSE
+        checked_product_id := OLD.product_id;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1
+        FROM products
+        WHERE products.id = checked_product_id
+        AND EXISTS (
+            SELECT 1
+            FROM prices
+            WHERE prices.product_id = products.id
+            AND prices.unit_type_id IS NULL
+            AND prices.option_id IS NULL
+            AND prices.deleted_at IS NULL
+        )
+        AND NOT EXISTS (
+            SELECT 1
+            FROM prices
+            WHERE prices.product_id = products.id
+            AND prices.currency = products.default_currency
+            AND prices.unit_type_id IS NULL
+            AND prices.option_id IS NULL
+            AND prices.deleted_at IS NULL
+        )
+    ) THEN
+        RAISE EXCEPTION 'product % has no base price in its default currency', checked_product_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE prices
+    DROP CONSTRAINT IF EXISTS rules_have_priority,
+    DROP CONSTRAINT IF EXISTS valid_weekdays,
+    DROP CONSTRAINT IF EXISTS valid_local_date_range,
+    DROP COLUMN IF EXISTS priority,
+    DROP COLUMN IF EXISTS weekdays,
+    DROP COLUMN IF EXISTS local_date_end,
+    DROP COLUMN IF EXISTS local_date_start,
+    DROP COLUMN IF EXISTS availability_id;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- units keep the price they were reserved for, in the currency of the booking,
+-- so later price changes do not change the totals of existing bookings
+ALTER TABLE units
+    ADD COLUMN price INTEGER;
+
+-- existing units get the current price of the booked availability
+UPDATE units
+SET price = COALESCE((
+    SELECT prices.price
+    FROM bookings
+    JOIN availabilities ON availabilities.id = bookings.availability_id
+    JOIN prices ON prices.product_id = bookings.product_id
+        AND (prices.unit_type_id = units.unit_type_id OR prices.unit_type_id IS NULL)
+        AND (prices.option_id = bookings.option_id OR prices.option_id IS NULL)
+        AND (prices.availability_id IS NULL OR prices.availability_id = availabilities.id)
+        AND (prices.local_date_start IS NULL OR prices.local_date_start <= availabilities.local_date)
+        AND (prices.local_date_end IS NULL OR prices.local_date_end >= availabilities.local_date)
+        AND (prices.weekdays IS NULL OR EXTRACT(ISODOW FROM availabilities.local_date)::INTEGER = ANY(prices.weekdays))
+        AND prices.currency = bookings.currency
+    WHERE bookings.id = units.booking_id
+    AND prices.deleted_at IS NULL
+    ORDER BY prices.priority DESC, prices.unit_type_id NULLS LAST, prices.option_id NULLS LAST
+    LIMIT 1
+), 0);
+
+ALTER TABLE units
+    ALTER COLUMN price SET NOT NULL,
+    ADD CONSTRAINT non_negative_price CHECK ( price >= 0 );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE units
+    DROP COLUMN IF EXISTS price;
+-- +goose StatementEnd
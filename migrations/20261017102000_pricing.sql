@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- price is the retail price paid by the customer, original is the price before discounts,
+-- net is the price paid by the reseller, taxes are included in the prices:
+-- [{"name": "VAT 19%", "retail": 160, "net": 128}]
+ALTER TABLE prices
+    ADD COLUMN original INTEGER -- NULL is the retail price
+        CONSTRAINT non_negative_original CHECK ( original >= 0 ),
+    ADD COLUMN net INTEGER -- NULL is unknown
+        CONSTRAINT non_negative_net CHECK ( net >= 0 ),
+    ADD COLUMN included_taxes JSONB NOT NULL DEFAULT '[]'
+        CONSTRAINT included_taxes_array CHECK ( jsonb_typeof(included_taxes) = 'array' );
+
+-- units keep the whole breakdown they were reserved for
+ALTER TABLE units
+    ADD COLUMN original INTEGER,
+    ADD COLUMN net INTEGER,
+    ADD COLUMN included_taxes JSONB NOT NULL DEFAULT '[]';
+
+UPDATE units
+SET original = price;
+
+ALTER TABLE units
+    ALTER COLUMN original SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE units
+    DROP COLUMN IF EXISTS included_taxes,
+    DROP COLUMN IF EXISTS net,
+    DROP COLUMN IF EXISTS original;
+
+ALTER TABLE prices
+    DROP COLUMN IF EXISTS included_taxes,
+    DROP COLUMN IF EXISTS net,
+    DROP COLUMN IF EXISTS original;
+-- +goose StatementEnd
@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE discount_type AS ENUM (
+    'PERCENTAGE', -- percent of the booking total
+    'FIXED' -- amount in the currency, up to the booking total
+);
+
+CREATE TABLE promo_codes (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMPTZ,
+
+    code VARCHAR NOT NULL, -- case insensitive
+    discount_type discount_type NOT NULL,
+    amount INTEGER NOT NULL -- percent or minor units of the currency
+        CONSTRAINT positive_amount CHECK ( amount > 0 ),
+    currency VARCHAR, -- of FIXED discounts
+    valid_from TIMESTAMPTZ, -- NULL is valid from creation
+    valid_until TIMESTAMPTZ, -- exclusive, NULL never expires
+    max_uses INTEGER -- NULL is unlimited
+        CONSTRAINT positive_max_uses CHECK ( max_uses > 0 ),
+    uses INTEGER NOT NULL DEFAULT 0,
+    product_ids INTEGER[], -- NULL is valid for all products
+
+    CONSTRAINT valid_percentage CHECK ( discount_type <> 'PERCENTAGE' OR amount <= 100 ),
+    CONSTRAINT fixed_has_currency CHECK ( (discount_type = 'FIXED') = (currency IS NOT NULL) ),
+    CONSTRAINT valid_period CHECK ( valid_from < valid_until ),
+    CONSTRAINT uses_within_max CHECK ( uses <= max_uses )
+);
+
+CREATE UNIQUE INDEX idx_promo_codes_by_code ON promo_codes (upper(code))
+    WHERE deleted_at IS NULL;
+
+-- bookings keep the discount of the promo code they were reserved with,
+-- so changing the promo code does not affect existing bookings
+ALTER TABLE bookings
+    ADD COLUMN promo_code_id INTEGER REFERENCES promo_codes(id),
+    ADD COLUMN discount_type discount_type,
+    ADD COLUMN discount_amount INTEGER,
+    ADD CONSTRAINT discount_has_promo_code CHECK (
+        (promo_code_id IS NULL) = (discount_type IS NULL)
+        AND (discount_type IS NULL) = (discount_amount IS NULL)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS discount_amount,
+    DROP COLUMN IF EXISTS discount_type,
+    DROP COLUMN IF EXISTS promo_code_id;
+
+DROP INDEX IF EXISTS idx_promo_codes_by_code;
+DROP TABLE IF EXISTS promo_codes;
+DROP TYPE IF EXISTS discount_type;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE units
+    ADD COLUMN ticket_reference VARCHAR; -- short code of the ticket for linear barcodes, which cannot fit the signed code
+
+-- a reference is redeemed like the ticket, it must identify a single unit
+CREATE UNIQUE INDEX idx_units_by_ticket_reference ON units (ticket_reference)
+    WHERE ticket_reference IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_units_by_ticket_reference;
+
+ALTER TABLE units
+    DROP COLUMN IF EXISTS ticket_reference;
+-- +goose StatementEnd
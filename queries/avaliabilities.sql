@@ -1,34 +1,191 @@
--- name: Avalability :one
-SELECT * FROM availabilities
-WHERE product_id = @product_id 
-AND local_date = @local_date
-AND deleted_at IS NULL;
-
-
--- name: AvalabilityWithPrice :one
-SELECT sqlc.embed(availabilities), sqlc.embed(prices)
+-- name: AvalabilityRange :many
+-- slots starting on the local dates, local date-times are in the product's time zone
+SELECT sqlc.embed(availabilities), products.timezone, products.limited_threshold
 FROM availabilities
-JOIN prices ON availabilities.product_id = prices.product_id
-WHERE availabilities.product_id = @product_id
-AND availabilities.local_date = @local_date
+JOIN products ON products.id = availabilities.product_id
+WHERE availabilities.product_id = @product_id 
+AND availabilities.option_id = (
+    SELECT id FROM options
+    WHERE options.product_id = @product_id
+    AND code = sqlc.arg('option_code')::VARCHAR
+    AND options.deleted_at IS NULL
+)
+AND availabilities.local_date >= @local_date_start
+AND availabilities.local_date <= @local_date_end
 AND availabilities.deleted_at IS NULL
-AND prices.deleted_at IS NULL;
-
-
--- name: AvalabilityRange :many
-SELECT * FROM availabilities
-WHERE product_id = @product_id 
-AND local_date >= @local_date_start
-AND local_date <= @local_date_end
-AND deleted_at IS NULL;
+ORDER BY availabilities.local_date_time_start, availabilities.id;
 
 
 -- name: AvalabilityWithPriceRange :many
-SELECT sqlc.embed(availabilities), sqlc.embed(prices)
+-- availabilities with their effective price in the currency: the matching price rule with the highest priority,
+-- then the option's price, falling back to the product's base price, the same precedence as in UnitPriceIDs
+SELECT DISTINCT ON (availabilities.local_date_time_start, availabilities.id) sqlc.embed(availabilities), products.timezone, products.limited_threshold, sqlc.embed(prices)
 FROM availabilities
+JOIN products ON products.id = availabilities.product_id
+JOIN options ON options.id = availabilities.option_id
 JOIN prices ON availabilities.product_id = prices.product_id
+    AND (prices.option_id = availabilities.option_id OR prices.option_id IS NULL)
+    AND (prices.availability_id IS NULL OR prices.availability_id = availabilities.id)
+    AND (prices.local_date_start IS NULL OR prices.local_date_start <= availabilities.local_date)
+    AND (prices.local_date_end IS NULL OR prices.local_date_end >= availabilities.local_date)
+    AND (prices.weekdays IS NULL OR EXTRACT(ISODOW FROM availabilities.local_date)::INTEGER = ANY(prices.weekdays))
 WHERE availabilities.product_id = @product_id 
+AND options.code = sqlc.arg('option_code')::VARCHAR
+AND options.deleted_at IS NULL
 AND availabilities.local_date >= @local_date_start
 AND availabilities.local_date <= @local_date_end
 AND availabilities.deleted_at IS NULL
-AND prices.deleted_at IS NULL;
\ No newline at end of file
+AND prices.deleted_at IS NULL
+AND prices.unit_type_id IS NULL -- base price
+AND prices.currency = sqlc.arg('currency')::VARCHAR
+ORDER BY availabilities.local_date_time_start, availabilities.id, prices.priority DESC, prices.option_id NULLS LAST;
+
+
+-- name: UnitPriceIDs :many
+-- effective prices of the unit types on the availability in the currency, zero is a unit without type,
+-- units are priced like the availability in AvalabilityWithPriceRange: the matching price with the highest priority wins,
+-- of the same priority, the unit type's price wins over the option's and the product's price
+SELECT DISTINCT ON (unit_type.id) unit_type.id::INTEGER AS unit_type_id, prices.id AS price_id
+FROM availabilities
+CROSS JOIN unnest(sqlc.arg('unit_type_ids')::INTEGER[]) AS unit_type(id)
+JOIN prices ON prices.product_id = availabilities.product_id
+    AND (prices.unit_type_id = unit_type.id OR prices.unit_type_id IS NULL)
+    AND (prices.option_id = availabilities.option_id OR prices.option_id IS NULL)
+    AND (prices.availability_id IS NULL OR prices.availability_id = availabilities.id)
+    AND (prices.local_date_start IS NULL OR prices.local_date_start <= availabilities.local_date)
+    AND (prices.local_date_end IS NULL OR prices.local_date_end >= availabilities.local_date)
+    AND (prices.weekdays IS NULL OR EXTRACT(ISODOW FROM availabilities.local_date)::INTEGER = ANY(prices.weekdays))
+WHERE availabilities.id = @availability_id
+AND availabilities.product_id = @product_id
+AND availabilities.deleted_at IS NULL
+AND prices.deleted_at IS NULL
+AND prices.currency = sqlc.arg('currency')::VARCHAR
+ORDER BY unit_type.id, prices.priority DESC, prices.unit_type_id NULLS LAST, prices.option_id NULLS LAST;
+
+
+-- name: AvailabilityCalendar :many
+-- status and vacancies per local date across the product's open options and slots,
+-- dates without open availabilities are closed
+SELECT
+    days.local_date::DATE AS local_date,
+    (CASE
+        WHEN COUNT(availabilities.id) = 0 THEN 'CLOSED'
+        WHEN bool_or(availabilities.freesale) THEN 'FREESALE'
+        WHEN SUM(availabilities.vacancies) = 0 THEN 'SOLD_OUT'
+        WHEN SUM(availabilities.vacancies) < products.limited_threshold THEN 'LIMITED'
+        ELSE 'AVAILABLE'
+    END)::VARCHAR AS status,
+    COALESCE(SUM(availabilities.vacancies) FILTER (WHERE NOT availabilities.freesale), 0)::INTEGER AS vacancies
+FROM products
+CROSS JOIN generate_series(sqlc.arg('local_date_start')::DATE, sqlc.arg('local_date_end')::DATE, '1 day') AS days(local_date)
+LEFT JOIN (
+    availabilities
+    JOIN options ON options.id = availabilities.option_id
+        AND options.deleted_at IS NULL
+) ON availabilities.product_id = products.id
+    AND availabilities.local_date = days.local_date::DATE
+    AND NOT availabilities.closed
+    AND availabilities.deleted_at IS NULL
+WHERE products.id = @product_id
+AND products.deleted_at IS NULL
+GROUP BY days.local_date, products.id
+ORDER BY days.local_date;
+
+
+-- name: AvailabilityCalendarWithPrice :many
+-- calendar with the lowest price of the open slots per local date in the currency,
+-- closed dates have the product's base price
+WITH slots AS (
+    -- open slots with their effective price, like in AvalabilityWithPriceRange
+    SELECT DISTINCT ON (availabilities.id) availabilities.id, availabilities.local_date, availabilities.vacancies, availabilities.freesale, prices.id AS price_id, prices.price
+    FROM availabilities
+    JOIN options ON options.id = availabilities.option_id
+    JOIN prices ON prices.product_id = availabilities.product_id
+        AND (prices.option_id = availabilities.option_id OR prices.option_id IS NULL)
+        AND (prices.availability_id IS NULL OR prices.availability_id = availabilities.id)
+        AND (prices.local_date_start IS NULL OR prices.local_date_start <= availabilities.local_date)
+        AND (prices.local_date_end IS NULL OR prices.local_date_end >= availabilities.local_date)
+        AND (prices.weekdays IS NULL OR EXTRACT(ISODOW FROM availabilities.local_date)::INTEGER = ANY(prices.weekdays))
+    WHERE availabilities.product_id = @product_id
+    AND availabilities.local_date >= sqlc.arg('local_date_start')::DATE
+    AND availabilities.local_date <= sqlc.arg('local_date_end')::DATE
+    AND NOT availabilities.closed
+    AND availabilities.deleted_at IS NULL
+    AND options.deleted_at IS NULL
+    AND prices.deleted_at IS NULL
+    AND prices.unit_type_id IS NULL -- base price
+    AND prices.currency = sqlc.arg('currency')::VARCHAR
+    ORDER BY availabilities.id, prices.priority DESC, prices.option_id NULLS LAST
+),
+dates AS (
+    SELECT
+        days.local_date::DATE AS local_date,
+        (CASE
+            WHEN COUNT(slots.id) = 0 THEN 'CLOSED'
+            WHEN bool_or(slots.freesale) THEN 'FREESALE'
+            WHEN SUM(slots.vacancies) = 0 THEN 'SOLD_OUT'
+            WHEN SUM(slots.vacancies) < products.limited_threshold THEN 'LIMITED'
+            ELSE 'AVAILABLE'
+        END)::VARCHAR AS status,
+        COALESCE(SUM(slots.vacancies) FILTER (WHERE NOT slots.freesale), 0)::INTEGER AS vacancies,
+        (array_agg(slots.price_id ORDER BY slots.price) FILTER (WHERE slots.id IS NOT NULL))[1]::BIGINT AS price_id -- lowest price
+    FROM products
+    CROSS JOIN generate_series(sqlc.arg('local_date_start')::DATE, sqlc.arg('local_date_end')::DATE, '1 day') AS days(local_date)
+    LEFT JOIN slots ON slots.local_date = days.local_date::DATE
+    WHERE products.id = @product_id
+    AND products.deleted_at IS NULL
+    GROUP BY days.local_date, products.id
+),
+base_price AS (
+    SELECT prices.id
+    FROM prices
+    WHERE prices.product_id = @product_id
+    AND prices.option_id IS NULL
+    AND prices.unit_type_id IS NULL
+    AND prices.priority = 0 -- not a price rule
+    AND prices.deleted_at IS NULL
+    AND prices.currency = sqlc.arg('currency')::VARCHAR
+    ORDER BY prices.id
+    LIMIT 1
+)
+SELECT dates.local_date, dates.status, dates.vacancies, sqlc.embed(prices)
+FROM dates
+CROSS JOIN base_price -- no calendar without a base price
+JOIN prices ON prices.id = COALESCE(dates.price_id, base_price.id)
+ORDER BY dates.local_date;
+
+
+-- name: LockAvailabilities :exec
+-- locks in ID order, so concurrent updates of bookings swapping availabilities do not deadlock
+SELECT id FROM availabilities
+WHERE id = ANY(sqlc.arg('ids')::INTEGER[])
+ORDER BY id
+FOR UPDATE;
+
+
+-- name: ReserveVacancies :execrows
+-- closed availabilities cannot be reserved, freesale ones have unlimited vacancies
+UPDATE availabilities
+SET vacancies = CASE WHEN freesale THEN vacancies ELSE vacancies - @units END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id
+AND product_id = @product_id
+AND option_id = @option_id
+AND NOT closed
+AND deleted_at IS NULL
+AND (freesale OR vacancies >= @units);
+
+
+-- name: ReleaseVacancies :exec
+-- up to the capacity, which may have been lowered since the units were sold
+UPDATE availabilities
+SET vacancies = CASE WHEN freesale THEN vacancies ELSE LEAST(vacancies + @units, capacity) END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+
+-- name: OpeningHours :many
+SELECT * FROM opening_hours
+WHERE availability_id = ANY(sqlc.arg('availability_ids')::INTEGER[])
+AND deleted_at IS NULL
+ORDER BY availability_id, time_from;
@@ -1,24 +1,40 @@
 -- name: CreateBooking :one
 WITH reservation AS (
     UPDATE availabilities
-    SET vacancies = vacancies - @units, 
+    SET vacancies = CASE WHEN freesale THEN vacancies ELSE vacancies - @units END, -- freesale has unlimited vacancies
         updated_at = CURRENT_TIMESTAMP
     WHERE id = sqlc.arg('availability_id')::INTEGER
     AND product_id = sqlc.arg('product_id')::INTEGER
+    AND ( -- availability must belong to the option, if given
+        sqlc.narg('option_code')::VARCHAR IS NULL
+        OR option_id = (
+            SELECT options.id FROM options
+            WHERE options.product_id = sqlc.arg('product_id')::INTEGER
+            AND options.code = sqlc.narg('option_code')::VARCHAR
+            AND options.deleted_at IS NULL
+        )
+    )
+    AND NOT closed
     AND deleted_at IS NULL
-    AND vacancies >= @units
-    RETURNING product_id, id AS availability_id
+    AND (freesale OR vacancies >= @units)
+    RETURNING product_id, option_id, id AS availability_id
 ),
 reserved_booking AS (
-    INSERT INTO bookings (product_id, availability_id, user_id, status)
-    SELECT reservation.product_id, reservation.availability_id, @user_id, 'RESERVED' 
+    INSERT INTO bookings (product_id, option_id, availability_id, user_id, status, expires_at, uuid, reseller_reference, currency, promo_code_id, discount_type, discount_amount)
+    SELECT reservation.product_id, reservation.option_id, reservation.availability_id, @user_id, 'RESERVED', sqlc.arg('expires_at')::TIMESTAMPTZ,
+        COALESCE(sqlc.narg('uuid')::UUID, gen_random_uuid()), sqlc.narg('reseller_reference')::VARCHAR, sqlc.arg('currency')::VARCHAR,
+        sqlc.narg('promo_code_id')::INTEGER, sqlc.narg('discount_type')::discount_type, sqlc.narg('discount_amount')::INTEGER
     FROM reservation
     RETURNING id
 ),
 new_units AS (
-    INSERT INTO units (booking_id)
-    SELECT reserved_booking.id 
-    FROM reserved_booking, generate_series(1, @units) -- creating units number of rows
+    -- units keep the price they are reserved for, units without a price are free
+    INSERT INTO units (booking_id, unit_type_id, price, original, net, included_taxes)
+    SELECT reserved_booking.id, NULLIF(unit_type.id, 0), -- zero is a unit without type
+        COALESCE(prices.price, 0), COALESCE(prices.original, prices.price, 0), prices.net, COALESCE(prices.included_taxes, '[]')
+    FROM reserved_booking, unnest(sqlc.arg('unit_type_ids')::INTEGER[]) WITH ORDINALITY AS unit_type(id, n) -- one row per unit
+    JOIN unnest(sqlc.arg('price_ids')::BIGINT[]) WITH ORDINALITY AS unit_price(id, n) USING (n) -- price of each unit
+    LEFT JOIN prices ON prices.id = unit_price.id
 )
 SELECT *
 FROM reserved_booking
@@ -26,43 +42,206 @@ FROM reserved_booking
 
 
 -- name: BookingForUpdate :many
-SELECT bookings.status, units.id as unit_id
+SELECT bookings.status, bookings.product_id, bookings.option_id, bookings.availability_id, bookings.expires_at, bookings.currency, bookings.promo_code_id, units.id as unit_id, units.unit_type_id, availabilities.local_date, products.delivery_methods
 FROM bookings
 JOIN units ON units.booking_id = bookings.id
+JOIN availabilities ON availabilities.id = bookings.availability_id
+JOIN products ON products.id = bookings.product_id
 WHERE bookings.id = @id
 AND bookings.user_id = @user_id
 AND bookings.deleted_at IS NULL
 AND units.deleted_at IS NULL
-FOR UPDATE;
+ORDER BY units.id
+FOR UPDATE OF bookings, units;
+
+-- name: BookingHoldForUpdate :one
+SELECT bookings.status, bookings.created_at, bookings.expires_at, products.max_reservation_hold_minutes
+FROM bookings
+JOIN products ON products.id = bookings.product_id
+WHERE bookings.id = @id
+AND bookings.user_id = @user_id
+AND bookings.deleted_at IS NULL
+FOR UPDATE OF bookings;
+
+-- name: ExtendBooking :exec
+UPDATE bookings
+SET expires_at = sqlc.arg('expires_at')::TIMESTAMPTZ,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
 
 -- name: ConfirmBooking :exec
 UPDATE bookings
 SET status = 'CONFIRMED',
+    expires_at = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: SetBookingAvailability :exec
+UPDATE bookings
+SET availability_id = @availability_id,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: AddUnits :exec
+-- unit type IDs and price IDs are in the same order, units without a price are free
+INSERT INTO units (booking_id, unit_type_id, price, original, net, included_taxes)
+SELECT @booking_id, NULLIF(unit_type.id, 0), -- zero is a unit without type
+    COALESCE(prices.price, 0), COALESCE(prices.original, prices.price, 0), prices.net, COALESCE(prices.included_taxes, '[]')
+FROM unnest(sqlc.arg('unit_type_ids')::INTEGER[]) WITH ORDINALITY AS unit_type(id, n) -- one row per unit
+JOIN unnest(sqlc.arg('price_ids')::BIGINT[]) WITH ORDINALITY AS unit_price(id, n) USING (n) -- price of each unit
+LEFT JOIN prices ON prices.id = unit_price.id;
+
+-- name: RemoveUnits :exec
+UPDATE units
+SET deleted_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ANY(sqlc.arg('ids')::BIGINT[]);
+
+-- name: SetUnitPrices :exec
+-- ids and price IDs are in the same order, units without a price are free
+UPDATE units
+SET price = COALESCE(prices.price, 0),
+    original = COALESCE(prices.original, prices.price, 0),
+    net = prices.net,
+    included_taxes = COALESCE(prices.included_taxes, '[]'),
+    updated_at = CURRENT_TIMESTAMP
+FROM unnest(sqlc.arg('ids')::BIGINT[]) WITH ORDINALITY AS unit(id, n)
+JOIN unnest(sqlc.arg('price_ids')::BIGINT[]) WITH ORDINALITY AS unit_price(price_id, n) USING (n)
+LEFT JOIN prices ON prices.id = unit_price.price_id
+WHERE units.id = unit.id;
+
+-- name: CancelBooking :exec
+-- invalidates the voucher, tickets of units are invalidated by InvalidateTickets
+UPDATE bookings
+SET status = 'CANCELLED',
+    voucher = NULL,
+    cancellation_reason = sqlc.arg('reason')::VARCHAR,
+    cancelled_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: ExpiredBookingsForUpdate :many
+-- skips bookings locked by other transactions, they will be picked up on the next run
+SELECT bookings.id, bookings.availability_id, bookings.promo_code_id, (
+    SELECT COUNT(*)
+    FROM units
+    WHERE units.booking_id = bookings.id
+    AND units.deleted_at IS NULL
+) AS units
+FROM bookings
+WHERE bookings.status = 'RESERVED'
+AND bookings.expires_at <= sqlc.arg('now')::TIMESTAMPTZ
+AND bookings.deleted_at IS NULL
+FOR UPDATE SKIP LOCKED;
+
+-- name: ExpireBooking :exec
+UPDATE bookings
+SET status = 'EXPIRED',
     updated_at = CURRENT_TIMESTAMP
 WHERE id = @id;
 
 -- name: SetUnitTicket :exec
 UPDATE units
 SET ticket = @ticket,
+    ticket_reference = @ticket_reference,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: SetBookingVoucher :exec
+UPDATE bookings
+SET voucher = @voucher,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = @id;
 
--- name: Booking :many
-SELECT sqlc.embed(bookings), sqlc.embed(units)
+-- name: InvalidateTickets :exec
+UPDATE units
+SET ticket = NULL,
+    ticket_reference = NULL,
+    updated_at = CURRENT_TIMESTAMP
+WHERE booking_id = @booking_id
+AND deleted_at IS NULL;
+
+-- name: Bookings :many
+SELECT sqlc.embed(bookings), options.code AS option_code, sqlc.embed(units), unit_types.code AS unit_type_code, promo_codes.code AS promo_code
 FROM bookings
+JOIN options ON options.id = bookings.option_id
+LEFT JOIN promo_codes ON promo_codes.id = bookings.promo_code_id
 LEFT JOIN units ON units.booking_id = bookings.id
-WHERE bookings.id = @id
+LEFT JOIN unit_types ON unit_types.id = units.unit_type_id
+WHERE bookings.id = ANY(sqlc.arg('ids')::BIGINT[])
 AND bookings.user_id = @user_id
 AND bookings.deleted_at IS NULL
-AND units.deleted_at IS NULL;
+AND units.deleted_at IS NULL
+ORDER BY bookings.id, units.id;
 
--- name: BookingWithPrice :many
-SELECT sqlc.embed(bookings), sqlc.embed(units), sqlc.embed(prices)
+-- name: BookingIDs :many
+-- lists IDs of user's bookings matching the filters, NULL filters are ignored
+SELECT bookings.id
 FROM bookings
-LEFT JOIN units ON units.booking_id = bookings.id
-JOIN prices ON  prices.product_id = bookings.product_id
-WHERE bookings.id = @id
+JOIN availabilities ON availabilities.id = bookings.availability_id
+WHERE bookings.user_id = @user_id
+AND bookings.id > @after
+AND bookings.deleted_at IS NULL
+AND (sqlc.narg('status')::booking_status IS NULL OR bookings.status = sqlc.narg('status')::booking_status)
+AND (sqlc.narg('product_id')::INTEGER IS NULL OR bookings.product_id = sqlc.narg('product_id')::INTEGER)
+AND (sqlc.narg('local_date_start')::DATE IS NULL OR availabilities.local_date >= sqlc.narg('local_date_start')::DATE)
+AND (sqlc.narg('local_date_end')::DATE IS NULL OR availabilities.local_date <= sqlc.narg('local_date_end')::DATE)
+AND (sqlc.narg('created_at_start')::TIMESTAMPTZ IS NULL OR bookings.created_at >= sqlc.narg('created_at_start')::TIMESTAMPTZ)
+AND (sqlc.narg('created_at_end')::TIMESTAMPTZ IS NULL OR bookings.created_at <= sqlc.narg('created_at_end')::TIMESTAMPTZ)
+AND (sqlc.narg('uuid')::UUID IS NULL OR bookings.uuid = sqlc.narg('uuid')::UUID)
+AND (sqlc.narg('reseller_reference')::VARCHAR IS NULL OR bookings.reseller_reference = sqlc.narg('reseller_reference')::VARCHAR)
+ORDER BY bookings.id
+LIMIT @max_results;
+
+-- name: TicketForUpdate :one
+-- unit with the ticket or its reference of the user's booking, its booking and the local date of its availability
+SELECT units.id AS unit_id, units.redeemed_at, units.ticket, unit_types.code AS unit_type_code,
+    bookings.id AS booking_id, bookings.status, bookings.product_id, bookings.availability_id,
+    availabilities.local_date, products.timezone
+FROM units
+JOIN bookings ON bookings.id = units.booking_id
+JOIN availabilities ON availabilities.id = bookings.availability_id
+JOIN products ON products.id = bookings.product_id
+LEFT JOIN unit_types ON unit_types.id = units.unit_type_id
+WHERE sqlc.arg('ticket')::VARCHAR IN (units.ticket, units.ticket_reference)
+AND bookings.user_id = @user_id
+AND units.deleted_at IS NULL
+AND bookings.deleted_at IS NULL
+FOR UPDATE OF units;
+
+-- name: VoucherForUpdate :many
+-- units of the user's booking with the voucher and the local date of its availability
+SELECT units.id AS unit_id, units.redeemed_at, units.ticket, unit_types.code AS unit_type_code,
+    bookings.id AS booking_id, bookings.status, bookings.product_id, bookings.availability_id,
+    availabilities.local_date, products.timezone
+FROM bookings
+JOIN units ON units.booking_id = bookings.id
+JOIN availabilities ON availabilities.id = bookings.availability_id
+JOIN products ON products.id = bookings.product_id
+LEFT JOIN unit_types ON unit_types.id = units.unit_type_id
+WHERE bookings.voucher = sqlc.arg('voucher')::VARCHAR
 AND bookings.user_id = @user_id
+AND units.deleted_at IS NULL
 AND bookings.deleted_at IS NULL
+ORDER BY units.id
+FOR UPDATE OF units;
+
+-- name: RedeemUnit :one
+UPDATE units
+SET redeemed_at = CURRENT_TIMESTAMP,
+    redeemed_by = sqlc.arg('redeemed_by')::INTEGER,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING redeemed_at;
+
+-- name: UnitTicket :one
+SELECT units.ticket, units.ticket_reference, products.delivery_format
+FROM units
+JOIN bookings ON bookings.id = units.booking_id
+JOIN products ON products.id = bookings.product_id
+WHERE units.id = @id
+AND units.booking_id = @booking_id
+AND bookings.user_id = @user_id
+AND units.ticket IS NOT NULL
 AND units.deleted_at IS NULL
-AND prices.deleted_at IS NULL;
+AND bookings.deleted_at IS NULL;
@@ -0,0 +1,30 @@
+-- name: UpsertContact :exec
+INSERT INTO contacts (booking_id, full_name, email_address, phone_number, locales, country, notes)
+VALUES (
+    @booking_id,
+    sqlc.narg('full_name')::VARCHAR,
+    sqlc.narg('email_address')::VARCHAR,
+    sqlc.narg('phone_number')::VARCHAR,
+    sqlc.arg('locales')::VARCHAR[],
+    sqlc.narg('country')::CHAR(2),
+    sqlc.narg('notes')::TEXT
+)
+ON CONFLICT (booking_id) DO UPDATE
+SET full_name = EXCLUDED.full_name,
+    email_address = EXCLUDED.email_address,
+    phone_number = EXCLUDED.phone_number,
+    locales = EXCLUDED.locales,
+    country = EXCLUDED.country,
+    notes = EXCLUDED.notes,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: Contacts :many
+SELECT * FROM contacts
+WHERE booking_id = ANY(sqlc.arg('booking_ids')::BIGINT[]);
+
+-- name: ConfirmationContact :one
+-- contact of the booking and whether the product requires it to confirm
+SELECT products.contact_required_to_confirm, contacts.full_name, contacts.email_address
+FROM products
+LEFT JOIN contacts ON contacts.booking_id = @booking_id
+WHERE products.id = @product_id;
@@ -0,0 +1,34 @@
+-- name: InsertIdempotencyKey :execrows
+INSERT INTO idempotency_keys (user_id, key, request_hash)
+VALUES (
+    sqlc.arg('user_id')::INTEGER,
+    sqlc.arg('key')::VARCHAR,
+    sqlc.arg('request_hash')::BYTEA
+)
+ON CONFLICT (user_id, key) DO NOTHING;
+
+-- name: IdempotencyKey :one
+SELECT request_hash, response_status, response_content_type, response_body
+FROM idempotency_keys
+WHERE user_id = sqlc.arg('user_id')::INTEGER
+AND key = sqlc.arg('key')::VARCHAR;
+
+-- name: SetIdempotencyKeyResponse :exec
+UPDATE idempotency_keys
+SET response_status = sqlc.arg('response_status')::INTEGER,
+    response_content_type = sqlc.arg('response_content_type')::VARCHAR,
+    response_body = sqlc.arg('response_body')::BYTEA,
+    updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg('user_id')::INTEGER
+AND key = sqlc.arg('key')::VARCHAR;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = sqlc.arg('user_id')::INTEGER
+AND key = sqlc.arg('key')::VARCHAR
+AND response_status IS NULL;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE (response_status IS NULL AND created_at < sqlc.arg('in_progress_before')::TIMESTAMPTZ)
+OR (response_status IS NOT NULL AND updated_at < sqlc.arg('completed_before')::TIMESTAMPTZ);
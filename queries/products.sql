@@ -4,11 +4,17 @@ WHERE products.deleted_at IS NULL;
 
 
 -- name: ProductsWithPrices :many
+-- products priced in the currency, or in their default currency if NULL,
+-- products without a price in the currency are skipped
 SELECT sqlc.embed(products), sqlc.embed(prices)
 FROM products
 JOIN prices ON products.id = prices.product_id
 WHERE products.deleted_at IS NULL 
-AND prices.deleted_at IS NULL;
+AND prices.deleted_at IS NULL
+AND prices.unit_type_id IS NULL -- base price
+AND prices.option_id IS NULL
+AND prices.priority = 0 -- not a price rule
+AND prices.currency = COALESCE(sqlc.narg('currency')::VARCHAR, products.default_currency);
 
 
 -- name: Product :one
@@ -22,4 +28,69 @@ FROM products
 JOIN prices ON products.id = prices.product_id
 WHERE products.id = @id
 AND products.deleted_at IS NULL
-AND prices.deleted_at IS NULL;
\ No newline at end of file
+AND prices.deleted_at IS NULL
+AND prices.unit_type_id IS NULL -- base price
+AND prices.option_id IS NULL
+AND prices.priority = 0 -- not a price rule
+AND prices.currency = sqlc.arg('currency')::VARCHAR;
+
+-- name: UnitTypes :many
+SELECT * FROM unit_types
+WHERE product_id = ANY(sqlc.arg('product_ids')::INTEGER[])
+AND deleted_at IS NULL
+ORDER BY id;
+
+-- name: UnitTypesWithPrices :many
+-- unit types with their price, falling back to the product's base price,
+-- in the currency, or in the product's default currency if NULL
+SELECT DISTINCT ON (unit_types.id) sqlc.embed(unit_types), sqlc.embed(prices)
+FROM unit_types
+JOIN products ON products.id = unit_types.product_id
+JOIN prices ON prices.product_id = unit_types.product_id
+    AND (prices.unit_type_id = unit_types.id OR prices.unit_type_id IS NULL)
+WHERE unit_types.product_id = ANY(sqlc.arg('product_ids')::INTEGER[])
+AND unit_types.deleted_at IS NULL
+AND prices.deleted_at IS NULL
+AND prices.option_id IS NULL
+AND prices.priority = 0 -- not a price rule
+AND prices.currency = COALESCE(sqlc.narg('currency')::VARCHAR, products.default_currency)
+ORDER BY unit_types.id, prices.unit_type_id NULLS LAST;
+
+-- name: UnitTypeIDs :many
+SELECT id, code FROM unit_types
+WHERE product_id = @product_id
+AND code = ANY(sqlc.arg('codes')::VARCHAR[])
+AND deleted_at IS NULL;
+
+-- name: Options :many
+SELECT * FROM options
+WHERE product_id = ANY(sqlc.arg('product_ids')::INTEGER[])
+AND deleted_at IS NULL
+ORDER BY id;
+
+-- name: OptionsWithPrices :many
+-- options with their price, falling back to the product's base price,
+-- in the currency, or in the product's default currency if NULL
+SELECT DISTINCT ON (options.id) sqlc.embed(options), sqlc.embed(prices)
+FROM options
+JOIN products ON products.id = options.product_id
+JOIN prices ON prices.product_id = options.product_id
+    AND (prices.option_id = options.id OR prices.option_id IS NULL)
+WHERE options.product_id = ANY(sqlc.arg('product_ids')::INTEGER[])
+AND options.deleted_at IS NULL
+AND prices.deleted_at IS NULL
+AND prices.unit_type_id IS NULL
+AND prices.priority = 0 -- not a price rule
+AND prices.currency = COALESCE(sqlc.narg('currency')::VARCHAR, products.default_currency)
+ORDER BY options.id, prices.option_id NULLS LAST;
+
+-- name: Currencies :many
+-- currencies the products have a base price in
+SELECT DISTINCT product_id, currency
+FROM prices
+WHERE product_id = ANY(sqlc.arg('product_ids')::INTEGER[])
+AND unit_type_id IS NULL
+AND option_id IS NULL
+AND priority = 0
+AND deleted_at IS NULL
+ORDER BY product_id, currency;
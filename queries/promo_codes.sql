@@ -0,0 +1,20 @@
+-- name: PromoCodeForUpdate :one
+-- locks the promo code, so concurrent bookings do not exceed its uses
+SELECT * FROM promo_codes
+WHERE upper(code) = upper(sqlc.arg('code')::VARCHAR)
+AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: UsePromoCode :exec
+UPDATE promo_codes
+SET uses = uses + 1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: ReleasePromoCode :exec
+-- gives the use back when the booking is cancelled or expires
+UPDATE promo_codes
+SET uses = uses - 1,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id
+AND uses > 0;
@@ -1,19 +1,61 @@
 -- name: InsertProduct :one
 -- used in tests
-INSERT INTO products (name, capacity, deleted_at) 
-VALUES (@name, @capacity, @deleted_at) 
+INSERT INTO products (name, capacity, max_reservation_hold_minutes, contact_required_to_confirm, availability_type, timezone, limited_threshold, delivery_format, delivery_methods, default_currency, deleted_at) 
+VALUES (@name, @capacity, @max_reservation_hold_minutes, @contact_required_to_confirm, @availability_type, @timezone, @limited_threshold, @delivery_format, @delivery_methods, @default_currency, @deleted_at) 
 RETURNING *;
 
 
 -- name: InsertPrice :one
 -- used in tests
-INSERT INTO prices (price, currency, product_id, deleted_at)
-VALUES (@price, @currency, @product_id, @deleted_at) 
+INSERT INTO prices (price, original, net, included_taxes, currency, product_id, unit_type_id, option_id, availability_id, local_date_start, local_date_end, weekdays, priority, deleted_at)
+VALUES (@price, @original, @net, @included_taxes, @currency, @product_id, @unit_type_id, @option_id, @availability_id, @local_date_start, @local_date_end, @weekdays, @priority, @deleted_at) 
+RETURNING *;
+
+-- name: InsertUnitType :one
+-- used in tests
+INSERT INTO unit_types (product_id, code, name, deleted_at)
+VALUES (@product_id, @code, @name, @deleted_at)
+RETURNING *;
+
+-- name: InsertOption :one
+-- used in tests
+INSERT INTO options (product_id, code, name, capacity, deleted_at)
+VALUES (@product_id, @code, @name, @capacity, @deleted_at)
 RETURNING *;
 
 -- name: InsertAvailability :one
-INSERT INTO availabilities (product_id, local_date, vacancies, deleted_at) 
-VALUES (@product_id, @local_date, @vacancies, @deleted_at)
+-- option defaults to the product's DEFAULT option, slot defaults to the whole local date,
+-- capacity defaults to the option's capacity
+INSERT INTO availabilities (product_id, option_id, local_date, local_date_time_start, local_date_time_end, all_day, capacity, vacancies, closed, freesale, deleted_at) 
+VALUES (
+    @product_id,
+    COALESCE(
+        sqlc.narg('option_id')::INTEGER,
+        (SELECT id FROM options WHERE options.product_id = @product_id AND code = 'DEFAULT' AND options.deleted_at IS NULL)
+    ),
+    @local_date,
+    COALESCE(sqlc.narg('local_date_time_start')::TIMESTAMP, sqlc.arg('local_date')::DATE::TIMESTAMP),
+    COALESCE(sqlc.narg('local_date_time_end')::TIMESTAMP, (sqlc.arg('local_date')::DATE + 1)::TIMESTAMP),
+    sqlc.narg('local_date_time_start')::TIMESTAMP IS NULL,
+    sqlc.narg('capacity')::INTEGER,
+    @vacancies,
+    @closed,
+    @freesale,
+    @deleted_at
+)
+RETURNING *;
+
+-- name: CloseAvailability :exec
+-- used in tests, like an operator shutting an availability
+UPDATE availabilities
+SET closed = TRUE,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: InsertOpeningHours :one
+-- used in tests
+INSERT INTO opening_hours (availability_id, time_from, time_to)
+VALUES (@availability_id, @time_from, @time_to)
 RETURNING *;
 
 -- name: InsertUser :one
@@ -21,3 +63,23 @@ RETURNING *;
 INSERT INTO users (email, api_key)
 VALUES (@email, @api_key)
 RETURNING *;
+
+-- name: SetVacancies :exec
+-- used in tests, like an operator topping up an availability
+UPDATE availabilities
+SET vacancies = @vacancies,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: SetCapacity :exec
+-- used in tests, like an operator lowering the capacity of an availability
+UPDATE availabilities
+SET capacity = @capacity,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: InsertPromoCode :one
+-- used in tests
+INSERT INTO promo_codes (code, discount_type, amount, currency, valid_from, valid_until, max_uses, uses, product_ids, deleted_at)
+VALUES (@code, @discount_type, @amount, @currency, @valid_from, @valid_until, @max_uses, @uses, @product_ids, @deleted_at)
+RETURNING *;
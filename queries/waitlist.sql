@@ -0,0 +1,68 @@
+-- name: AvailabilityProductID :one
+SELECT product_id FROM availabilities
+WHERE id = @id
+AND deleted_at IS NULL;
+
+-- name: CreateWaitlistEntry :one
+-- no rows if the availability does not exist or does not belong to the option, if given
+INSERT INTO waitlist_entries (user_id, availability_id, option_id, units, unit_type_ids, webhook_url, currency)
+SELECT @user_id, availabilities.id, availabilities.option_id, cardinality(sqlc.arg('unit_type_ids')::INTEGER[]), sqlc.arg('unit_type_ids')::INTEGER[],
+    sqlc.narg('webhook_url')::VARCHAR, sqlc.arg('currency')::VARCHAR
+FROM availabilities
+JOIN options ON options.id = availabilities.option_id
+WHERE availabilities.id = @availability_id
+AND availabilities.deleted_at IS NULL
+AND (sqlc.narg('option_code')::VARCHAR IS NULL OR options.code = sqlc.narg('option_code')::VARCHAR)
+AND options.deleted_at IS NULL
+RETURNING id;
+
+-- name: WaitlistEntry :one
+SELECT sqlc.embed(waitlist_entries), options.code AS option_code
+FROM waitlist_entries
+JOIN options ON options.id = waitlist_entries.option_id
+WHERE waitlist_entries.id = @id
+AND waitlist_entries.user_id = @user_id
+AND waitlist_entries.deleted_at IS NULL;
+
+-- name: WaitlistedAvailabilities :many
+SELECT DISTINCT availability_id
+FROM waitlist_entries
+WHERE status = 'WAITING'
+AND deleted_at IS NULL
+ORDER BY availability_id;
+
+-- name: WaitingEntriesForUpdate :many
+-- oldest first, skips entries locked by other transactions, they will be picked up on the next run
+SELECT waitlist_entries.id, waitlist_entries.user_id, waitlist_entries.unit_type_ids, waitlist_entries.currency, availabilities.product_id, options.code AS option_code
+FROM waitlist_entries
+JOIN availabilities ON availabilities.id = waitlist_entries.availability_id
+JOIN options ON options.id = waitlist_entries.option_id
+WHERE waitlist_entries.availability_id = @availability_id
+AND waitlist_entries.status = 'WAITING'
+AND waitlist_entries.deleted_at IS NULL
+ORDER BY waitlist_entries.created_at, waitlist_entries.id
+FOR UPDATE OF waitlist_entries SKIP LOCKED;
+
+-- name: ReserveWaitlistEntry :exec
+UPDATE waitlist_entries
+SET status = 'RESERVED',
+    booking_id = @booking_id,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;
+
+-- name: UnnotifiedWaitlistEntries :many
+-- reserved entries with a webhook which was not delivered yet
+SELECT sqlc.embed(waitlist_entries), options.code AS option_code
+FROM waitlist_entries
+JOIN options ON options.id = waitlist_entries.option_id
+WHERE waitlist_entries.status = 'RESERVED'
+AND waitlist_entries.webhook_url IS NOT NULL
+AND waitlist_entries.notified_at IS NULL
+AND waitlist_entries.deleted_at IS NULL
+ORDER BY waitlist_entries.id;
+
+-- name: SetWaitlistEntryNotified :exec
+UPDATE waitlist_entries
+SET notified_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id;